@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/pflag"
+
+	"pg-start-trainee-2024/pkg/client"
+)
+
+// command runs with args following its name and returns exit code of the tool
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error)
+	flags   func(fs *pflag.FlagSet)
+}
+
+// exitCode mirrors exit code of done script, scripts done without exit code (stopped, rejected etc.) exit with failure
+func exitCode(script *client.Script) int {
+	switch {
+	case script.ExitCode != nil && *script.ExitCode >= 0:
+		return *script.ExitCode
+
+	case script.Status == client.StatusFinished:
+		return exitOK
+
+	default:
+		return exitFailure
+	}
+}
+
+func scriptID(fs *pflag.FlagSet) (int, error) {
+	if fs.NArg() != 1 {
+		return 0, usageErrorf("script ID is required")
+	}
+
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return 0, usageErrorf("invalid script ID %q", fs.Arg(0))
+	}
+
+	return id, nil
+}
+
+// follow streams output of script to w until script is done and returns its exit code
+func follow(ctx context.Context, c *client.Client, w io.Writer, id int) (int, error) {
+	stream := c.Output(ctx, id)
+
+	for stream.Next() {
+		if _, err := io.WriteString(w, stream.Chunk()); err != nil {
+			return exitFailure, err
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return exitFailure, err
+	}
+
+	script := stream.Script()
+	if script.Status != client.StatusFinished && script.Status != client.StatusFailed {
+		_, _ = fmt.Fprintf(os.Stderr, "script %v is %v\n", script.ID, script.Status)
+	}
+
+	return exitCode(script), nil
+}
+
+// wait waits for script and prints it, it's used to follow script with JSON output
+func wait(ctx context.Context, c *client.Client, id int) (int, error) {
+	script, err := c.Wait(ctx, id)
+	if err != nil {
+		return exitFailure, err
+	}
+
+	return exitCode(script), printJSON(os.Stdout, script)
+}
+
+var (
+	runFollow      bool
+	runTags        []string
+	runInterpreter string
+	runRunAs       string
+	runEnv         map[string]string
+	runLabels      map[string]string
+
+	listRunning  bool
+	listStatuses []string
+	listTags     []string
+	listCommand  string
+	listOffset   int
+	listLimit    int
+
+	logsFollow bool
+)
+
+var commands = map[string]*command{
+	"run": {
+		usage:   "run <command>",
+		summary: "create script, with --follow stream its output and exit with its exit code",
+		flags: func(fs *pflag.FlagSet) {
+			fs.BoolVarP(&runFollow, "follow", "f", false, "stream output until script is done")
+			fs.StringSliceVarP(&runTags, "tag", "t", nil, "tag of script, may be repeated")
+			fs.StringVar(&runInterpreter, "interpreter", "", "interpreter: sh or bash")
+			fs.StringVar(&runRunAs, "run-as", "", "user script is run as")
+			fs.StringToStringVarP(&runEnv, "env", "e", nil, "env variable KEY=VALUE, may be repeated")
+			fs.StringToStringVarP(&runLabels, "label", "l", nil, "label KEY=VALUE worker must have, may be repeated")
+		},
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			if fs.NArg() != 1 {
+				return 0, usageErrorf("command is required, quote it if it has spaces")
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			created, err := c.CreateScript(ctx, client.CreateScriptRequest{
+				Command:       fs.Arg(0),
+				Tags:          runTags,
+				Interpreter:   runInterpreter,
+				Env:           runEnv,
+				RunAs:         runRunAs,
+				LabelSelector: runLabels,
+			})
+			if err != nil {
+				return exitFailure, err
+			}
+
+			switch {
+			case !runFollow:
+				return exitOK, printCreated(os.Stdout, g.output, created)
+
+			case g.output == outputJSON:
+				return wait(ctx, c, created.ID)
+
+			default:
+				if created.Status != client.StatusRunning {
+					_, _ = fmt.Fprintf(os.Stderr, "script %v is %v, waiting for it to run\n", created.ID, created.Status)
+				}
+
+				return follow(ctx, c, os.Stdout, created.ID)
+			}
+		},
+	},
+	"ls": {
+		usage:   "ls",
+		summary: "list scripts, the latest first",
+		flags: func(fs *pflag.FlagSet) {
+			fs.BoolVar(&listRunning, "running", false, "list running scripts only")
+			fs.StringSliceVarP(&listStatuses, "status", "s", nil, "status of scripts, may be repeated")
+			fs.StringSliceVarP(&listTags, "tag", "t", nil, "tag scripts must have, may be repeated")
+			fs.StringVarP(&listCommand, "command", "c", "", "command substring")
+			fs.IntVar(&listOffset, "offset", 0, "number of scripts to skip")
+			fs.IntVar(&listLimit, "limit", 20, "max number of scripts")
+		},
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			if fs.NArg() != 0 {
+				return 0, usageErrorf("unexpected arguments %v", fs.Args())
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			filter := client.Filter{
+				Statuses: listStatuses,
+				Tags:     listTags,
+				Command:  listCommand,
+				Sort:     "created_at",
+				Order:    "desc",
+			}
+
+			if listRunning {
+				filter.IsRunning = &listRunning
+			}
+
+			scripts, err := c.GetAllScripts(ctx, filter, listOffset, listLimit)
+			if err != nil {
+				return exitFailure, err
+			}
+
+			return exitOK, printScripts(os.Stdout, g.output, scripts)
+		},
+	},
+	"get": {
+		usage:   "get <id>",
+		summary: "show script",
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			id, err := scriptID(fs)
+			if err != nil {
+				return 0, err
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			script, err := c.GetScript(ctx, id)
+			if err != nil {
+				return exitFailure, err
+			}
+
+			return exitOK, printScript(os.Stdout, g.output, script)
+		},
+	},
+	"logs": {
+		usage:   "logs <id>",
+		summary: "print output of script, with --follow stream it and exit with exit code of script",
+		flags: func(fs *pflag.FlagSet) {
+			fs.BoolVarP(&logsFollow, "follow", "f", false, "stream output until script is done")
+		},
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			id, err := scriptID(fs)
+			if err != nil {
+				return 0, err
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			switch {
+			case logsFollow && g.output == outputJSON:
+				return wait(ctx, c, id)
+
+			case logsFollow:
+				return follow(ctx, c, os.Stdout, id)
+			}
+
+			script, err := c.GetScript(ctx, id)
+			if err != nil {
+				return exitFailure, err
+			}
+
+			if g.output == outputJSON {
+				return exitOK, printJSON(os.Stdout, script)
+			}
+
+			_, err = io.WriteString(os.Stdout, script.Output)
+
+			return exitOK, err
+		},
+	},
+	"stop": {
+		usage:   "stop <id>",
+		summary: "stop running script",
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			id, err := scriptID(fs)
+			if err != nil {
+				return 0, err
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			return exitOK, c.StopScript(ctx, id)
+		},
+	},
+	"rm": {
+		usage:   "rm <id>",
+		summary: "delete script, running script is stopped",
+		run: func(ctx context.Context, fs *pflag.FlagSet, g *globalFlags) (int, error) {
+			id, err := scriptID(fs)
+			if err != nil {
+				return 0, err
+			}
+
+			c, err := g.client()
+			if err != nil {
+				return exitFailure, err
+			}
+
+			return exitOK, c.DeleteScript(ctx, id)
+		},
+	},
+}
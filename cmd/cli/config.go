@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"pg-start-trainee-2024/pkg/client"
+)
+
+const (
+	defaultServer = "http://localhost:5000"
+
+	// env variables are PGST_SERVER, PGST_API_KEY, PGST_TOKEN and PGST_TIMEOUT
+	envPrefix = "pgst"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type config struct {
+	Server string `mapstructure:"server"`
+	APIKey string `mapstructure:"api_key"`
+	Token  string `mapstructure:"token"`
+	// Timeout of request in seconds
+	Timeout int `mapstructure:"timeout"`
+}
+
+// loadConfig reads config file at path or pgst/config.yaml in user config dir if path is empty,
+// env variables override config file values
+func loadConfig(path string) (*config, error) {
+	v := viper.New()
+
+	v.SetDefault("server", defaultServer)
+	v.SetDefault("api_key", "")
+	v.SetDefault("token", "")
+	v.SetDefault("timeout", 0)
+
+	v.SetEnvPrefix(envPrefix)
+	v.AutomaticEnv()
+
+	if path != "" {
+		v.SetConfigFile(path)
+
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+	} else if dir, err := os.UserConfigDir(); err == nil {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(filepath.Join(dir, "pgst"))
+
+		// config file is optional
+		var notFound viper.ConfigFileNotFoundError
+		if err = v.ReadInConfig(); err != nil && !errors.As(err, &notFound) {
+			return nil, err
+		}
+	}
+
+	var conf config
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	return &conf, nil
+}
+
+// globalFlags are flags every command accepts, they override config
+type globalFlags struct {
+	configPath string
+	server     string
+	apiKey     string
+	token      string
+	output     string
+}
+
+func (g *globalFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", "", "path to config file")
+	fs.StringVar(&g.server, "server", "", "server URL (PGST_SERVER)")
+	fs.StringVar(&g.apiKey, "api-key", "", "API key (PGST_API_KEY)")
+	fs.StringVar(&g.token, "token", "", "JWT used if API key isn't set (PGST_TOKEN)")
+	fs.StringVarP(&g.output, "output", "o", outputTable, "output format: table or json")
+}
+
+// client creates API client configured with config and flags
+func (g *globalFlags) client() (*client.Client, error) {
+	if g.output != outputTable && g.output != outputJSON {
+		return nil, usageErrorf("unknown output format %q", g.output)
+	}
+
+	conf, err := loadConfig(g.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for dest, value := range map[*string]string{&conf.Server: g.server, &conf.APIKey: g.apiKey, &conf.Token: g.token} {
+		if value != "" {
+			*dest = value
+		}
+	}
+
+	return client.New(conf.Server, client.Options{
+		APIKey:  conf.APIKey,
+		Token:   conf.Token,
+		Timeout: time.Duration(conf.Timeout) * time.Second,
+	}), nil
+}
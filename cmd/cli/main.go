@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitInterrupted = 130
+)
+
+var errUsage = errors.New("usage")
+
+func usageErrorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %v", errUsage, fmt.Sprintf(format, args...))
+}
+
+func printUsage() {
+	_, _ = fmt.Fprintln(os.Stderr, "pgst is command-line client of pg-start-trainee API\n\nusage: pgst <command> [flags]\n\ncommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-16v%v\n", commands[name].usage, commands[name].summary)
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "\nserver URL and credentials are read from pgst/config.yaml in user config dir and PGST_* env variables,\nrun pgst <command> --help for flags")
+}
+
+// run runs command named by the first arg and returns exit code of the tool
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "pgst: unknown command %q\n\n", args[0])
+		printUsage()
+
+		return exitUsage
+	}
+
+	var g globalFlags
+
+	fs := pflag.NewFlagSet("pgst "+args[0], pflag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: pgst %v [flags]\n\n%v\n\nflags:\n%v", cmd.usage, cmd.summary, fs.FlagUsages())
+	}
+
+	g.register(fs)
+
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return exitOK
+		}
+
+		return exitUsage
+	}
+
+	code, err := cmd.run(ctx, fs, &g)
+
+	switch {
+	case err == nil:
+		return code
+
+	case errors.Is(err, errUsage):
+		_, _ = fmt.Fprintf(os.Stderr, "pgst: %v\n\n", err)
+		fs.Usage()
+
+		return exitUsage
+
+	case ctx.Err() != nil:
+		return exitInterrupted
+
+	default:
+		_, _ = fmt.Fprintf(os.Stderr, "pgst: %v\n", err)
+
+		return exitFailure
+	}
+}
+
+// pgst is command-line client of the API, it exits with exit code of script it follows
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	code := run(ctx, os.Args[1:])
+
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"pg-start-trainee-2024/pkg/client"
+)
+
+// maxCommandWidth is width command is truncated to in tables
+const maxCommandWidth = 60
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func truncate(s string, width int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+
+	if len([]rune(s)) <= width {
+		return s
+	}
+
+	return string([]rune(s)[:width-3]) + "..."
+}
+
+func formatExitCode(code *int) string {
+	if code == nil {
+		return "-"
+	}
+
+	return strconv.Itoa(*code)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Local().Format(time.DateTime)
+}
+
+func printCreated(w io.Writer, output string, script *client.CreatedScript) error {
+	if output == outputJSON {
+		return printJSON(w, script)
+	}
+
+	table := newTable(w)
+
+	_, _ = fmt.Fprintln(table, "ID\tSTATUS\tPID\tCOMMAND")
+	_, _ = fmt.Fprintf(table, "%v\t%v\t%v\t%v\n", script.ID, script.Status, script.PID, truncate(script.Command, maxCommandWidth))
+
+	return table.Flush()
+}
+
+func printScripts(w io.Writer, output string, scripts []*client.Script) error {
+	if output == outputJSON {
+		return printJSON(w, scripts)
+	}
+
+	table := newTable(w)
+
+	_, _ = fmt.Fprintln(table, "ID\tSTATUS\tEXIT\tCREATED\tFINISHED\tCOMMAND")
+
+	for _, script := range scripts {
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			script.ID,
+			script.Status,
+			formatExitCode(script.ExitCode),
+			formatTime(&script.CreatedAt),
+			formatTime(script.FinishedAt),
+			truncate(script.Command, maxCommandWidth),
+		)
+	}
+
+	return table.Flush()
+}
+
+// printScript prints fields of script one per line, output is omitted
+func printScript(w io.Writer, output string, script *client.Script) error {
+	if output == outputJSON {
+		return printJSON(w, script)
+	}
+
+	table := newTable(w)
+
+	rows := [][2]string{
+		{"ID", strconv.Itoa(script.ID)},
+		{"Namespace", script.Namespace},
+		{"Command", script.Command},
+		{"Status", script.Status},
+		{"Exit code", formatExitCode(script.ExitCode)},
+		{"PID", strconv.Itoa(script.PID)},
+		{"Tags", strings.Join(script.Tags, ", ")},
+		{"Created", formatTime(&script.CreatedAt)},
+		{"Finished", formatTime(script.FinishedAt)},
+	}
+
+	if script.WorkerID != nil {
+		rows = append(rows, [2]string{"Worker", *script.WorkerID})
+	}
+
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(table, "%v:\t%v\n", row[0], row[1])
+	}
+
+	return table.Flush()
+}
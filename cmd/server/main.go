@@ -4,30 +4,58 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 
 	chimiddlewares "github.com/go-chi/chi/v5/middleware"
 	gocache "github.com/patrickmn/go-cache"
 	httpswagger "github.com/swaggo/http-swagger"
 
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/app"
 	"pg-start-trainee-2024/internal/config"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/internal/handler/rpc"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"pg-start-trainee-2024/internal/pkg/metrics"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+	"pg-start-trainee-2024/internal/service/bus"
+	"pg-start-trainee-2024/internal/service/policy"
+	"pg-start-trainee-2024/pkg/jwt"
 	"pg-start-trainee-2024/pkg/router"
 
+	apikeyhandler "pg-start-trainee-2024/internal/handler/apikey"
+	audithandler "pg-start-trainee-2024/internal/handler/audit"
+	healthhandler "pg-start-trainee-2024/internal/handler/health"
 	scripthandler "pg-start-trainee-2024/internal/handler/script"
+	webhookhandler "pg-start-trainee-2024/internal/handler/webhook"
+	apikeyrepo "pg-start-trainee-2024/internal/repository/postgres/apikey"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	healthrepo "pg-start-trainee-2024/internal/repository/postgres/health"
+	idempotencyrepo "pg-start-trainee-2024/internal/repository/postgres/idempotency"
 	scriprepo "pg-start-trainee-2024/internal/repository/postgres/script"
+	webhookrepo "pg-start-trainee-2024/internal/repository/postgres/webhook"
+	apikeyservice "pg-start-trainee-2024/internal/service/apikey"
+	auditservice "pg-start-trainee-2024/internal/service/audit"
+	healthservice "pg-start-trainee-2024/internal/service/health"
+	idempotencyservice "pg-start-trainee-2024/internal/service/idempotency"
 	scriptservice "pg-start-trainee-2024/internal/service/script"
+	tokenservice "pg-start-trainee-2024/internal/service/token"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
 
 	dbutils "pg-start-trainee-2024/pkg/utils/db"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "pg-start-trainee-2024/docs"
@@ -36,83 +64,236 @@ import (
 const (
 	configPath = "./config"
 	baseUri    = "/pg-start-trainee/api/"
-	apiVersion = "v1"
-)
-
-func initConfig() (*config.Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
 
-	viper.AddConfigPath(configPath)
-
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, err
-	}
+	jwksRequestTimeout     = 10 * time.Second
+	approvalExpiryInterval = time.Minute
+	idempotencyKeysCleanup = time.Hour
+	listenerRetryInterval  = 5 * time.Second
+	scriptChangesBuffer    = 100
+)
 
-	var conf config.Config
-	if err := viper.Unmarshal(&conf); err != nil {
-		return nil, err
-	}
+func newTokenService(conf config.JWT, logger *logrus.Logger) *tokenservice.Service {
+	ttl := time.Duration(conf.JWKSCacheTTL) * time.Second
+	maxStale := time.Duration(conf.JWKSMaxStale) * time.Second
 
-	// env variables
-	if err := godotenv.Load(configPath + "/.env"); err != nil {
-		return nil, err
+	keys := jwt.NewURLSource(conf.JWKSURL, &http.Client{Timeout: jwksRequestTimeout}, ttl, maxStale, logger)
+	if conf.JWKSFile != "" {
+		keys = jwt.NewFileSource(conf.JWKSFile, ttl, maxStale, logger)
 	}
 
-	viper.SetEnvPrefix("pg_start_trainee")
-	viper.AutomaticEnv()
-
-	return &conf, nil
-}
-
-func shutdownScripts(ctx context.Context, scriptService *scriptservice.Service, cache *gocache.Cache, logger *logrus.Logger) {
-	for k := range cache.Items() {
-		if id, err := strconv.Atoi(k); err == nil {
-			err = scriptService.StopScript(ctx, id)
-			if err != nil {
-				logger.Errorf("error occurred stopping script: %v", err)
-			}
-		}
-	}
+	return tokenservice.New(keys, jwt.VerifyOptions{
+		Issuers:  conf.Issuers,
+		Audience: conf.Audience,
+		Leeway:   time.Duration(conf.Leeway) * time.Second,
+	})
 }
 
 func main() {
 	logger := logrus.New()
 	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
 	cache := gocache.New(gocache.NoExpiration, 0)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	conf, err := initConfig()
+	conf, err := app.InitConfig(configPath)
 	if err != nil {
 		logger.Fatalf("cannot init config: %v", err)
 	}
 
+	if err = logging.Configure(logger, logging.Options{Level: conf.Logging.Level, Format: conf.Logging.Format}); err != nil {
+		logger.Fatalf("cannot configure logging: %v", err)
+	}
+
+	tracing.SetupPropagation()
+
+	shutdownTracing := func(context.Context) error { return nil }
+	if conf.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(ctx, tracing.Options{
+			ServiceName: conf.Tracing.ServiceName,
+			Exporter:    conf.Tracing.Exporter,
+			Endpoint:    conf.Tracing.Endpoint,
+			Insecure:    conf.Tracing.Insecure,
+			File:        conf.Tracing.File,
+			SampleRatio: conf.Tracing.SampleRatio,
+		})
+		if err != nil {
+			logger.Fatalf("cannot set up tracing: %v", err)
+		}
+	}
+
 	db, err := dbutils.TryToConnectToDB(conf.Postgres.ConnectionURL(), "postgres", conf.Postgres.Retries, conf.Postgres.Interval, logger)
 	if err != nil {
 		logger.Fatalf("cannot connect to db: %v", err)
 	}
 
+	transactor := dbutils.NewTransactor(db)
+	auditRepo := auditrepo.New(db)
+
 	scriptRepo := scriprepo.New(db)
-	scriptService := scriptservice.New(scriptRepo, cache, conf.Service.OutputBufferLength)
-	scriptHandler := scripthandler.New(scriptService, logger, valid, conf.Handler.DefaultOffset, conf.Handler.DefaultLimit)
+	policyEngine, err := policy.New(entity.PolicyAction(conf.Policy.DefaultAction), conf.Policy.PolicyRules(), conf.Policy.RunAsUsers)
+	if err != nil {
+		logger.Fatalf("cannot init command policy: %v", err)
+	}
+
+	allowedNetworks, err := conf.Webhooks.AllowedPrefixes()
+	if err != nil {
+		logger.Fatalf("invalid webhooks config: %v", err)
+	}
+
+	webhookService := webhookservice.New(webhookrepo.New(db), auditRepo, transactor, logger, webhookservice.Options{
+		CallbackSecret:  conf.Webhooks.CallbackSecret,
+		Timeout:         time.Duration(conf.Webhooks.Timeout) * time.Second,
+		MaxAttempts:     conf.Webhooks.MaxAttempts,
+		BaseBackoff:     time.Duration(conf.Webhooks.BaseBackoff) * time.Second,
+		MaxBackoff:      time.Duration(conf.Webhooks.MaxBackoff) * time.Second,
+		BatchSize:       conf.Webhooks.BatchSize,
+		AllowedNetworks: allowedNetworks,
+	})
+
+	go webhookService.DeliverDueWebhooksPeriodically(ctx, time.Duration(conf.Webhooks.PollInterval)*time.Second)
+
+	mode := scriptservice.ExecutionMode(conf.Executor.Mode)
+	if mode != scriptservice.ExecuteLocally && mode != scriptservice.ExecuteByWorkers {
+		logger.Fatalf("unknown execution mode: %v", mode)
+	}
+
+	scriptService := scriptservice.New(
+		scriptRepo,
+		auditRepo,
+		transactor,
+		cache,
+		policyEngine,
+		webhookService,
+		conf.Quotas.NamespaceQuotas(),
+		logger,
+		scriptservice.ExecutionOptions{
+			Mode:            mode,
+			WorkerLostAfter: time.Duration(conf.Worker.LostAfter) * time.Second,
+		},
+		conf.Service.OutputBufferLength,
+		time.Duration(conf.Service.ApprovalTTL)*time.Second,
+	)
+
+	go scriptService.ExpirePendingScriptsPeriodically(ctx, approvalExpiryInterval)
+
+	// scripts of dead workers are marked as lost here as well, so they are marked even if no worker is alive
+	if mode == scriptservice.ExecuteByWorkers {
+		go scriptService.MarkLostScriptsPeriodically(ctx, time.Duration(conf.Worker.HeartbeatInterval)*time.Second)
+	}
+
+	// changes of scripts made by other replicas are received with LISTEN
+	changeBus := bus.New(scriprepo.NewChangeListener(conf.Postgres.ConnectionURL(), listenerRetryInterval, logger), logger)
+
+	go func() {
+		if busErr := changeBus.Run(ctx); busErr != nil && !errors.Is(busErr, context.Canceled) {
+			logger.Errorf("error occurred listening to script changes: %v", busErr)
+		}
+	}()
+
+	scriptChanges, _ := changeBus.Subscribe(scriptChangesBuffer)
+	go scriptService.WatchScriptChanges(ctx, scriptChanges)
+
+	if err = metrics.RegisterRunningScripts(prometheus.DefaultRegisterer, scriptService.RunningScripts); err != nil {
+		logger.Fatalf("cannot register running scripts gauge: %v", err)
+	}
+
+	idempotencyService := idempotencyservice.New(
+		idempotencyrepo.New(db),
+		logger,
+		time.Duration(conf.Service.IdempotencyTTL)*time.Second,
+		time.Duration(conf.Service.IdempotencyLease)*time.Second,
+	)
+
+	go idempotencyService.DeleteExpiredKeysPeriodically(ctx, idempotencyKeysCleanup)
+
+	// access to scripts is authorized by script service
+	scriptHandler := scripthandler.New(
+		scriptService,
+		idempotencyService,
+		logger,
+		valid,
+		conf.Handler.DefaultOffset,
+		conf.Handler.DefaultLimit,
+	)
+
+	apiKeyRepo := apikeyrepo.New(db)
+	apiKeyService := apikeyservice.New(apiKeyRepo, auditRepo, transactor, logger, conf.Auth.BootstrapKey)
+	apiKeyHandler := apikeyhandler.New(apiKeyService, logger, valid, middleware.RequireScope(entity.ScopeAdmin, logger))
+
+	auditService := auditservice.New(auditRepo)
+	auditHandler := audithandler.New(
+		auditService,
+		logger,
+		valid,
+		conf.Handler.DefaultOffset,
+		conf.Handler.DefaultLimit,
+		middleware.RequireScope(entity.ScopeAdmin, logger),
+	)
+
+	webhookHandler := webhookhandler.New(
+		webhookService,
+		logger,
+		valid,
+		conf.Handler.DefaultOffset,
+		conf.Handler.DefaultLimit,
+		middleware.RequireScope(entity.ScopeAdmin, logger),
+	)
+
+	migrationVersion, err := dbutils.LatestMigrationVersion(conf.Health.MigrationsDir)
+	if err != nil {
+		logger.Fatalf("cannot find expected migration version: %v", err)
+	}
+
+	healthService := healthservice.New(healthrepo.New(db), migrationVersion)
+	healthHandler := healthhandler.New(healthService)
 
 	routers := make(map[string]chi.Router)
 
-	routers["/script"] = scriptHandler.Routes()
+	// v1 header based routes are kept for existing clients
+	routers["v1/script"] = scriptHandler.Routes()
+	routers["v2/scripts"] = scriptHandler.RoutesV2()
+	routers["v2/admin/api-keys"] = apiKeyHandler.Routes()
+	routers["v2/admin/audit-events"] = auditHandler.Routes()
+	routers["v2/admin/webhooks"] = webhookHandler.Routes()
+
+	// gRPC calls are authenticated the same way requests are
+	grpcContextFuncs := []middleware.GRPCContextFunc{middleware.GRPCRequestMeta}
 
 	middlewares := []router.Middleware{
 		chimiddlewares.Recoverer,
+		chimiddlewares.RequestID,
+		middleware.Tracing,
 		chimiddlewares.Logger,
+		middleware.Metrics,
+		middleware.RequestMeta,
 	}
 
-	r := router.MakeRoutes(baseUri+apiVersion, routers, middlewares...)
+	if conf.Auth.Enabled {
+		var tokens middleware.Authenticator
+		if conf.Auth.JWT.Enabled {
+			tokens = newTokenService(conf.Auth.JWT, logger)
+		}
+
+		middlewares = append(middlewares, middleware.Authenticate(apiKeyService, tokens, logger, "/swagger/", "/metrics", "/healthz", "/readyz"))
+		grpcContextFuncs = append(grpcContextFuncs, middleware.GRPCAuthenticate(apiKeyService, tokens, logger))
+	} else {
+		logger.Warn("authentication is disabled, anyone can run scripts")
+	}
+
+	r := router.MakeRoutes(baseUri, routers, middlewares...)
 
 	server := http.Server{
 		Addr:    fmt.Sprintf(":%v", conf.Server.Port),
 		Handler: r,
 	}
 
+	r.Handle("/metrics", promhttp.Handler())
+
+	// probes are served out of api base path
+	r.Get("/healthz", healthHandler.Live)
+	r.Get("/readyz", healthHandler.Ready)
+
 	// add swagger middleware
 	r.Get("/swagger/*", httpswagger.Handler(
 		httpswagger.URL(fmt.Sprintf("http://localhost:%v/swagger/doc.json", conf.Server.Port)),
@@ -128,6 +309,28 @@ func main() {
 
 	logger.Infof("documentation available on: http://localhost:%v/swagger/index.html", conf.Server.Port)
 
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.UnaryInterceptor(grpcContextFuncs...)),
+		grpc.ChainStreamInterceptor(middleware.StreamInterceptor(grpcContextFuncs...)),
+	)
+
+	rpc.New(scriptService, logger, valid, conf.Handler.DefaultLimit).Register(grpcServer)
+
+	if conf.Server.GRPCPort != 0 {
+		listener, listenErr := net.Listen("tcp", fmt.Sprintf(":%v", conf.Server.GRPCPort))
+		if listenErr != nil {
+			logger.Fatalf("cannot listen gRPC port: %v", listenErr)
+		}
+
+		logger.Infof("gRPC server started at port %v", listener.Addr())
+
+		go func() {
+			if serveErr := grpcServer.Serve(listener); serveErr != nil {
+				logger.WithError(serveErr).Fatalf("gRPC server can't serve calls")
+			}
+		}()
+	}
+
 	// graceful shutdown
 	interrupt := make(chan os.Signal, 1)
 
@@ -139,14 +342,28 @@ func main() {
 
 		logger.Info("interrupt signal caught: shutting server down")
 
+		// readiness fails from now on, so no new requests are routed here once load balancer notices it,
+		// requests routed meanwhile are still served
+		healthService.StartShutdown()
+
+		time.Sleep(time.Duration(conf.Health.ShutdownDelay) * time.Second)
+
 		// stop all running scripts
-		shutdownScripts(ctx, scriptService, cache, logger)
+		app.ShutdownScripts(ctx, scriptService, cache, logger)
 
 		// shutdown http server
-		if shutdownErr := server.Shutdown(ctx); err != nil {
+		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
 			logger.WithError(shutdownErr).Fatalf("can't close server listening on '%s'", server.Addr)
 		}
 
+		// output watches would keep graceful stop waiting until their scripts finish
+		grpcServer.Stop()
+
+		// export spans that are not exported yet
+		if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+			logger.WithError(shutdownErr).Error("can't flush traces")
+		}
+
 		cancel()
 	}()
 
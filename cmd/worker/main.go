@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/app"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+	"pg-start-trainee-2024/internal/service/bus"
+	"pg-start-trainee-2024/internal/service/policy"
+
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	scriprepo "pg-start-trainee-2024/internal/repository/postgres/script"
+	webhookrepo "pg-start-trainee-2024/internal/repository/postgres/webhook"
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	configPath = "./config"
+
+	listenerRetryInterval = 5 * time.Second
+	scriptChangesBuffer   = 100
+)
+
+// worker runs scripts queued by api replicas executing scripts by workers
+func main() {
+	logger := logrus.New()
+	cache := gocache.New(gocache.NoExpiration, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	conf, err := app.InitConfig(configPath)
+	if err != nil {
+		logger.Fatalf("cannot init config: %v", err)
+	}
+
+	if err = logging.Configure(logger, logging.Options{Level: conf.Logging.Level, Format: conf.Logging.Format}); err != nil {
+		logger.Fatalf("cannot configure logging: %v", err)
+	}
+
+	tracing.SetupPropagation()
+
+	shutdownTracing := func(context.Context) error { return nil }
+	if conf.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(ctx, tracing.Options{
+			ServiceName: conf.Tracing.ServiceName + "-worker",
+			Exporter:    conf.Tracing.Exporter,
+			Endpoint:    conf.Tracing.Endpoint,
+			Insecure:    conf.Tracing.Insecure,
+			File:        conf.Tracing.File,
+			SampleRatio: conf.Tracing.SampleRatio,
+		})
+		if err != nil {
+			logger.Fatalf("cannot set up tracing: %v", err)
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Fatalf("cannot get hostname: %v", err)
+	}
+
+	workerID := conf.Worker.ID
+	if workerID == "" {
+		workerID = fmt.Sprintf("%v-%v", hostname, os.Getpid())
+	}
+
+	labels, err := scriptservice.ParseLabels(conf.Worker.Labels)
+	if err != nil {
+		logger.Fatalf("cannot parse worker labels: %v", err)
+	}
+
+	db, err := dbutils.TryToConnectToDB(conf.Postgres.ConnectionURL(), "postgres", conf.Postgres.Retries, conf.Postgres.Interval, logger)
+	if err != nil {
+		logger.Fatalf("cannot connect to db: %v", err)
+	}
+
+	policyEngine, err := policy.New(entity.PolicyAction(conf.Policy.DefaultAction), conf.Policy.PolicyRules(), conf.Policy.RunAsUsers)
+	if err != nil {
+		logger.Fatalf("cannot init command policy: %v", err)
+	}
+
+	allowedNetworks, err := conf.Webhooks.AllowedPrefixes()
+	if err != nil {
+		logger.Fatalf("invalid webhooks config: %v", err)
+	}
+
+	// webhooks are only enqueued here, they are delivered by api replicas
+	webhookService := webhookservice.New(webhookrepo.New(db), auditrepo.New(db), dbutils.NewTransactor(db), logger, webhookservice.Options{
+		CallbackSecret:  conf.Webhooks.CallbackSecret,
+		Timeout:         time.Duration(conf.Webhooks.Timeout) * time.Second,
+		MaxAttempts:     conf.Webhooks.MaxAttempts,
+		BaseBackoff:     time.Duration(conf.Webhooks.BaseBackoff) * time.Second,
+		MaxBackoff:      time.Duration(conf.Webhooks.MaxBackoff) * time.Second,
+		BatchSize:       conf.Webhooks.BatchSize,
+		AllowedNetworks: allowedNetworks,
+	})
+
+	scriptService := scriptservice.New(
+		scriprepo.New(db),
+		auditrepo.New(db),
+		dbutils.NewTransactor(db),
+		cache,
+		policyEngine,
+		webhookService,
+		conf.Quotas.NamespaceQuotas(),
+		logger,
+		scriptservice.ExecutionOptions{
+			Mode:            scriptservice.ExecuteByWorkers,
+			WorkerLostAfter: time.Duration(conf.Worker.LostAfter) * time.Second,
+		},
+		conf.Service.OutputBufferLength,
+		time.Duration(conf.Service.ApprovalTTL)*time.Second,
+	)
+
+	// stop requests are received with LISTEN, worker polls them on heartbeat as well in case notification is missed
+	changeBus := bus.New(scriprepo.NewChangeListener(conf.Postgres.ConnectionURL(), listenerRetryInterval, logger), logger)
+
+	go func() {
+		if busErr := changeBus.Run(ctx); busErr != nil && !errors.Is(busErr, context.Canceled) {
+			logger.Errorf("error occurred listening to script changes: %v", busErr)
+		}
+	}()
+
+	scriptChanges, _ := changeBus.Subscribe(scriptChangesBuffer)
+	go scriptService.WatchScriptChanges(ctx, scriptChanges)
+
+	// graceful shutdown
+	interrupt := make(chan os.Signal, 1)
+
+	signal.Ignore(syscall.SIGHUP, syscall.SIGPIPE)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-interrupt
+
+		logger.Info("interrupt signal caught: shutting worker down")
+
+		// stop all running scripts, so they are not marked as lost
+		app.ShutdownScripts(ctx, scriptService, cache, logger)
+
+		cancel()
+	}()
+
+	err = scriptService.Work(ctx, scriptservice.WorkerOptions{
+		ID:                workerID,
+		Hostname:          hostname,
+		MaxConcurrent:     conf.Worker.MaxConcurrent,
+		Labels:            labels,
+		PollInterval:      time.Duration(conf.Worker.PollInterval) * time.Second,
+		HeartbeatInterval: time.Duration(conf.Worker.HeartbeatInterval) * time.Second,
+		LostAfter:         time.Duration(conf.Worker.LostAfter) * time.Second,
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Fatalf("worker failed: %v", err)
+	}
+
+	// export spans that are not exported yet
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		logger.WithError(shutdownErr).Error("can't flush traces")
+	}
+}
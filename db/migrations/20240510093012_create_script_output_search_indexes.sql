@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- full-text index used by the default (fts) search mode
+CREATE INDEX script_output_fts_idx ON script USING GIN (to_tsvector('simple', coalesce(output, '')));
+
+-- trigram index used by the regex search mode
+CREATE INDEX script_output_trgm_idx ON script USING GIN (output gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX script_output_trgm_idx;
+DROP INDEX script_output_fts_idx;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE script
+    ADD COLUMN status      text      not null default 'running',
+    ADD COLUMN exit_code   integer   null,
+    ADD COLUMN tags        text[]    not null default '{}',
+    ADD COLUMN finished_at timestamp null;
+
+UPDATE script SET status = 'finished', finished_at = updated_at WHERE NOT is_running;
+
+CREATE INDEX script_status_idx ON script (status);
+CREATE INDEX script_finished_at_idx ON script (finished_at);
+CREATE INDEX script_tags_idx ON script USING GIN (tags);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX script_tags_idx;
+DROP INDEX script_finished_at_idx;
+DROP INDEX script_status_idx;
+
+ALTER TABLE script
+    DROP COLUMN finished_at,
+    DROP COLUMN tags,
+    DROP COLUMN exit_code,
+    DROP COLUMN status;
+-- +goose StatementEnd
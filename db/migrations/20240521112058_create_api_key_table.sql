@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_key
+(
+    id           bigserial primary key not null,
+    name         text                  not null,
+    prefix       text                  not null unique,
+    secret_hash  text                  not null,
+    scopes       text[]                not null default '{}',
+    created_at   timestamp             not null default now(),
+    last_used_at timestamp             null,
+    expires_at   timestamp             null,
+    revoked_at   timestamp             null
+);
+
+ALTER TABLE script ADD COLUMN api_key_id bigint null REFERENCES api_key (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE script DROP COLUMN api_key_id;
+
+DROP TABLE api_key;
+-- +goose StatementEnd
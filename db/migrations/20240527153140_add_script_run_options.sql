@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE script
+    ADD COLUMN interpreter text  not null default 'sh',
+    ADD COLUMN env         jsonb not null default '{}',
+    ADD COLUMN run_as      text  not null default '',
+    ADD COLUMN policy_rule text  null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE script
+    DROP COLUMN policy_rule,
+    DROP COLUMN run_as,
+    DROP COLUMN env,
+    DROP COLUMN interpreter;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE script
+    ADD COLUMN reviewed_by         text      null,
+    ADD COLUMN reviewed_at         timestamp null,
+    ADD COLUMN reject_reason       text      null,
+    ADD COLUMN approval_expires_at timestamp null;
+
+CREATE INDEX script_pending_approval_idx ON script (approval_expires_at) WHERE status = 'pending_approval';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX script_pending_approval_idx;
+
+ALTER TABLE script
+    DROP COLUMN approval_expires_at,
+    DROP COLUMN reject_reason,
+    DROP COLUMN reviewed_at,
+    DROP COLUMN reviewed_by;
+-- +goose StatementEnd
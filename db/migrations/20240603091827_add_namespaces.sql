@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE script ADD COLUMN namespace text not null default 'default';
+ALTER TABLE api_key ADD COLUMN namespace text not null default 'default';
+
+CREATE INDEX script_namespace_created_at_id_idx ON script (namespace, created_at, id);
+CREATE INDEX script_namespace_running_idx ON script (namespace) WHERE is_running;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX script_namespace_running_idx;
+DROP INDEX script_namespace_created_at_id_idx;
+
+ALTER TABLE api_key DROP COLUMN namespace;
+ALTER TABLE script DROP COLUMN namespace;
+-- +goose StatementEnd
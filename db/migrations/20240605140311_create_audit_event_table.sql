@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_event
+(
+    id           bigserial primary key not null,
+    actor        text                  null,
+    action       text                  not null,
+    target_type  text                  not null,
+    target_id    text                  not null,
+    request_id   text                  null,
+    client_ip    text                  null,
+    payload_hash text                  null,
+    created_at   timestamp             not null default now()
+);
+
+CREATE INDEX audit_event_target_idx ON audit_event (target_type, target_id);
+CREATE INDEX audit_event_actor_idx ON audit_event (actor);
+CREATE INDEX audit_event_created_at_idx ON audit_event (created_at);
+
+-- audit log is append-only
+CREATE FUNCTION audit_event_immutable() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_immutable
+    BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_immutable();
+
+CREATE TRIGGER audit_event_no_truncate
+    BEFORE TRUNCATE ON audit_event
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_event_immutable();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_event;
+
+DROP FUNCTION audit_event_immutable();
+-- +goose StatementEnd
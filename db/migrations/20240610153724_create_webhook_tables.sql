@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE script ADD COLUMN callback_url text null;
+
+CREATE TABLE webhook
+(
+    id         bigserial primary key not null,
+    name       text                  not null,
+    url        text                  not null,
+    namespace  text                  not null default '',
+    events     text[]                not null default '{}',
+    secret     text                  not null,
+    created_by text                  null,
+    created_at timestamp             not null default now()
+);
+
+CREATE TABLE webhook_delivery
+(
+    id              bigserial primary key not null,
+    webhook_id      bigint                null REFERENCES webhook (id) ON DELETE CASCADE,
+    script_id       bigint                not null REFERENCES script (id) ON DELETE CASCADE,
+    event           text                  not null,
+    url             text                  not null,
+    payload         text                  not null,
+    signature       text                  not null,
+    status          text                  not null default 'pending',
+    attempts        int                   not null default 0,
+    next_attempt_at timestamp             not null default now(),
+    response_status int                   null,
+    last_error      text                  null,
+    created_at      timestamp             not null default now(),
+    delivered_at    timestamp             null
+);
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_webhook_id_idx ON webhook_delivery (webhook_id);
+CREATE INDEX webhook_delivery_script_id_idx ON webhook_delivery (script_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE webhook_delivery;
+DROP TABLE webhook;
+
+ALTER TABLE script DROP COLUMN callback_url;
+-- +goose StatementEnd
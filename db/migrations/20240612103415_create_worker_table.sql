@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE worker
+(
+    id             text primary key not null,
+    hostname       text             not null,
+    max_concurrent int              not null,
+    started_at     timestamp        not null default now(),
+    heartbeat_at   timestamp        not null default now()
+);
+
+-- worker_id is not a foreign key, so scripts of dead worker keep reference to it after it's gone
+ALTER TABLE script ADD COLUMN worker_id text null;
+ALTER TABLE script ADD COLUMN stop_requested_at timestamp null;
+
+CREATE INDEX script_queued_idx ON script (created_at, id) WHERE status = 'queued';
+CREATE INDEX script_worker_id_idx ON script (worker_id) WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX script_worker_id_idx;
+DROP INDEX script_queued_idx;
+
+ALTER TABLE script DROP COLUMN stop_requested_at;
+ALTER TABLE script DROP COLUMN worker_id;
+
+DROP TABLE worker;
+-- +goose StatementEnd
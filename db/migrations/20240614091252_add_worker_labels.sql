@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE worker ADD COLUMN labels jsonb not null default '{}';
+ALTER TABLE script ADD COLUMN label_selector jsonb not null default '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE script DROP COLUMN label_selector;
+ALTER TABLE worker DROP COLUMN labels;
+-- +goose StatementEnd
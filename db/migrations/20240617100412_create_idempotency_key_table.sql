@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_key
+(
+    scope            text      not null,
+    key              text      not null,
+    request_hash     text      not null,
+    script_id        bigint    null,
+    response_status  int       null,
+    response_headers jsonb     not null default '{}',
+    response_body    text      null,
+    lease_token      text      null,
+    created_at       timestamp not null default now(),
+    expires_at       timestamp not null,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_key_expires_at_idx ON idempotency_key (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_key;
+-- +goose StatementEnd
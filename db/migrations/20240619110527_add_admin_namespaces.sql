@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- namespace of event's target, events of targets deleted before are visible to global admins only
+ALTER TABLE audit_event ADD COLUMN namespace text not null default '';
+
+ALTER TABLE audit_event DISABLE TRIGGER audit_event_immutable;
+
+UPDATE audit_event e SET namespace = s.namespace FROM script s WHERE e.target_type = 'script' AND e.target_id = s.id::text;
+UPDATE audit_event e SET namespace = k.namespace FROM api_key k WHERE e.target_type = 'api_key' AND e.target_id = k.id::text;
+
+ALTER TABLE audit_event ENABLE TRIGGER audit_event_immutable;
+
+CREATE INDEX audit_event_namespace_idx ON audit_event (namespace);
+
+ALTER TABLE webhook_delivery ADD COLUMN namespace text not null default '';
+
+UPDATE webhook_delivery d SET namespace = s.namespace FROM script s WHERE d.script_id = s.id;
+
+CREATE INDEX webhook_delivery_namespace_idx ON webhook_delivery (namespace);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE webhook_delivery DROP COLUMN namespace;
+
+ALTER TABLE audit_event DROP COLUMN namespace;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deliveries are history of events, so they outlive scripts they were sent for
+ALTER TABLE webhook_delivery DROP CONSTRAINT webhook_delivery_script_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM webhook_delivery d WHERE NOT EXISTS (SELECT 1 FROM script s WHERE s.id = d.script_id);
+
+ALTER TABLE webhook_delivery
+    ADD CONSTRAINT webhook_delivery_script_id_fkey FOREIGN KEY (script_id) REFERENCES script (id) ON DELETE CASCADE;
+-- +goose StatementEnd
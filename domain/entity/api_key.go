@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+type APIKey struct {
+	ID         int                 `db:"id"`
+	Name       string              `db:"name"`
+	Namespace  string              `db:"namespace"`
+	Prefix     string              `db:"prefix"`
+	SecretHash string              `db:"secret_hash"`
+	Scopes     dbutils.StringArray `db:"scopes"`
+	CreatedAt  time.Time           `db:"created_at"`
+	LastUsedAt *time.Time          `db:"last_used_at"`
+	ExpiresAt  *time.Time          `db:"expires_at"`
+	RevokedAt  *time.Time          `db:"revoked_at"`
+}
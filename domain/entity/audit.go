@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionCreateScript  AuditAction = "script.create"
+	AuditActionStopScript    AuditAction = "script.stop"
+	AuditActionDeleteScript  AuditAction = "script.delete"
+	AuditActionApproveScript AuditAction = "script.approve"
+	AuditActionRejectScript  AuditAction = "script.reject"
+
+	AuditActionCreateAPIKey AuditAction = "api_key.create"
+	AuditActionRevokeAPIKey AuditAction = "api_key.revoke"
+
+	AuditActionCreateWebhook AuditAction = "webhook.create"
+	AuditActionDeleteWebhook AuditAction = "webhook.delete"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetScript  AuditTargetType = "script"
+	AuditTargetAPIKey  AuditTargetType = "api_key"
+	AuditTargetWebhook AuditTargetType = "webhook"
+)
+
+// AuditEvent records action performed by actor, nil actor means anonymous caller.
+// Payload of action itself is not stored as it may contain secrets, only its sha256 hash is
+type AuditEvent struct {
+	ID         int             `db:"id"`
+	Actor      *string         `db:"actor"`
+	Action     AuditAction     `db:"action"`
+	TargetType AuditTargetType `db:"target_type"`
+	TargetID   string          `db:"target_id"`
+	// Namespace of target, events are visible to admins of the namespace only
+	Namespace   string    `db:"namespace"`
+	RequestID   *string   `db:"request_id"`
+	ClientIP    *string   `db:"client_ip"`
+	PayloadHash *string   `db:"payload_hash"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// AuditFilter describes which audit events to list, zero value matches all events
+type AuditFilter struct {
+	Actor      string
+	Actions    []AuditAction
+	TargetType AuditTargetType
+	TargetID   string
+	// Namespace of targets, events of all namespaces match if empty
+	Namespace string
+	From      *time.Time
+	To        *time.Time
+}
+
+// RequestMeta describes request action is performed in
+type RequestMeta struct {
+	RequestID string
+	ClientIP  string
+}
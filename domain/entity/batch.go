@@ -0,0 +1,14 @@
+package entity
+
+// BatchResult is result of operation on one item of batch, batch operations report every item
+// instead of failing on the first failed one
+type BatchResult struct {
+	// Index of item in batch
+	Index int
+	// ID of script item refers to, it's 0 if script is not created
+	ID int
+	// Script is created script, it's set by batch creation only
+	Script *Script
+	// Err is nil if operation on item succeeded
+	Err error
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Cursor points to script in keyset ordered by (created_at, id),
+// backward cursor is used to fetch page located before the script
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+	Backward  bool
+}
+
+type ScriptsPage struct {
+	Scripts    []*Script
+	NextCursor *Cursor
+	PrevCursor *Cursor
+}
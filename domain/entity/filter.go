@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type ScriptSortField string
+
+const (
+	ScriptSortByID         ScriptSortField = "id"
+	ScriptSortByCommand    ScriptSortField = "command"
+	ScriptSortByExitCode   ScriptSortField = "exit_code"
+	ScriptSortByCreatedAt  ScriptSortField = "created_at"
+	ScriptSortByFinishedAt ScriptSortField = "finished_at"
+)
+
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// ScriptFilter describes which scripts to list and in what order, zero value matches all scripts ordered by creation
+type ScriptFilter struct {
+	// Namespace scripts belong to, scripts of all namespaces match if empty
+	Namespace string
+
+	Statuses  []ScriptStatus
+	IsRunning *bool
+	Command   string
+	ExitCode  *int
+	Tags      []string
+
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	FinishedFrom *time.Time
+	FinishedTo   *time.Time
+
+	SortBy        ScriptSortField
+	SortDirection SortDirection
+}
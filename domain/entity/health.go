@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+type HealthCheck struct {
+	Name     string
+	Status   HealthStatus
+	Error    string
+	Duration time.Duration
+}
+
+// HealthReport is up only if all its checks are up
+type HealthReport struct {
+	Status HealthStatus
+	Checks []HealthCheck
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// IdempotencyKey is key client sends to make request at most once, response to completed request is stored
+// to be replayed until key expires. Keys are scoped by principal, so different callers may use the same key. Key in
+// progress is leased to request identified by LeaseToken, only that request may complete or release it
+type IdempotencyKey struct {
+	Scope           string            `db:"scope"`
+	Key             string            `db:"key"`
+	RequestHash     string            `db:"request_hash"`
+	ScriptID        *int              `db:"script_id"`
+	ResponseStatus  *int              `db:"response_status"`
+	ResponseHeaders dbutils.StringMap `db:"response_headers"`
+	ResponseBody    *string           `db:"response_body"`
+	LeaseToken      *string           `db:"lease_token"`
+	CreatedAt       time.Time         `db:"created_at"`
+	ExpiresAt       time.Time         `db:"expires_at"`
+}
+
+// Completed reports whether response to request is stored, request with key that is not completed is in progress
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != nil
+}
@@ -0,0 +1,35 @@
+package entity
+
+const (
+	DefaultNamespace = "default"
+
+	// GlobalNamespace is reserved namespace of admins which may access every namespace, nothing belongs to it
+	GlobalNamespace = "*"
+)
+
+// Quota limits usage of namespace, zero limit means no limit
+type Quota struct {
+	MaxConcurrentScripts int
+	MaxRunsPerDay        int
+	MaxOutputBytes       int64
+}
+
+// Quotas are per-namespace quotas, namespaces without own quota use default one
+type Quotas struct {
+	Default    Quota
+	Namespaces map[string]Quota
+}
+
+func (q *Quotas) For(namespace string) Quota {
+	if quota, ok := q.Namespaces[namespace]; ok {
+		return quota
+	}
+
+	return q.Default
+}
+
+type NamespaceUsage struct {
+	RunningScripts int   `db:"running_scripts"`
+	RunsPerDay     int   `db:"runs_per_day"`
+	OutputBytes    int64 `db:"output_bytes"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+type PolicyAction string
+
+const (
+	PolicyActionAllow           PolicyAction = "allow"
+	PolicyActionDeny            PolicyAction = "deny"
+	PolicyActionRequireApproval PolicyAction = "require_approval"
+)
+
+// PolicyRule matches script if all conditions set in rule are met, empty conditions match any script
+type PolicyRule struct {
+	Name   string
+	Action PolicyAction
+
+	// CommandRegex is matched against whole command, CommandPrefix against every line of command
+	CommandRegex  string
+	CommandPrefix string
+
+	Interpreters []string
+	// EnvKeys match if any of them is requested, "*" matches any requested env
+	EnvKeys []string
+	// RunAs matches if script is run as one of users, "*" matches any user other than default one
+	RunAs []string
+}
+
+// PolicyDecision is outcome of policy evaluation, Rule is empty if no rule matched and default action is taken
+type PolicyDecision struct {
+	Action PolicyAction
+	Rule   string
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "slices"
+
+type Scope = string
+
+const (
+	ScopeScriptsRead  Scope = "scripts:read"
+	ScopeScriptsWrite Scope = "scripts:write"
+	ScopeAdmin        Scope = "admin"
+)
+
+// Principal is authenticated caller of API
+type Principal struct {
+	Subject  string
+	APIKeyID *int
+	Scopes   []Scope
+	// Namespace principal's scripts belong to, admin of GlobalNamespace (e.g. bootstrap key) may access every namespace
+	Namespace string
+	// Roles granted explicitly (e.g. by token's roles claim), roles are also derived from scopes
+	Roles []Role
+	// Claims of token principal is authenticated with, nil for api keys
+	Claims map[string]any
+}
+
+// Role returns the highest role granted to principal, empty role grants nothing
+func (p *Principal) Role() Role {
+	var role Role
+
+	roles := slices.Clone(p.Roles)
+	for _, scope := range p.Scopes {
+		roles = append(roles, roleFromScope(scope))
+	}
+
+	for _, r := range roles {
+		if rank[r] > rank[role] {
+			role = r
+		}
+	}
+
+	return role
+}
+
+// IsGlobal reports whether principal may access every namespace, only admins may be granted GlobalNamespace
+func (p *Principal) IsGlobal() bool {
+	return p.Namespace == GlobalNamespace && p.Role() == RoleAdmin
+}
+
+// HasScope reports whether principal is granted scope, admin is granted every scope
+func (p *Principal) HasScope(scope Scope) bool {
+	return p.Role() == RoleAdmin || slices.Contains(p.Scopes, scope)
+}
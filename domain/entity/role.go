@@ -0,0 +1,68 @@
+package entity
+
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+)
+
+type Operation string
+
+const (
+	OperationReadScript   Operation = "read"
+	OperationCreateScript Operation = "create"
+	OperationStopScript   Operation = "stop"
+	OperationDeleteScript Operation = "delete"
+	OperationReviewScript Operation = "review"
+)
+
+// rank orders roles, every role is granted everything granted to lower ones
+var rank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// IsValid reports whether role is known
+func (r Role) IsValid() bool {
+	_, ok := rank[r]
+
+	return ok
+}
+
+// Can reports whether role may perform operation on script, own tells if script is created by the caller
+func (r Role) Can(op Operation, own bool) bool {
+	switch r {
+	case RoleAdmin:
+		return true
+
+	case RoleOperator:
+		switch op {
+		case OperationReadScript, OperationCreateScript, OperationReviewScript:
+			return true
+		case OperationStopScript, OperationDeleteScript:
+			return own
+		}
+
+	case RoleViewer:
+		return op == OperationReadScript
+	}
+
+	return false
+}
+
+// roleFromScope maps api key scopes to roles
+func roleFromScope(scope Scope) Role {
+	switch scope {
+	case ScopeAdmin:
+		return RoleAdmin
+	case ScopeScriptsWrite:
+		return RoleOperator
+	case ScopeScriptsRead:
+		return RoleViewer
+	default:
+		return ""
+	}
+}
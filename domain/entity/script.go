@@ -1,13 +1,59 @@
 package entity
 
-import "time"
+import (
+	"time"
 
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+type ScriptStatus string
+
+const (
+	ScriptStatusRunning  ScriptStatus = "running"
+	ScriptStatusFinished ScriptStatus = "finished"
+	ScriptStatusFailed   ScriptStatus = "failed"
+	ScriptStatusStopped  ScriptStatus = "stopped"
+
+	// ScriptStatusPendingApproval is status of script waiting for review, it's not started until approved
+	ScriptStatusPendingApproval ScriptStatus = "pending_approval"
+	ScriptStatusRejected        ScriptStatus = "rejected"
+	ScriptStatusExpired         ScriptStatus = "expired"
+
+	// ScriptStatusQueued is status of script waiting for worker to claim it, ScriptStatusLost is status of script
+	// which worker stopped heartbeating while running it
+	ScriptStatusQueued ScriptStatus = "queued"
+	ScriptStatusLost   ScriptStatus = "lost"
+)
+
+// Script is command run with interpreter, PolicyRule is name of policy rule command matched on creation,
+// review fields are set for scripts policy requires approval for, events of script are delivered to CallbackURL if it's set.
+// WorkerID is set for scripts run by workers, StopRequestedAt is set when stop of script is requested from any replica.
+// Script is run only by worker having all labels of LabelSelector
 type Script struct {
-	ID        int       `db:"id"`
-	Command   string    `db:"command"`
-	Output    string    `db:"output"`
-	IsRunning bool      `db:"is_running"`
-	PID       int       `db:"pid"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID                int                 `db:"id"`
+	Namespace         string              `db:"namespace"`
+	Command           string              `db:"command"`
+	Output            string              `db:"output"`
+	IsRunning         bool                `db:"is_running"`
+	PID               int                 `db:"pid"`
+	Status            ScriptStatus        `db:"status"`
+	ExitCode          *int                `db:"exit_code"`
+	Tags              dbutils.StringArray `db:"tags"`
+	APIKeyID          *int                `db:"api_key_id"`
+	CreatedBy         *string             `db:"created_by"`
+	Interpreter       string              `db:"interpreter"`
+	Env               dbutils.StringMap   `db:"env"`
+	RunAs             string              `db:"run_as"`
+	PolicyRule        *string             `db:"policy_rule"`
+	ReviewedBy        *string             `db:"reviewed_by"`
+	ReviewedAt        *time.Time          `db:"reviewed_at"`
+	RejectReason      *string             `db:"reject_reason"`
+	ApprovalExpiresAt *time.Time          `db:"approval_expires_at"`
+	CallbackURL       *string             `db:"callback_url"`
+	LabelSelector     dbutils.StringMap   `db:"label_selector"`
+	WorkerID          *string             `db:"worker_id"`
+	StopRequestedAt   *time.Time          `db:"stop_requested_at"`
+	CreatedAt         time.Time           `db:"created_at"`
+	UpdatedAt         time.Time           `db:"updated_at"`
+	FinishedAt        *time.Time          `db:"finished_at"`
 }
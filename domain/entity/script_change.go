@@ -0,0 +1,26 @@
+package entity
+
+type ScriptChangeOp string
+
+const (
+	ScriptChangeCreated  ScriptChangeOp = "created"
+	ScriptChangeUpdated  ScriptChangeOp = "updated"
+	ScriptChangeFinished ScriptChangeOp = "finished"
+	ScriptChangeDeleted  ScriptChangeOp = "deleted"
+
+	// ScriptChangeResync means changes may have been missed (e.g. connection to db was lost or subscriber was too slow),
+	// so state kept by subscriber must be reloaded, it's not related to any script
+	ScriptChangeResync ScriptChangeOp = "resync"
+)
+
+// ScriptChange is notification about change of script made by any replica of service, it carries only state
+// of script small enough to be sent as notification, the rest is fetched by ID. StopRequested is set when stop of
+// script is requested, so process is stopped by whoever runs it
+type ScriptChange struct {
+	Op            ScriptChangeOp
+	ID            int
+	Namespace     string
+	Status        ScriptStatus
+	IsRunning     bool
+	StopRequested bool
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+type SearchMode string
+
+const (
+	SearchModeFTS   SearchMode = "fts"
+	SearchModeRegex SearchMode = "regex"
+)
+
+type ScriptSearch struct {
+	// Namespace scripts belong to, scripts of all namespaces are searched if empty
+	Namespace string
+
+	Query string
+	Mode  SearchMode
+	From  *time.Time
+	To    *time.Time
+
+	// Words of full-text query, lines of output containing any of them are matched lines
+	Words []string
+}
+
+// MatchedLine is line of output matched by search, Ranges are [start, end) character offsets of regex matches
+// within Text, they are not set for full-text search
+type MatchedLine struct {
+	Number int      `json:"number"`
+	Text   string   `json:"text"`
+	Ranges [][2]int `json:"ranges"`
+}
+
+// ScriptSearchHit is script matched by search with the first matched lines of its output, output itself is not loaded
+type ScriptSearchHit struct {
+	Script *Script
+	Lines  []MatchedLine
+}
+
+type OutputMatch struct {
+	Line    int
+	Snippet string
+}
+
+type ScriptSearchResult struct {
+	Script  *Script
+	Matches []OutputMatch
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// ScriptEvent is lifecycle event of script webhooks are delivered on
+type ScriptEvent string
+
+const (
+	ScriptEventStarted  ScriptEvent = "script.started"
+	ScriptEventFinished ScriptEvent = "script.finished"
+	ScriptEventFailed   ScriptEvent = "script.failed"
+	ScriptEventStopped  ScriptEvent = "script.stopped"
+)
+
+// Webhook is subscription to events of scripts of namespace, empty namespace means scripts of all namespaces.
+// Secret signs delivered payloads, so it's stored as is
+type Webhook struct {
+	ID        int                 `db:"id"`
+	Name      string              `db:"name"`
+	URL       string              `db:"url"`
+	Namespace string              `db:"namespace"`
+	Events    dbutils.StringArray `db:"events"`
+	Secret    string              `db:"secret"`
+	CreatedBy *string             `db:"created_by"`
+	CreatedAt time.Time           `db:"created_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is payload of event to be delivered to URL, nil WebhookID means delivery to callback url of script.
+// Signature is computed on creation, so attempts send exactly the same request. Deliveries are kept after their script
+// is deleted
+type WebhookDelivery struct {
+	ID             int                   `db:"id"`
+	WebhookID      *int                  `db:"webhook_id"`
+	ScriptID       int                   `db:"script_id"`
+	Namespace      string                `db:"namespace"`
+	Event          ScriptEvent           `db:"event"`
+	URL            string                `db:"url"`
+	Payload        string                `db:"payload"`
+	Signature      string                `db:"signature"`
+	Status         WebhookDeliveryStatus `db:"status"`
+	Attempts       int                   `db:"attempts"`
+	NextAttemptAt  time.Time             `db:"next_attempt_at"`
+	ResponseStatus *int                  `db:"response_status"`
+	LastError      *string               `db:"last_error"`
+	CreatedAt      time.Time             `db:"created_at"`
+	DeliveredAt    *time.Time            `db:"delivered_at"`
+}
+
+// WebhookDeliveryFilter describes which deliveries to list, zero value matches all deliveries
+type WebhookDeliveryFilter struct {
+	WebhookID *int
+	ScriptID  *int
+	// Namespace of scripts, deliveries of all namespaces match if empty
+	Namespace string
+	Status    WebhookDeliveryStatus
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// Worker is process running queued scripts, it owns scripts it claimed while it heartbeats.
+// Worker runs only scripts which label selector is matched by its labels, label without value has empty value
+type Worker struct {
+	ID            string            `db:"id"`
+	Hostname      string            `db:"hostname"`
+	MaxConcurrent int               `db:"max_concurrent"`
+	Labels        dbutils.StringMap `db:"labels"`
+	StartedAt     time.Time         `db:"started_at"`
+	HeartbeatAt   time.Time         `db:"heartbeat_at"`
+}
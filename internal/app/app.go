@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"pg-start-trainee-2024/internal/config"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+)
+
+// InitConfig reads config.yaml of configPath, env variables of configPath/.env and of environment override its values
+func InitConfig(configPath string) (*config.Config, error) {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+
+	viper.AddConfigPath(configPath)
+
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	// env variables, loaded before unmarshalling so they override config values (e.g. PG_START_TRAINEE_AUTH_BOOTSTRAP_KEY)
+	if err := godotenv.Load(configPath + "/.env"); err != nil {
+		return nil, err
+	}
+
+	viper.SetEnvPrefix("pg_start_trainee")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	var conf config.Config
+	if err := viper.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+
+	return &conf, nil
+}
+
+// ShutdownScripts stops scripts run by this process, cache holds running scripts by their IDs
+func ShutdownScripts(ctx context.Context, scriptService *scriptservice.Service, cache *gocache.Cache, logger *logrus.Logger) {
+	for k := range cache.Items() {
+		if id, err := strconv.Atoi(k); err == nil {
+			err = scriptService.StopScript(ctx, id)
+			if err != nil {
+				logger.Errorf("error occurred stopping script: %v", err)
+			}
+		}
+	}
+}
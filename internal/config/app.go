@@ -5,4 +5,13 @@ type Config struct {
 	Postgres
 	Service
 	Handler
+	Auth
+	Policy
+	Quotas
+	Health
+	Tracing
+	Logging
+	Webhooks
+	Executor
+	Worker
 }
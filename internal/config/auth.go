@@ -0,0 +1,25 @@
+package config
+
+type Auth struct {
+	Enabled bool
+	// BootstrapKey is accepted as admin api key, it's meant to create the first keys
+	BootstrapKey string `mapstructure:"bootstrap_key"`
+
+	JWT JWT
+}
+
+type JWT struct {
+	Enabled  bool
+	Issuers  []string
+	Audience string
+
+	// JWKS is loaded from file if JWKSFile is set, otherwise from JWKSURL
+	JWKSFile string `mapstructure:"jwks_file"`
+	JWKSURL  string `mapstructure:"jwks_url"`
+
+	// JWKSCacheTTL, JWKSMaxStale and Leeway are in seconds, JWKS that can't be refreshed is used until
+	// it's older than JWKSMaxStale
+	JWKSCacheTTL int `mapstructure:"jwks_cache_ttl"`
+	JWKSMaxStale int `mapstructure:"jwks_max_stale"`
+	Leeway       int
+}
@@ -0,0 +1,19 @@
+package config
+
+type Executor struct {
+	// Mode is local to run scripts on replica they are created on or workers to queue them for workers
+	Mode string
+}
+
+type Worker struct {
+	// ID identifies worker, hostname and pid of worker are used if it's empty
+	ID            string
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	// Labels are written as key=value or just key, e.g. region=eu or has-docker
+	Labels []string
+	// PollInterval is time in seconds between claims of queued scripts
+	PollInterval      int `mapstructure:"poll_interval"`
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"`
+	// LostAfter is time in seconds without heartbeat after which scripts of worker are marked as lost
+	LostAfter int `mapstructure:"lost_after"`
+}
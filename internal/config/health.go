@@ -0,0 +1,9 @@
+package config
+
+type Health struct {
+	// MigrationsDir is directory with goose migrations, db must be migrated to the latest one to be ready
+	MigrationsDir string `mapstructure:"migrations_dir"`
+	// ShutdownDelay is time in seconds service keeps serving requests after readiness starts failing on shutdown,
+	// so load balancer notices it and stops routing requests here before server is closed
+	ShutdownDelay int `mapstructure:"shutdown_delay"`
+}
@@ -0,0 +1,8 @@
+package config
+
+type Logging struct {
+	// Level is one of logrus levels, e.g. debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+}
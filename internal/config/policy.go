@@ -0,0 +1,38 @@
+package config
+
+import "pg-start-trainee-2024/domain/entity"
+
+type Policy struct {
+	DefaultAction string `mapstructure:"default_action"`
+	Rules         []PolicyRule
+	// RunAsUsers are users scripts may be run as, scripts can't be run as another user if it's empty
+	RunAsUsers []string `mapstructure:"run_as_users"`
+}
+
+type PolicyRule struct {
+	Name          string
+	Action        string
+	CommandRegex  string `mapstructure:"command_regex"`
+	CommandPrefix string `mapstructure:"command_prefix"`
+	Interpreters  []string
+	EnvKeys       []string `mapstructure:"env_keys"`
+	RunAs         []string `mapstructure:"run_as"`
+}
+
+func (p *Policy) PolicyRules() []entity.PolicyRule {
+	rules := make([]entity.PolicyRule, 0, len(p.Rules))
+
+	for _, rule := range p.Rules {
+		rules = append(rules, entity.PolicyRule{
+			Name:          rule.Name,
+			Action:        entity.PolicyAction(rule.Action),
+			CommandRegex:  rule.CommandRegex,
+			CommandPrefix: rule.CommandPrefix,
+			Interpreters:  rule.Interpreters,
+			EnvKeys:       rule.EnvKeys,
+			RunAs:         rule.RunAs,
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,36 @@
+package config
+
+import "pg-start-trainee-2024/domain/entity"
+
+type Quotas struct {
+	Default    Quota
+	Namespaces map[string]Quota
+}
+
+// Quota limits namespace usage, zero limit means no limit
+type Quota struct {
+	MaxConcurrentScripts int   `mapstructure:"max_concurrent_scripts"`
+	MaxRunsPerDay        int   `mapstructure:"max_runs_per_day"`
+	MaxOutputBytes       int64 `mapstructure:"max_output_bytes"`
+}
+
+func (q Quota) quota() entity.Quota {
+	return entity.Quota{
+		MaxConcurrentScripts: q.MaxConcurrentScripts,
+		MaxRunsPerDay:        q.MaxRunsPerDay,
+		MaxOutputBytes:       q.MaxOutputBytes,
+	}
+}
+
+func (q *Quotas) NamespaceQuotas() entity.Quotas {
+	namespaces := make(map[string]entity.Quota, len(q.Namespaces))
+
+	for namespace, quota := range q.Namespaces {
+		namespaces[namespace] = quota.quota()
+	}
+
+	return entity.Quotas{
+		Default:    q.Default.quota(),
+		Namespaces: namespaces,
+	}
+}
@@ -2,4 +2,6 @@ package config
 
 type Server struct {
 	Port int
+	// GRPCPort is port gRPC API is served on, gRPC API is disabled if it's 0
+	GRPCPort int `mapstructure:"grpc_port"`
 }
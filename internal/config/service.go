@@ -2,4 +2,11 @@ package config
 
 type Service struct {
 	OutputBufferLength int `mapstructure:"output_buffer_length"`
+	// ApprovalTTL is time in seconds script may wait for approval
+	ApprovalTTL int `mapstructure:"approval_ttl"`
+	// IdempotencyTTL is time in seconds response to request with idempotency key is replayed for
+	IdempotencyTTL int `mapstructure:"idempotency_ttl"`
+	// IdempotencyLease is time in seconds request with idempotency key is considered in progress for, key of request
+	// that neither completed nor released within it (e.g. process died) is claimed anew
+	IdempotencyLease int `mapstructure:"idempotency_lease"`
 }
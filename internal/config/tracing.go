@@ -0,0 +1,14 @@
+package config
+
+type Tracing struct {
+	Enabled     bool
+	ServiceName string `mapstructure:"service_name"`
+	// Exporter is otlp, stdout or file
+	Exporter string
+	// Endpoint is host:port of OTLP/HTTP collector, OTEL_EXPORTER_OTLP_* env variables are used if it's empty
+	Endpoint string
+	Insecure bool
+	// File is path spans are written to by file exporter
+	File        string
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
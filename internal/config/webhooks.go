@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+type Webhooks struct {
+	// CallbackSecret signs payloads delivered to callback urls of scripts, callback urls are rejected if it's empty
+	CallbackSecret string `mapstructure:"callback_secret"`
+	// PollInterval is time in seconds between polls of due deliveries
+	PollInterval int `mapstructure:"poll_interval"`
+	// Timeout is timeout in seconds of single delivery attempt
+	Timeout     int
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// BaseBackoff is delay in seconds before the first retry, every next retry is delayed twice longer up to MaxBackoff
+	BaseBackoff int `mapstructure:"base_backoff"`
+	MaxBackoff  int `mapstructure:"max_backoff"`
+	BatchSize   int `mapstructure:"batch_size"`
+	// AllowedNetworks are CIDRs webhooks may be delivered to although they are not public (loopback, private etc)
+	AllowedNetworks []string `mapstructure:"allowed_networks"`
+}
+
+func (w *Webhooks) AllowedPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(w.AllowedNetworks))
+
+	for _, network := range w.AllowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("allowed network %q: %w", network, err)
+		}
+
+		prefixes = append(prefixes, prefix)
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,184 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/pkg/auth"
+
+	apikeyservice "pg-start-trainee-2024/internal/service/apikey"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+type Service interface {
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (*entity.APIKey, string, error)
+	GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger    *logrus.Logger
+	validator *validator.Validate
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:     service,
+		Middlewares: middlewares,
+		logger:      logger,
+		validator:   validator,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Post("/", h.CreateAPIKey)
+		r.Get("/", h.GetAllAPIKeys)
+		r.Delete("/{id}", h.RevokeAPIKey)
+	})
+
+	return router
+}
+
+// problemFromError maps errors returned by Service to problems, unknown errors are internal ones
+func problemFromError(err error) *handlerutils.Problem {
+	switch {
+	case errors.Is(err, apikeyservice.ErrNoSuchAPIKey):
+		return handlerutils.NewNotFoundProblem("api key not found")
+
+	case errors.Is(err, apikeyservice.ErrUnknownScope),
+		errors.Is(err, apikeyservice.ErrNamespaceRequired),
+		errors.Is(err, apikeyservice.ErrGlobalKeyNotAdmin):
+		return handlerutils.NewBadRequestProblem(err.Error())
+
+	case errors.Is(err, auth.ErrOtherNamespace):
+		return handlerutils.NewForbiddenProblem(err.Error())
+
+	default:
+		return handlerutils.NewInternalProblem()
+	}
+}
+
+func (h *Handler) writeProblem(rw http.ResponseWriter, req *http.Request, problem *handlerutils.Problem, logMsg string) {
+	handlerutils.WriteProblemAndLog(rw, req, h.logger, problem, logMsg)
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Create api key
+//	@Description	Create api key, plain key is returned only in this response and can't be restored later.
+//	@Description	Admin bound to namespace creates keys of own namespace only, global admin must set namespace,
+//	@Description	namespace "*" grants admin key every namespace
+//	@Tags			API key
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		request.CreateAPIKey	true	"create api key schema"
+//	@Success		201		{object}	response.CreateAPIKey
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(rw http.ResponseWriter, req *http.Request) {
+	var keyReq request.CreateAPIKey
+
+	if err := render.DecodeJSON(req.Body, &keyReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateAPIKey request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err := keyReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateAPIKey request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	created, plainKey, err := h.Service.CreateAPIKey(req.Context(), mapper.MapCreateAPIKeyRequestToEntity(&keyReq))
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred creating api key: %v", err))
+
+		return
+	}
+
+	render.Status(req, http.StatusCreated)
+	render.JSON(rw, req, mapper.MapAPIKeyToCreateAPIKeyResponse(created, plainKey))
+}
+
+// GetAllAPIKeys godoc
+//
+//	@Summary		Get api keys
+//	@Description	Get all api keys of caller's namespace including revoked ones, secrets are never returned
+//	@Tags			API key
+//	@Produce		json
+//	@Success		200	{object}	[]response.APIKey
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/api-keys [get]
+func (h *Handler) GetAllAPIKeys(rw http.ResponseWriter, req *http.Request) {
+	keys, err := h.Service.GetAllAPIKeys(req.Context())
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching api keys: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(keys, mapper.MapAPIKeyToResponse))
+}
+
+// RevokeAPIKey godoc
+//
+//	@Summary		Revoke api key
+//	@Description	Revoke api key by ID, revoked key can't be used anymore
+//	@Tags			API key
+//	@Param			id	path	int	true	"api key ID"
+//	@Success		204
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid api key id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("api key id must be an integer"), msg)
+
+		return
+	}
+
+	if err = h.Service.RevokeAPIKey(req.Context(), id); err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred revoking api key: %v", err))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/pkg/auth"
+
+	handlerinternalutils "pg-start-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+type Service interface {
+	GetAuditEvents(ctx context.Context, filter entity.AuditFilter, offset, limit int) ([]*entity.AuditEvent, error)
+	ExportAuditEvents(ctx context.Context, filter entity.AuditFilter, write func(event *entity.AuditEvent) error) error
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger        *logrus.Logger
+	validator     *validator.Validate
+	defaultOffset int
+	defaultLimit  int
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, defaultOffset, defaultLimit int, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:       service,
+		Middlewares:   middlewares,
+		logger:        logger,
+		validator:     validator,
+		defaultOffset: defaultOffset,
+		defaultLimit:  defaultLimit,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Get("/", h.GetAuditEvents)
+		r.Get("/export", h.ExportAuditEvents)
+	})
+
+	return router
+}
+
+// problemFromError maps errors returned by Service to problems, unknown errors are internal ones
+func problemFromError(err error) *handlerutils.Problem {
+	switch {
+	case errors.Is(err, auth.ErrOtherNamespace):
+		return handlerutils.NewForbiddenProblem(err.Error())
+
+	default:
+		return handlerutils.NewInternalProblem()
+	}
+}
+
+func (h *Handler) writeProblem(rw http.ResponseWriter, req *http.Request, problem *handlerutils.Problem, logMsg string) {
+	handlerutils.WriteProblemAndLog(rw, req, h.logger, problem, logMsg)
+}
+
+// auditFilterFromQuery parses and validates filter, problem is written if it's invalid
+func (h *Handler) auditFilterFromQuery(rw http.ResponseWriter, req *http.Request) (request.AuditFilter, bool) {
+	filterReq, err := handlerinternalutils.GetAuditFilterFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing AuditFilter request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return request.AuditFilter{}, false
+	}
+
+	if err = filterReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid filter provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return request.AuditFilter{}, false
+	}
+
+	return filterReq, true
+}
+
+// GetAuditEvents godoc
+//
+//	@Summary		Get audit events
+//	@Description	Get audit events matching filter, most recent first. Admins bound to namespace get events of own namespace only
+//	@Tags			Audit
+//	@Produce		json
+//	@Param			actor		query		string		false	"Actor (principal subject)"
+//	@Param			action		query		[]string	false	"Actions"	collectionFormat(csv)	Enums(script.create, script.stop, script.delete, script.approve, script.reject, api_key.create, api_key.revoke, webhook.create, webhook.delete)
+//	@Param			target_type	query		string		false	"Target type"	Enums(script, api_key, webhook)
+//	@Param			target_id	query		string		false	"Target ID"
+//	@Param			namespace	query		string		false	"Namespace of targets, admin bound to namespace gets events of own namespace only"
+//	@Param			from		query		string		false	"Created at lower bound (RFC3339)"
+//	@Param			to			query		string		false	"Created at upper bound (RFC3339)"
+//	@Param			offset		query		int			false	"Offset"
+//	@Param			limit		query		int			false	"Limit"
+//	@Success		200			{object}	[]response.AuditEvent
+//	@Failure		400			{object}	handler.Problem
+//	@Failure		401			{object}	handler.Problem
+//	@Failure		403			{object}	handler.Problem
+//	@Failure		500			{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/audit-events [get]
+func (h *Handler) GetAuditEvents(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	filterReq, ok := h.auditFilterFromQuery(rw, req)
+	if !ok {
+		return
+	}
+
+	events, err := h.Service.GetAuditEvents(
+		req.Context(),
+		mapper.MapAuditFilterRequestToEntity(&filterReq),
+		paginationOpts.Offset,
+		paginationOpts.Limit,
+	)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching audit events: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(events, mapper.MapAuditEventToResponse))
+}
+
+// ExportAuditEvents godoc
+//
+//	@Summary		Export audit events
+//	@Description	Export all audit events matching filter as JSON Lines in order they were written
+//	@Tags			Audit
+//	@Produce		application/x-ndjson
+//	@Param			actor		query		string		false	"Actor (principal subject)"
+//	@Param			action		query		[]string	false	"Actions"	collectionFormat(csv)	Enums(script.create, script.stop, script.delete, script.approve, script.reject, api_key.create, api_key.revoke, webhook.create, webhook.delete)
+//	@Param			target_type	query		string		false	"Target type"	Enums(script, api_key, webhook)
+//	@Param			target_id	query		string		false	"Target ID"
+//	@Param			namespace	query		string		false	"Namespace of targets, admin bound to namespace gets events of own namespace only"
+//	@Param			from		query		string		false	"Created at lower bound (RFC3339)"
+//	@Param			to			query		string		false	"Created at upper bound (RFC3339)"
+//	@Success		200			{object}	response.AuditEvent	"one event per line"
+//	@Failure		400			{object}	handler.Problem
+//	@Failure		401			{object}	handler.Problem
+//	@Failure		403			{object}	handler.Problem
+//	@Failure		500			{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/audit-events/export [get]
+func (h *Handler) ExportAuditEvents(rw http.ResponseWriter, req *http.Request) {
+	filterReq, ok := h.auditFilterFromQuery(rw, req)
+	if !ok {
+		return
+	}
+
+	encoder := json.NewEncoder(rw)
+	written := false
+
+	err := h.Service.ExportAuditEvents(req.Context(), mapper.MapAuditFilterRequestToEntity(&filterReq), func(event *entity.AuditEvent) error {
+		if !written {
+			rw.Header().Set("Content-Type", "application/x-ndjson")
+			rw.WriteHeader(http.StatusOK)
+
+			written = true
+		}
+
+		return encoder.Encode(mapper.MapAuditEventToResponse(event))
+	})
+
+	switch {
+	case err != nil && written:
+		// status is already sent, client sees truncated export
+		h.logger.WithContext(req.Context()).Errorf("error occurred exporting audit events: %v", err)
+
+	case err != nil:
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred exporting audit events: %v", err))
+
+	case !written:
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+)
+
+type Service interface {
+	Live() entity.HealthReport
+	Ready(ctx context.Context) entity.HealthReport
+}
+
+type Handler struct {
+	Service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		Service: service,
+	}
+}
+
+func writeReport(rw http.ResponseWriter, req *http.Request, report entity.HealthReport) {
+	if report.Status != entity.HealthStatusUp {
+		render.Status(req, http.StatusServiceUnavailable)
+	}
+
+	render.JSON(rw, req, mapper.MapHealthReportToResponse(report))
+}
+
+// Live godoc
+//
+//	@Summary		Liveness probe
+//	@Description	Reports that process is alive
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	response.HealthReport
+//	@Router			/healthz [get]
+func (h *Handler) Live(rw http.ResponseWriter, req *http.Request) {
+	writeReport(rw, req, h.Service.Live())
+}
+
+// Ready godoc
+//
+//	@Summary		Readiness probe
+//	@Description	Reports whether service can serve requests: db is reachable and migrated, scripts can be started
+//	@Description	and service is not shutting down
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	response.HealthReport
+//	@Failure		503	{object}	response.HealthReport
+//	@Router			/readyz [get]
+func (h *Handler) Ready(rw http.ResponseWriter, req *http.Request) {
+	writeReport(rw, req, h.Service.Ready(req.Context()))
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+)
+
+func MapCreateAPIKeyRequestToEntity(createRequest *request.CreateAPIKey) entity.APIKey {
+	return entity.APIKey{
+		Name:      createRequest.Name,
+		Namespace: createRequest.Namespace,
+		Scopes:    createRequest.Scopes,
+		ExpiresAt: createRequest.ExpiresAt,
+	}
+}
+
+func MapAPIKeyToResponse(key *entity.APIKey) response.APIKey {
+	return response.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Namespace:  key.Namespace,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func MapAPIKeyToCreateAPIKeyResponse(key *entity.APIKey, plainKey string) response.CreateAPIKey {
+	return response.CreateAPIKey{
+		APIKey: MapAPIKeyToResponse(key),
+		Key:    plainKey,
+	}
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+func MapAuditFilterRequestToEntity(filterRequest *request.AuditFilter) entity.AuditFilter {
+	return entity.AuditFilter{
+		Actor:      filterRequest.Actor,
+		Actions:    sliceutils.Map(filterRequest.Actions, func(a string) entity.AuditAction { return entity.AuditAction(a) }),
+		TargetType: entity.AuditTargetType(filterRequest.TargetType),
+		TargetID:   filterRequest.TargetID,
+		Namespace:  filterRequest.Namespace,
+		From:       filterRequest.From,
+		To:         filterRequest.To,
+	}
+}
+
+func MapAuditEventToResponse(event *entity.AuditEvent) response.AuditEvent {
+	return response.AuditEvent{
+		ID:          event.ID,
+		Actor:       event.Actor,
+		Action:      string(event.Action),
+		TargetType:  string(event.TargetType),
+		TargetID:    event.TargetID,
+		Namespace:   event.Namespace,
+		RequestID:   event.RequestID,
+		ClientIP:    event.ClientIP,
+		PayloadHash: event.PayloadHash,
+		CreatedAt:   event.CreatedAt,
+	}
+}
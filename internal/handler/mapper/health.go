@@ -0,0 +1,31 @@
+package mapper
+
+import (
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+func MapHealthCheckToResponse(check entity.HealthCheck) response.HealthCheck {
+	resp := response.HealthCheck{
+		Name:       check.Name,
+		Status:     string(check.Status),
+		DurationMS: float64(check.Duration) / float64(time.Millisecond),
+	}
+
+	if check.Error != "" {
+		resp.Error = &check.Error
+	}
+
+	return resp
+}
+
+func MapHealthReportToResponse(report entity.HealthReport) response.HealthReport {
+	return response.HealthReport{
+		Status: string(report.Status),
+		Checks: sliceutils.Map(report.Checks, MapHealthCheckToResponse),
+	}
+}
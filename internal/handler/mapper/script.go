@@ -4,11 +4,20 @@ import (
 	"pg-start-trainee-2024/domain/entity"
 	"pg-start-trainee-2024/internal/handler/request"
 	"pg-start-trainee-2024/internal/handler/response"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
 )
 
 func MapCreateScriptRequestToEntity(createRequest *request.CreateScript) entity.Script {
 	return entity.Script{
-		Command: createRequest.Command,
+		Command:       createRequest.Command,
+		Tags:          createRequest.Tags,
+		Interpreter:   createRequest.Interpreter,
+		Env:           createRequest.Env,
+		RunAs:         createRequest.RunAs,
+		CallbackURL:   optional(createRequest.CallbackURL),
+		LabelSelector: createRequest.LabelSelector,
 	}
 }
 
@@ -17,17 +26,123 @@ func MapScriptToCreateScriptResponse(script *entity.Script) response.CreateScrip
 		ID:      script.ID,
 		Command: script.Command,
 		PID:     script.PID,
+		Status:  string(script.Status),
+		Tags:    script.Tags,
 	}
 }
 
 func MapScriptToGetScriptResponse(script *entity.Script) response.GetScript {
 	return response.GetScript{
-		ID:        script.ID,
-		Command:   script.Command,
-		Output:    script.Output,
-		IsRunning: script.IsRunning,
-		PID:       script.PID,
-		CreatedAt: script.CreatedAt,
-		UpdatedAt: script.UpdatedAt,
+		ID:                script.ID,
+		Namespace:         script.Namespace,
+		Command:           script.Command,
+		Output:            script.Output,
+		IsRunning:         script.IsRunning,
+		PID:               script.PID,
+		Status:            string(script.Status),
+		ExitCode:          script.ExitCode,
+		Tags:              script.Tags,
+		APIKeyID:          script.APIKeyID,
+		CreatedBy:         script.CreatedBy,
+		Interpreter:       script.Interpreter,
+		RunAs:             script.RunAs,
+		PolicyRule:        script.PolicyRule,
+		ReviewedBy:        script.ReviewedBy,
+		ReviewedAt:        script.ReviewedAt,
+		RejectReason:      script.RejectReason,
+		ApprovalExpiresAt: script.ApprovalExpiresAt,
+		CallbackURL:       script.CallbackURL,
+		LabelSelector:     script.LabelSelector,
+		WorkerID:          script.WorkerID,
+		StopRequestedAt:   script.StopRequestedAt,
+		CreatedAt:         script.CreatedAt,
+		UpdatedAt:         script.UpdatedAt,
+		FinishedAt:        script.FinishedAt,
+	}
+}
+
+func MapSearchScriptsRequestToEntity(searchRequest *request.SearchScripts) entity.ScriptSearch {
+	return entity.ScriptSearch{
+		Query: searchRequest.Query,
+		Mode:  entity.SearchMode(searchRequest.Mode),
+		From:  searchRequest.From,
+		To:    searchRequest.To,
+	}
+}
+
+func MapOutputMatchToResponse(match entity.OutputMatch) response.OutputMatch {
+	return response.OutputMatch{
+		Line:    match.Line,
+		Snippet: match.Snippet,
+	}
+}
+
+func MapScriptSearchResultToResponse(result *entity.ScriptSearchResult) response.SearchScript {
+	return response.SearchScript{
+		ID:        result.Script.ID,
+		Command:   result.Script.Command,
+		IsRunning: result.Script.IsRunning,
+		CreatedAt: result.Script.CreatedAt,
+		Matches:   sliceutils.Map(result.Matches, MapOutputMatchToResponse),
+	}
+}
+
+func MapScriptFilterRequestToEntity(filterRequest *request.ScriptFilter) entity.ScriptFilter {
+	return entity.ScriptFilter{
+		Statuses:      sliceutils.Map(filterRequest.Statuses, func(s string) entity.ScriptStatus { return entity.ScriptStatus(s) }),
+		IsRunning:     filterRequest.IsRunning,
+		Command:       filterRequest.Command,
+		ExitCode:      filterRequest.ExitCode,
+		Tags:          filterRequest.Tags,
+		CreatedFrom:   filterRequest.CreatedFrom,
+		CreatedTo:     filterRequest.CreatedTo,
+		FinishedFrom:  filterRequest.FinishedFrom,
+		FinishedTo:    filterRequest.FinishedTo,
+		SortBy:        entity.ScriptSortField(filterRequest.Sort),
+		SortDirection: entity.SortDirection(filterRequest.Order),
+	}
+}
+
+func MapScriptsPageToResponse(page *entity.ScriptsPage, encodeCursor func(*entity.Cursor) string) response.ScriptsPage {
+	return response.ScriptsPage{
+		Items:      sliceutils.Map(page.Scripts, MapScriptToGetScriptResponse),
+		NextCursor: encodeCursor(page.NextCursor),
+		PrevCursor: encodeCursor(page.PrevCursor),
+	}
+}
+
+func MapPolicyDecisionToResponse(decision *entity.PolicyDecision) response.PolicyDecision {
+	return response.PolicyDecision{
+		Action: string(decision.Action),
+		Rule:   decision.Rule,
+	}
+}
+
+// MapBatchResultsToResponse maps results of batch, problem of failed item is made of its error by problemFromError
+func MapBatchResultsToResponse(results []entity.BatchResult, problemFromError func(error) *handlerutils.Problem) response.BatchResult {
+	batch := response.BatchResult{Items: make([]response.BatchItem, 0, len(results))}
+
+	for _, result := range results {
+		item := response.BatchItem{Index: result.Index}
+
+		if result.ID != 0 {
+			id := result.ID
+			item.ID = &id
+		}
+
+		if result.Script != nil {
+			item.Status = string(result.Script.Status)
+		}
+
+		if result.Err != nil {
+			item.Error = problemFromError(result.Err)
+			batch.Failed++
+		} else {
+			batch.Succeeded++
+		}
+
+		batch.Items = append(batch.Items, item)
 	}
+
+	return batch
 }
@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+)
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func MapCreateWebhookRequestToEntity(createRequest *request.CreateWebhook) entity.Webhook {
+	return entity.Webhook{
+		Name:      createRequest.Name,
+		URL:       createRequest.URL,
+		Namespace: createRequest.Namespace,
+		Events:    createRequest.Events,
+	}
+}
+
+func MapWebhookToResponse(webhook *entity.Webhook) response.Webhook {
+	return response.Webhook{
+		ID:        webhook.ID,
+		Name:      webhook.Name,
+		URL:       webhook.URL,
+		Namespace: webhook.Namespace,
+		Events:    webhook.Events,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func MapWebhookToCreateWebhookResponse(webhook *entity.Webhook) response.CreateWebhook {
+	return response.CreateWebhook{
+		Webhook: MapWebhookToResponse(webhook),
+		Secret:  webhook.Secret,
+	}
+}
+
+func MapWebhookDeliveryFilterRequestToEntity(filterRequest *request.WebhookDeliveryFilter) entity.WebhookDeliveryFilter {
+	return entity.WebhookDeliveryFilter{
+		WebhookID: filterRequest.WebhookID,
+		ScriptID:  filterRequest.ScriptID,
+		Namespace: filterRequest.Namespace,
+		Status:    entity.WebhookDeliveryStatus(filterRequest.Status),
+	}
+}
+
+func MapWebhookDeliveryToResponse(delivery *entity.WebhookDelivery) response.WebhookDelivery {
+	return response.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		ScriptID:       delivery.ScriptID,
+		Event:          string(delivery.Event),
+		URL:            delivery.URL,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
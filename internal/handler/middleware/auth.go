@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+
+	apikeyservice "pg-start-trainee-2024/internal/service/apikey"
+	tokenservice "pg-start-trainee-2024/internal/service/token"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	apiKeyHeader        = "X-API-Key"
+	bearerPrefix        = "Bearer "
+)
+
+type Middleware = func(http.Handler) http.Handler
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials string) (*entity.Principal, error)
+}
+
+// credentialsFromRequest returns api key or bearer token, isJWT reports whether bearer token looks like JWT
+func credentialsFromRequest(req *http.Request) (credentials string, isJWT bool) {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		return key, false
+	}
+
+	if header := req.Header.Get(authorizationHeader); strings.HasPrefix(header, bearerPrefix) {
+		token := strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))
+
+		return token, strings.Count(token, ".") == 2
+	}
+
+	return "", false
+}
+
+func isUnauthenticated(err error) bool {
+	return errors.Is(err, apikeyservice.ErrInvalidAPIKey) ||
+		errors.Is(err, apikeyservice.ErrAPIKeyRevoked) ||
+		errors.Is(err, apikeyservice.ErrAPIKeyExpired) ||
+		errors.Is(err, tokenservice.ErrInvalidToken)
+}
+
+// Authenticate rejects requests without valid api key or JWT and stores authenticated principal in request context,
+// tokens may be nil if JWT authentication is disabled, requests to paths starting with one of publicPrefixes are passed as is
+func Authenticate(apiKeys, tokens Authenticator, logger *logrus.Logger, publicPrefixes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			for _, prefix := range publicPrefixes {
+				if strings.HasPrefix(req.URL.Path, prefix) {
+					next.ServeHTTP(rw, req)
+
+					return
+				}
+			}
+
+			credentials, isJWT := credentialsFromRequest(req)
+			if credentials == "" {
+				handlerutils.WriteProblemAndLog(rw, req, logger, handlerutils.NewUnauthorizedProblem("api key or token is required"), "")
+
+				return
+			}
+
+			authenticator := apiKeys
+			if isJWT && tokens != nil {
+				authenticator = tokens
+			}
+
+			principal, err := authenticator.Authenticate(req.Context(), credentials)
+			if err != nil {
+				if isUnauthenticated(err) {
+					handlerutils.WriteProblemAndLog(rw, req, logger, handlerutils.NewUnauthorizedProblem(err.Error()), "")
+
+					return
+				}
+
+				msg := fmt.Sprintf("error occurred authenticating request: %v", err)
+
+				handlerutils.WriteProblemAndLog(rw, req, logger, handlerutils.NewInternalProblem(), msg)
+
+				return
+			}
+
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	}
+}
+
+// RequireScope rejects requests of principals that are not granted scope
+func RequireScope(scope entity.Scope, logger *logrus.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			// no principal means authentication is disabled
+			principal, ok := auth.PrincipalFromContext(req.Context())
+			if ok && !principal.HasScope(scope) {
+				problem := handlerutils.NewForbiddenProblem(fmt.Sprintf("scope '%v' is required", scope))
+
+				handlerutils.WriteProblemAndLog(rw, req, logger, problem, "")
+
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
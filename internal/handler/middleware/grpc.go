@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/audit"
+	"pg-start-trainee-2024/internal/pkg/auth"
+)
+
+// metadata keys are always lower case
+const requestIDMetadata = "x-request-id"
+
+// GRPCContextFunc prepares context of gRPC call, returned error (status) rejects the call
+type GRPCContextFunc = func(ctx context.Context) (context.Context, error)
+
+// UnaryInterceptor applies fns to context of unary calls in given order
+func UnaryInterceptor(fns ...GRPCContextFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := applyContextFuncs(ctx, fns)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor applies fns to context of streaming calls in given order
+func StreamInterceptor(fns ...GRPCContextFunc) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := applyContextFuncs(stream.Context(), fns)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func applyContextFuncs(ctx context.Context, fns []GRPCContextFunc) (context.Context, error) {
+	var err error
+
+	for _, fn := range fns {
+		if ctx, err = fn(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx, nil
+}
+
+// contextStream is server stream with replaced context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// GRPCRequestMeta stores request ID passed in x-request-id metadata and client IP in context for audit events
+func GRPCRequestMeta(ctx context.Context) (context.Context, error) {
+	var meta entity.RequestMeta
+
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) != 0 {
+		meta.RequestID = values[0]
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			clientIP = p.Addr.String()
+		}
+
+		meta.ClientIP = clientIP
+	}
+
+	return audit.WithRequestMeta(ctx, meta), nil
+}
+
+// credentialsFromMetadata returns api key or bearer token of call as credentialsFromRequest does for requests
+func credentialsFromMetadata(ctx context.Context) (credentials string, isJWT bool) {
+	if values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(apiKeyHeader)); len(values) != 0 && values[0] != "" {
+		return values[0], false
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(authorizationHeader))
+	if len(values) != 0 && strings.HasPrefix(values[0], bearerPrefix) {
+		token := strings.TrimSpace(strings.TrimPrefix(values[0], bearerPrefix))
+
+		return token, strings.Count(token, ".") == 2
+	}
+
+	return "", false
+}
+
+// GRPCAuthenticate rejects calls without valid api key or JWT and stores authenticated principal in context
+// as Authenticate does for requests, tokens may be nil if JWT authentication is disabled
+func GRPCAuthenticate(apiKeys, tokens Authenticator, logger *logrus.Logger) GRPCContextFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		credentials, isJWT := credentialsFromMetadata(ctx)
+		if credentials == "" {
+			return nil, status.Error(codes.Unauthenticated, "api key or token is required")
+		}
+
+		authenticator := apiKeys
+		if isJWT && tokens != nil {
+			authenticator = tokens
+		}
+
+		principal, err := authenticator.Authenticate(ctx, credentials)
+		if err != nil {
+			if isUnauthenticated(err) {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+
+			logger.WithContext(ctx).Errorf("error occurred authenticating call: %v", err)
+
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		return auth.WithPrincipal(ctx, principal), nil
+	}
+}
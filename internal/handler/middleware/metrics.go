@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	chimiddlewares "github.com/go-chi/chi/v5/middleware"
+
+	"pg-start-trainee-2024/internal/pkg/metrics"
+)
+
+// unmatchedRoute labels requests not matched by any route, so unknown paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// Metrics records number and duration of requests labeled with route pattern (not path, which contains IDs)
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		ww := chimiddlewares.NewWrapResponseWriter(rw, req.ProtoMajor)
+
+		next.ServeHTTP(ww, req)
+
+		route := unmatchedRoute
+		if routeCtx := chi.RouteContext(req.Context()); routeCtx != nil && routeCtx.RoutePattern() != "" {
+			route = routeCtx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		metrics.HTTPRequests.WithLabelValues(req.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(req.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	chimiddlewares "github.com/go-chi/chi/v5/middleware"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/audit"
+)
+
+// RequestMeta stores request ID (set by chi RequestID middleware) and client IP in request context
+// for audit events. Client IP is taken from connection, so it's proxy's address behind reverse proxy
+// unless chi RealIP middleware is used
+func RequestMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			clientIP = req.RemoteAddr
+		}
+
+		meta := entity.RequestMeta{
+			RequestID: chimiddlewares.GetReqID(req.Context()),
+			ClientIP:  clientIP,
+		}
+
+		next.ServeHTTP(rw, req.WithContext(audit.WithRequestMeta(req.Context(), meta)))
+	})
+}
@@ -0,0 +1,25 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type AuditFilter struct {
+	Actor      string     `json:"actor" validate:"omitempty,max=256"`
+	Actions    []string   `json:"action" validate:"omitempty,dive,oneof=script.create script.stop script.delete script.approve script.reject api_key.create api_key.revoke webhook.create webhook.delete"`
+	TargetType string     `json:"target_type" validate:"omitempty,oneof=script api_key webhook"`
+	TargetID   string     `json:"target_id" validate:"omitempty,max=64"`
+	Namespace  string     `json:"namespace" validate:"omitempty,max=63"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+}
+
+func (af *AuditFilter) Validate(valid *validator.Validate) error {
+	if err := valid.Struct(af); err != nil {
+		return err
+	}
+
+	return validateTimeRange("created_at", af.From, af.To)
+}
@@ -0,0 +1,80 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxBatchSize is maximal number of scripts batch operation is applied to
+const MaxBatchSize = 1000
+
+type BatchCreateScripts struct {
+	Scripts []CreateScript `json:"scripts" validate:"required,min=1,max=100,dive"`
+}
+
+func (bcs *BatchCreateScripts) Validate(valid *validator.Validate) error { return valid.Struct(bcs) }
+
+// BatchFilter selects scripts batch operation is applied to
+type BatchFilter struct {
+	ScriptFilter
+
+	// OlderThan matches scripts created earlier than the duration ago
+	OlderThan string `json:"older_than" example:"1h"`
+}
+
+// empty reports whether filter matches all scripts, batch operations don't accept such filters
+func (bf *BatchFilter) empty() bool {
+	return len(bf.Statuses) == 0 && bf.IsRunning == nil && bf.Command == "" && bf.ExitCode == nil && len(bf.Tags) == 0 &&
+		bf.CreatedFrom == nil && bf.CreatedTo == nil && bf.FinishedFrom == nil && bf.FinishedTo == nil && bf.OlderThan == ""
+}
+
+// CreatedBefore returns upper bound of creation time of matched scripts, it's the earliest one of created_to
+// and older_than
+func (bf *BatchFilter) CreatedBefore(now time.Time) *time.Time {
+	if bf.OlderThan == "" {
+		return bf.CreatedTo
+	}
+
+	// duration is checked by Validate
+	olderThan, _ := time.ParseDuration(bf.OlderThan)
+
+	before := now.Add(-olderThan)
+	if bf.CreatedTo != nil && bf.CreatedTo.Before(before) {
+		return bf.CreatedTo
+	}
+
+	return &before
+}
+
+func (bf *BatchFilter) Validate(valid *validator.Validate) error {
+	if bf.empty() {
+		return ErrEmptyFilter
+	}
+
+	if bf.OlderThan != "" {
+		if d, err := time.ParseDuration(bf.OlderThan); err != nil || d <= 0 {
+			return ErrInvalidDuration
+		}
+	}
+
+	return bf.ScriptFilter.Validate(valid)
+}
+
+// BatchScripts selects scripts either by IDs or by filter
+type BatchScripts struct {
+	IDs    []int        `json:"ids" example:"1,2,3" validate:"required_without=Filter,excluded_with=Filter,max=1000,dive,min=1"`
+	Filter *BatchFilter `json:"filter" validate:"required_without=IDs"`
+}
+
+func (bs *BatchScripts) Validate(valid *validator.Validate) error {
+	if err := valid.Struct(bs); err != nil {
+		return err
+	}
+
+	if bs.Filter != nil {
+		return bs.Filter.Validate(valid)
+	}
+
+	return nil
+}
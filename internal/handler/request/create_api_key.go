@@ -0,0 +1,18 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateAPIKey creates key in namespace, caller's namespace is used if it's omitted. Caller which may access every
+// namespace must set it, "*" grants admin key every namespace
+type CreateAPIKey struct {
+	Name      string     `json:"name" example:"ci runner" validate:"required,min=1,max=128"`
+	Namespace string     `json:"namespace" example:"team-a" validate:"omitempty,max=63,lowercase"`
+	Scopes    []string   `json:"scopes" example:"scripts:read,scripts:write" validate:"required,min=1,dive,oneof=scripts:read scripts:write admin"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2025-01-01T00:00:00Z"`
+}
+
+func (ck *CreateAPIKey) Validate(valid *validator.Validate) error { return valid.Struct(ck) }
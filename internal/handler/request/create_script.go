@@ -3,7 +3,18 @@ package request
 import "github.com/go-playground/validator/v10"
 
 type CreateScript struct {
-	Command string `json:"command" example:"ping google.com" validate:"required,min=1"`
+	Command string   `json:"command" example:"ping google.com" validate:"required,min=1"`
+	Tags    []string `json:"tags" example:"nightly,backup" validate:"omitempty,max=20,dive,min=1,max=64"`
+
+	Interpreter string            `json:"interpreter" example:"bash" validate:"omitempty,oneof=sh bash"`
+	Env         map[string]string `json:"env" validate:"omitempty,max=64,dive,keys,min=1,max=128,endkeys,max=4096"`
+	RunAs       string            `json:"run_as" example:"nobody" validate:"omitempty,max=32"`
+
+	// LabelSelector holds labels worker running script must have, label without value is matched by empty value
+	LabelSelector map[string]string `json:"label_selector" example:"region:eu" validate:"omitempty,max=16,dive,keys,min=1,max=63,endkeys,max=63"`
+
+	// CallbackURL receives signed events of script
+	CallbackURL string `json:"callback_url" example:"https://ci.example.com/hooks/pg-start" validate:"omitempty,http_url,max=2048"`
 }
 
 func (cs *CreateScript) Validate(valid *validator.Validate) error { return valid.Struct(cs) }
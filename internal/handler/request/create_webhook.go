@@ -0,0 +1,14 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+// CreateWebhook subscribes url to events of scripts of namespace, caller's namespace is used if it's omitted.
+// Only callers not bound to namespace may subscribe to scripts of all namespaces
+type CreateWebhook struct {
+	Name      string   `json:"name" example:"ci" validate:"required,min=1,max=128"`
+	URL       string   `json:"url" example:"https://ci.example.com/hooks/pg-start" validate:"required,http_url,max=2048"`
+	Namespace string   `json:"namespace" example:"team-a" validate:"omitempty,max=63,lowercase"`
+	Events    []string `json:"events" example:"script.finished,script.failed" validate:"required,min=1,dive,oneof=script.started script.finished script.failed script.stopped"`
+}
+
+func (cw *CreateWebhook) Validate(valid *validator.Validate) error { return valid.Struct(cw) }
@@ -0,0 +1,9 @@
+package request
+
+import "errors"
+
+var (
+	ErrInvalidTimeRange = errors.New("lower bound of time range is after its upper bound")
+	ErrInvalidDuration  = errors.New("duration must be positive, e.g. 30m or 1h")
+	ErrEmptyFilter      = errors.New("filter must have at least one condition")
+)
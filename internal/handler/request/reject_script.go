@@ -0,0 +1,9 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type RejectScript struct {
+	Reason string `json:"reason" example:"not during release freeze" validate:"required,min=1,max=1024"`
+}
+
+func (rs *RejectScript) Validate(valid *validator.Validate) error { return valid.Struct(rs) }
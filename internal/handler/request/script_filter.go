@@ -0,0 +1,44 @@
+package request
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ScriptFilter struct {
+	Statuses  []string `json:"status" validate:"omitempty,dive,oneof=running finished failed stopped pending_approval rejected expired queued lost"`
+	IsRunning *bool    `json:"is_running"`
+	Command   string   `json:"command" validate:"omitempty,max=1024"`
+	ExitCode  *int     `json:"exit_code"`
+	Tags      []string `json:"tags" validate:"omitempty,dive,min=1,max=64"`
+
+	CreatedFrom  *time.Time `json:"created_from"`
+	CreatedTo    *time.Time `json:"created_to"`
+	FinishedFrom *time.Time `json:"finished_from"`
+	FinishedTo   *time.Time `json:"finished_to"`
+
+	Sort  string `json:"sort" validate:"omitempty,oneof=id command exit_code created_at finished_at"`
+	Order string `json:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+func validateTimeRange(name string, from, to *time.Time) error {
+	if from != nil && to != nil && from.After(*to) {
+		return fmt.Errorf("%v: %w", name, ErrInvalidTimeRange)
+	}
+
+	return nil
+}
+
+func (sf *ScriptFilter) Validate(valid *validator.Validate) error {
+	if err := valid.Struct(sf); err != nil {
+		return err
+	}
+
+	if err := validateTimeRange("created_at", sf.CreatedFrom, sf.CreatedTo); err != nil {
+		return err
+	}
+
+	return validateTimeRange("finished_at", sf.FinishedFrom, sf.FinishedTo)
+}
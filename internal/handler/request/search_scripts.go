@@ -0,0 +1,16 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type SearchScripts struct {
+	Query string     `json:"q" example:"connection refused" validate:"required,min=1"`
+	Mode  string     `json:"mode" example:"fts" validate:"omitempty,oneof=fts regex"`
+	From  *time.Time `json:"from"`
+	To    *time.Time `json:"to"`
+}
+
+func (ss *SearchScripts) Validate(valid *validator.Validate) error { return valid.Struct(ss) }
@@ -0,0 +1,12 @@
+package request
+
+import "github.com/go-playground/validator/v10"
+
+type WebhookDeliveryFilter struct {
+	WebhookID *int   `json:"webhook_id" validate:"omitempty,min=1"`
+	ScriptID  *int   `json:"script_id" validate:"omitempty,min=1"`
+	Namespace string `json:"namespace" validate:"omitempty,max=63"`
+	Status    string `json:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
+
+func (wf *WebhookDeliveryFilter) Validate(valid *validator.Validate) error { return valid.Struct(wf) }
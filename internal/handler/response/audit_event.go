@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type AuditEvent struct {
+	ID          int       `json:"id"`
+	Actor       *string   `json:"actor"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"target_type"`
+	TargetID    string    `json:"target_id"`
+	Namespace   string    `json:"namespace"`
+	RequestID   *string   `json:"request_id"`
+	ClientIP    *string   `json:"client_ip"`
+	PayloadHash *string   `json:"payload_hash"`
+	CreatedAt   time.Time `json:"created_at"`
+}
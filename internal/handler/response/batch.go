@@ -0,0 +1,21 @@
+package response
+
+import "pg-start-trainee-2024/pkg/utils/handler"
+
+// BatchResult reports result of every item of batch, items are in order of request
+type BatchResult struct {
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+	Items     []BatchItem `json:"items"`
+}
+
+type BatchItem struct {
+	// Index of item in request, for batches selected by filter it's index of matched script
+	Index int `json:"index"`
+	// ID of script, it's absent if script is not created
+	ID *int `json:"id,omitempty"`
+	// Status of created script
+	Status string `json:"status,omitempty"`
+	// Error is problem of failed item
+	Error *handler.Problem `json:"error,omitempty"`
+}
@@ -1,7 +1,9 @@
 package response
 
 type CreateScript struct {
-	ID      int    `json:"id"`
-	Command string `json:"command"`
-	PID     int    `json:"pid"`
+	ID      int      `json:"id"`
+	Command string   `json:"command"`
+	PID     int      `json:"pid"`
+	Status  string   `json:"status"`
+	Tags    []string `json:"tags"`
 }
@@ -3,11 +3,29 @@ package response
 import "time"
 
 type GetScript struct {
-	ID        int       `db:"id"`
-	Command   string    `db:"command"`
-	Output    string    `db:"output"`
-	IsRunning bool      `db:"is_running"`
-	PID       int       `db:"pid"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID                int               `db:"id"`
+	Namespace         string            `db:"namespace"`
+	Command           string            `db:"command"`
+	Output            string            `db:"output"`
+	IsRunning         bool              `db:"is_running"`
+	PID               int               `db:"pid"`
+	Status            string            `db:"status"`
+	ExitCode          *int              `db:"exit_code"`
+	Tags              []string          `db:"tags"`
+	APIKeyID          *int              `db:"api_key_id"`
+	CreatedBy         *string           `db:"created_by"`
+	Interpreter       string            `db:"interpreter"`
+	RunAs             string            `db:"run_as"`
+	PolicyRule        *string           `db:"policy_rule"`
+	ReviewedBy        *string           `db:"reviewed_by"`
+	ReviewedAt        *time.Time        `db:"reviewed_at"`
+	RejectReason      *string           `db:"reject_reason"`
+	ApprovalExpiresAt *time.Time        `db:"approval_expires_at"`
+	CallbackURL       *string           `db:"callback_url"`
+	LabelSelector     map[string]string `db:"label_selector"`
+	WorkerID          *string           `db:"worker_id"`
+	StopRequestedAt   *time.Time        `db:"stop_requested_at"`
+	CreatedAt         time.Time         `db:"created_at"`
+	UpdatedAt         time.Time         `db:"updated_at"`
+	FinishedAt        *time.Time        `db:"finished_at"`
 }
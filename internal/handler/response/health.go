@@ -0,0 +1,13 @@
+package response
+
+type HealthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Error      *string `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
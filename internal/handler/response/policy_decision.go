@@ -0,0 +1,6 @@
+package response
+
+type PolicyDecision struct {
+	Action string `json:"action" example:"deny"`
+	Rule   string `json:"rule,omitempty" example:"deny-rm-root"`
+}
@@ -0,0 +1,7 @@
+package response
+
+type ScriptsPage struct {
+	Items      []GetScript `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
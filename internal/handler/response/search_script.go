@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type OutputMatch struct {
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"`
+}
+
+type SearchScript struct {
+	ID        int           `json:"id"`
+	Command   string        `json:"command"`
+	IsRunning bool          `json:"is_running"`
+	CreatedAt time.Time     `json:"created_at"`
+	Matches   []OutputMatch `json:"matches"`
+}
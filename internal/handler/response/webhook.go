@@ -0,0 +1,35 @@
+package response
+
+import "time"
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Namespace string    `json:"namespace"`
+	Events    []string  `json:"events"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateWebhook contains secret payloads are signed with, it's returned only once
+type CreateWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	WebhookID      *int       `json:"webhook_id"`
+	ScriptID       int        `json:"script_id"`
+	Event          string     `json:"event"`
+	URL            string     `json:"url"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status"`
+	LastError      *string    `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+
+	scriptv1 "pg-start-trainee-2024/pkg/pb/script/v1"
+)
+
+func mapCreateScriptRequest(createRequest *scriptv1.CreateScriptRequest) request.CreateScript {
+	return request.CreateScript{
+		Command:       createRequest.GetCommand(),
+		Tags:          createRequest.GetTags(),
+		Interpreter:   createRequest.GetInterpreter(),
+		Env:           createRequest.GetEnv(),
+		RunAs:         createRequest.GetRunAs(),
+		LabelSelector: createRequest.GetLabelSelector(),
+		CallbackURL:   createRequest.GetCallbackUrl(),
+	}
+}
+
+func mapListScriptsRequest(listRequest *scriptv1.ListScriptsRequest) request.ScriptFilter {
+	return request.ScriptFilter{
+		Statuses:  listRequest.GetStatuses(),
+		IsRunning: listRequest.IsRunning,
+		Command:   listRequest.GetCommand(),
+		Tags:      listRequest.GetTags(),
+	}
+}
+
+func mapScript(script *entity.Script) *scriptv1.Script {
+	mapped := &scriptv1.Script{
+		Id:            int64(script.ID),
+		Namespace:     script.Namespace,
+		Command:       script.Command,
+		Output:        script.Output,
+		IsRunning:     script.IsRunning,
+		Pid:           int64(script.PID),
+		Status:        string(script.Status),
+		Tags:          script.Tags,
+		Interpreter:   script.Interpreter,
+		RunAs:         script.RunAs,
+		LabelSelector: script.LabelSelector,
+		CreatedAt:     timestamppb.New(script.CreatedAt),
+	}
+
+	if script.ExitCode != nil {
+		exitCode := int32(*script.ExitCode)
+		mapped.ExitCode = &exitCode
+	}
+
+	if script.FinishedAt != nil {
+		mapped.FinishedAt = timestamppb.New(*script.FinishedAt)
+	}
+
+	return mapped
+}
+
+func mapScripts(scripts []*entity.Script) []*scriptv1.Script {
+	mapped := make([]*scriptv1.Script, 0, len(scripts))
+
+	for _, script := range scripts {
+		mapped = append(mapped, mapScript(script))
+	}
+
+	return mapped
+}
@@ -0,0 +1,135 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+
+	scriptv1 "pg-start-trainee-2024/pkg/pb/script/v1"
+)
+
+type Service interface {
+	CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error)
+	GetScript(ctx context.Context, id int) (*entity.Script, error)
+	GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error)
+	StopScript(ctx context.Context, id int) error
+	DeleteScript(ctx context.Context, id int) error
+	WatchOutput(ctx context.Context, id int, send func(output string) error) error
+}
+
+// Server serves scripts over gRPC, requests are validated with the same rules REST requests are
+type Server struct {
+	scriptv1.UnimplementedScriptServiceServer
+
+	Service Service
+
+	logger       *logrus.Logger
+	validator    *validator.Validate
+	defaultLimit int
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, defaultLimit int) *Server {
+	return &Server{
+		Service:      service,
+		logger:       logger,
+		validator:    validator,
+		defaultLimit: defaultLimit,
+	}
+}
+
+// Register registers server on gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	scriptv1.RegisterScriptServiceServer(registrar, s)
+}
+
+func (s *Server) CreateScript(ctx context.Context, req *scriptv1.CreateScriptRequest) (*scriptv1.Script, error) {
+	scriptReq := mapCreateScriptRequest(req)
+
+	if err := scriptReq.Validate(s.validator); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid CreateScript request: %v", err)
+	}
+
+	created, err := s.Service.CreateScript(ctx, mapper.MapCreateScriptRequestToEntity(&scriptReq))
+	if err != nil {
+		return nil, s.errorAndLog(ctx, err, fmt.Sprintf("error occurred creating script: %v", err))
+	}
+
+	return mapScript(created), nil
+}
+
+func (s *Server) GetScript(ctx context.Context, req *scriptv1.GetScriptRequest) (*scriptv1.Script, error) {
+	script, err := s.Service.GetScript(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, s.errorAndLog(ctx, err, fmt.Sprintf("error occurred getting script: %v", err))
+	}
+
+	return mapScript(script), nil
+}
+
+func (s *Server) ListScripts(ctx context.Context, req *scriptv1.ListScriptsRequest) (*scriptv1.ListScriptsResponse, error) {
+	filterReq := mapListScriptsRequest(req)
+
+	if err := filterReq.Validate(s.validator); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ListScripts request: %v", err)
+	}
+
+	paginationOpts := request.PaginationOptions{Offset: int(req.GetOffset()), Limit: int(req.GetLimit())}
+	if paginationOpts.Limit == 0 {
+		paginationOpts.Limit = s.defaultLimit
+	}
+
+	if err := paginationOpts.Validate(s.validator); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pagination: %v", err)
+	}
+
+	scripts, err := s.Service.GetAllScripts(ctx, mapper.MapScriptFilterRequestToEntity(&filterReq), paginationOpts.Offset, paginationOpts.Limit)
+	if err != nil {
+		return nil, s.errorAndLog(ctx, err, fmt.Sprintf("error occurred listing scripts: %v", err))
+	}
+
+	return &scriptv1.ListScriptsResponse{Scripts: mapScripts(scripts)}, nil
+}
+
+func (s *Server) StopScript(ctx context.Context, req *scriptv1.StopScriptRequest) (*scriptv1.StopScriptResponse, error) {
+	if err := s.Service.StopScript(ctx, int(req.GetId())); err != nil {
+		return nil, s.errorAndLog(ctx, err, fmt.Sprintf("error occurred stopping script: %v", err))
+	}
+
+	return &scriptv1.StopScriptResponse{}, nil
+}
+
+func (s *Server) DeleteScript(ctx context.Context, req *scriptv1.DeleteScriptRequest) (*scriptv1.DeleteScriptResponse, error) {
+	if err := s.Service.DeleteScript(ctx, int(req.GetId())); err != nil {
+		return nil, s.errorAndLog(ctx, err, fmt.Sprintf("error occurred deleting script: %v", err))
+	}
+
+	return &scriptv1.DeleteScriptResponse{}, nil
+}
+
+// WatchOutput streams output of script until it finishes, output appended in between polls is sent as one chunk
+func (s *Server) WatchOutput(req *scriptv1.WatchOutputRequest, stream scriptv1.ScriptService_WatchOutputServer) error {
+	ctx := stream.Context()
+
+	err := s.Service.WatchOutput(ctx, int(req.GetId()), func(output string) error {
+		return stream.Send(&scriptv1.OutputChunk{Data: output})
+	})
+	if err != nil {
+		// client has gone, nothing to report
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+
+		return s.errorAndLog(ctx, err, fmt.Sprintf("error occurred watching script's output: %v", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+)
+
+// statusFromError converts error of script service to status with the same meaning problems of REST API have
+func statusFromError(err error) *status.Status {
+	switch {
+	case errors.Is(err, scriptservice.ErrNoSuchScript):
+		return status.New(codes.NotFound, "script not found")
+
+	case errors.Is(err, scriptservice.ErrForbidden),
+		errors.Is(err, scriptservice.ErrSelfApproval),
+		errors.Is(err, scriptservice.ErrCommandDenied):
+		return status.New(codes.PermissionDenied, err.Error())
+
+	case errors.Is(err, scriptservice.ErrQuotaExceeded):
+		return status.New(codes.ResourceExhausted, err.Error())
+
+	case errors.Is(err, scriptservice.ErrNoSuchRunningScript):
+		return status.New(codes.FailedPrecondition, "script is not running")
+
+	case errors.Is(err, scriptservice.ErrUnschedulable),
+		errors.Is(err, scriptservice.ErrNotPendingApproval),
+		errors.Is(err, scriptservice.ErrApprovalExpired):
+		return status.New(codes.FailedPrecondition, err.Error())
+
+	case errors.Is(err, scriptservice.ErrInvalidSearchQuery),
+		errors.Is(err, scriptservice.ErrInvalidSearchMode),
+		errors.Is(err, scriptservice.ErrSearchTimeout),
+		errors.Is(err, scriptservice.ErrUnsupportedCursorSort),
+		errors.Is(err, scriptservice.ErrUnknownInterpreter),
+		errors.Is(err, scriptservice.ErrInvalidEnv),
+		errors.Is(err, scriptservice.ErrInvalidLabel),
+		errors.Is(err, scriptservice.ErrInvalidCallbackURL):
+		return status.New(codes.InvalidArgument, err.Error())
+
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+
+	default:
+		return status.New(codes.Internal, "internal error")
+	}
+}
+
+// errorAndLog logs logMsg and returns status error of err, so details of internal errors are only logged
+func (s *Server) errorAndLog(ctx context.Context, err error, logMsg string) error {
+	s.logger.WithContext(ctx).Error(logMsg)
+
+	return statusFromError(err).Err()
+}
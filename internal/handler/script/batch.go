@@ -0,0 +1,193 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+// scriptIDs returns IDs of scripts selected by request, filter may select at most request.MaxBatchSize scripts
+func (h *Handler) scriptIDs(ctx context.Context, batchReq *request.BatchScripts) ([]int, *handlerutils.Problem, error) {
+	if batchReq.Filter == nil {
+		return batchReq.IDs, nil, nil
+	}
+
+	filter := mapper.MapScriptFilterRequestToEntity(&batchReq.Filter.ScriptFilter)
+	filter.CreatedTo = batchReq.Filter.CreatedBefore(time.Now())
+
+	scripts, err := h.Service.GetAllScripts(ctx, filter, 0, request.MaxBatchSize+1)
+	if err != nil {
+		return nil, problemFromError(err), fmt.Errorf("error occurred fetching scripts matching filter: %w", err)
+	}
+
+	if len(scripts) > request.MaxBatchSize {
+		err = fmt.Errorf("filter matches more than %v scripts", request.MaxBatchSize)
+
+		return nil, handlerutils.NewBadRequestProblem(err.Error()), err
+	}
+
+	return sliceutils.Map(scripts, func(script *entity.Script) int { return script.ID }), nil, nil
+}
+
+// decodeBatchScripts decodes and validates request selecting scripts and returns their IDs,
+// problem is written if request is invalid
+func (h *Handler) decodeBatchScripts(rw http.ResponseWriter, req *http.Request) ([]int, bool) {
+	var batchReq request.BatchScripts
+
+	if err := render.DecodeJSON(req.Body, &batchReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to BatchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return nil, false
+	}
+
+	if err := batchReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating BatchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return nil, false
+	}
+
+	ids, problem, err := h.scriptIDs(req.Context(), &batchReq)
+	if err != nil {
+		h.writeProblem(rw, req, problem, err.Error())
+
+		return nil, false
+	}
+
+	return ids, true
+}
+
+// writeBatchResults writes results of batch with 200 status code if all items succeeded and 207 otherwise
+func (h *Handler) writeBatchResults(rw http.ResponseWriter, req *http.Request, results []entity.BatchResult) {
+	batch := mapper.MapBatchResultsToResponse(results, func(err error) *handlerutils.Problem {
+		problem := problemFromError(err)
+		if problem.Type == handlerutils.ProblemTypeInternal {
+			h.logger.WithContext(req.Context()).Errorf("error occurred processing item of batch: %v", err)
+		}
+
+		return problem
+	})
+
+	if batch.Failed > 0 {
+		render.Status(req, http.StatusMultiStatus)
+	}
+
+	render.JSON(rw, req, batch)
+}
+
+// CreateScriptsV2 godoc
+//
+//	@Summary		Create and run batch of scripts
+//	@Description	Create and run up to 100 scripts, every script is created on its own and result of every one is reported.
+//	@Description	207 is returned if some of scripts are not created. Request with Idempotency-Key header is processed once per key
+//	@Tags			Script v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string						false	"idempotency key"
+//	@Param			input			body		request.BatchCreateScripts	true	"batch create scripts schema"
+//	@Success		200				{object}	response.BatchResult
+//	@Success		207				{object}	response.BatchResult
+//	@Failure		400				{object}	handler.Problem
+//	@Failure		401				{object}	handler.Problem
+//	@Failure		409				{object}	handler.Problem
+//	@Failure		422				{object}	handler.Problem
+//	@Failure		500				{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/batch/create [post]
+func (h *Handler) CreateScriptsV2(rw http.ResponseWriter, req *http.Request) {
+	var batchReq request.BatchCreateScripts
+
+	if err := render.DecodeJSON(req.Body, &batchReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to BatchCreateScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err := batchReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating BatchCreateScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	scripts := make([]entity.Script, 0, len(batchReq.Scripts))
+	for i := range batchReq.Scripts {
+		scripts = append(scripts, mapper.MapCreateScriptRequestToEntity(&batchReq.Scripts[i]))
+	}
+
+	h.writeBatchResults(rw, req, h.Service.CreateScripts(req.Context(), scripts))
+}
+
+// StopScriptsV2 godoc
+//
+//	@Summary		Stop batch of scripts
+//	@Description	Stop scripts selected by IDs or by filter (up to 1000 scripts), result of every script is reported.
+//	@Description	Filter must have at least one condition, older_than matches scripts created earlier than the duration ago.
+//	@Description	207 is returned if some of scripts are not stopped
+//	@Tags			Script v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		request.BatchScripts	true	"batch scripts schema"
+//	@Success		200		{object}	response.BatchResult
+//	@Success		207		{object}	response.BatchResult
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/batch/stop [post]
+func (h *Handler) StopScriptsV2(rw http.ResponseWriter, req *http.Request) {
+	ids, ok := h.decodeBatchScripts(rw, req)
+	if !ok {
+		return
+	}
+
+	h.writeBatchResults(rw, req, h.Service.StopScripts(req.Context(), ids))
+}
+
+// DeleteScriptsV2 godoc
+//
+//	@Summary		Delete batch of scripts
+//	@Description	Delete scripts selected by IDs or by filter (up to 1000 scripts) in one transaction, scripts that don't exist
+//	@Description	or may not be deleted by caller are reported and skipped, 207 is returned if there are such ones.
+//	@Description	Filter must have at least one condition, older_than matches scripts created earlier than the duration ago
+//	@Tags			Script v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		request.BatchScripts	true	"batch scripts schema"
+//	@Success		200		{object}	response.BatchResult
+//	@Success		207		{object}	response.BatchResult
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/batch/delete [post]
+func (h *Handler) DeleteScriptsV2(rw http.ResponseWriter, req *http.Request) {
+	ids, ok := h.decodeBatchScripts(rw, req)
+	if !ok {
+		return
+	}
+
+	results, err := h.Service.DeleteScripts(req.Context(), ids)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred deleting scripts: %v", err))
+
+		return
+	}
+
+	h.writeBatchResults(rw, req, results)
+}
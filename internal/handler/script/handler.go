@@ -23,14 +23,23 @@ type Service interface {
 	CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error)
 	StopScript(ctx context.Context, id int) error
 	GetScript(ctx context.Context, id int) (*entity.Script, error)
-	GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error)
+	GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error)
+	GetScriptsPage(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) (*entity.ScriptsPage, error)
 	DeleteScript(ctx context.Context, id int) error
+	SearchScripts(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchResult, error)
+	EvaluatePolicy(ctx context.Context, script entity.Script) (*entity.PolicyDecision, error)
+	ApproveScript(ctx context.Context, id int) (*entity.Script, error)
+	RejectScript(ctx context.Context, id int, reason string) error
+	CreateScripts(ctx context.Context, scripts []entity.Script) []entity.BatchResult
+	StopScripts(ctx context.Context, ids []int) []entity.BatchResult
+	DeleteScripts(ctx context.Context, ids []int) ([]entity.BatchResult, error)
 }
 
 type Middleware = func(http.Handler) http.Handler
 
 type Handler struct {
 	Service     Service
+	Idempotency Idempotency
 	Middlewares []Middleware
 
 	logger        *logrus.Logger
@@ -39,9 +48,17 @@ type Handler struct {
 	defaultLimit  int
 }
 
-func New(service Service, logger *logrus.Logger, validator *validator.Validate, defaultOffset, defaultLimit int, middlewares ...Middleware) *Handler {
+func New(
+	service Service,
+	idempotency Idempotency,
+	logger *logrus.Logger,
+	validator *validator.Validate,
+	defaultOffset, defaultLimit int,
+	middlewares ...Middleware,
+) *Handler {
 	return &Handler{
 		Service:       service,
+		Idempotency:   idempotency,
 		Middlewares:   middlewares,
 		logger:        logger,
 		validator:     validator,
@@ -56,10 +73,11 @@ func (h *Handler) Routes() *chi.Mux {
 	router.Group(func(r chi.Router) {
 		r.Use(h.Middlewares...)
 
-		r.Post("/", h.CreateScript)
+		r.Post("/", h.idempotent(h.CreateScript))
 		r.Patch("/", h.StopScript)
 		r.Get("/", h.GetScript)
 		r.Get("/all", h.GetAllScripts)
+		r.Get("/search", h.SearchScripts)
 		r.Delete("/", h.DeleteScript)
 	})
 
@@ -69,15 +87,21 @@ func (h *Handler) Routes() *chi.Mux {
 // CreateScript godoc
 //
 //	@Summary		Create and run new script
-//	@Description	Create and run new script
+//	@Description	Create and run new script. Request with Idempotency-Key header is processed once per key:
+//	@Description	retries with the same key get the original response with Idempotent-Replayed header
 //	@Tags			Script
 //	@Accept			json
 //	@Produce		json
-//	@Param			input	body		request.CreateScript	true	"create script schema"
-//	@Success		200		{object}	response.CreateScript
-//	@Failure		401		{string}	Unauthorized
-//	@Failure		400		{string}	invalid		request
-//	@Failure		500		{string}	internal	error
+//	@Param			Idempotency-Key	header		string					false	"idempotency key"
+//	@Param			input			body		request.CreateScript	true	"create script schema"
+//	@Success		200				{object}	response.CreateScript
+//	@Failure		400				{object}	handler.Problem
+//	@Failure		401				{object}	handler.Problem
+//	@Failure		403				{object}	handler.Problem
+//	@Failure		409				{object}	handler.Problem
+//	@Failure		422				{object}	handler.Problem
+//	@Failure		429				{object}	handler.Problem
+//	@Failure		500				{object}	handler.Problem
 //	@Router			/pg-start-trainee/api/v1/script [post]
 func (h *Handler) CreateScript(rw http.ResponseWriter, req *http.Request) {
 	var scriptReq request.CreateScript
@@ -85,7 +109,7 @@ func (h *Handler) CreateScript(rw http.ResponseWriter, req *http.Request) {
 	if err := render.DecodeJSON(req.Body, &scriptReq); err != nil {
 		msg := fmt.Sprintf("error occurred decoding request body to CreateScript request: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
 
 		return
 	}
@@ -93,7 +117,7 @@ func (h *Handler) CreateScript(rw http.ResponseWriter, req *http.Request) {
 	if err := scriptReq.Validate(h.validator); err != nil {
 		msg := fmt.Sprintf("error occurred validating CreateScript request: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
 
 		return
 	}
@@ -102,7 +126,7 @@ func (h *Handler) CreateScript(rw http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		msg := fmt.Sprintf("error occurred creating script: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, problemFromError(err), msg)
 		return
 	}
 
@@ -119,23 +143,26 @@ func (h *Handler) CreateScript(rw http.ResponseWriter, req *http.Request) {
 //	@Produce		json
 //	@Param			id	header	int	true	"script ID"
 //	@Success		200
-//	@Failure		401	{string}	Unauthorized
-//	@Failure		400	{string}	invalid		request
-//	@Failure		500	{string}	internal	error
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		409	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
 //	@Router			/pg-start-trainee/api/v1/script [patch]
 func (h *Handler) StopScript(rw http.ResponseWriter, req *http.Request) {
 	id, err := handlerutils.GetIntHeaderByKey(req, "id")
 	if err != nil {
 		msg := fmt.Sprintf("no id header provided: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, idHeaderProblem(err), msg)
 		return
 	}
 
 	if err = h.Service.StopScript(req.Context(), id); err != nil {
 		msg := fmt.Sprintf("error occurred stopping script: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, problemFromError(err), msg)
 		return
 	}
 
@@ -143,7 +170,7 @@ func (h *Handler) StopScript(rw http.ResponseWriter, req *http.Request) {
 
 	_, err = rw.Write([]byte("script successfully stopped."))
 	if err != nil {
-		h.logger.Errorf("error occurred writing response: %v", err)
+		h.logger.WithContext(req.Context()).Errorf("error occurred writing response: %v", err)
 	}
 }
 
@@ -156,16 +183,18 @@ func (h *Handler) StopScript(rw http.ResponseWriter, req *http.Request) {
 //	@Produce		json
 //	@Param			id	header		int	true	"script ID"
 //	@Success		200	{object}	response.GetScript
-//	@Failure		401	{string}	Unauthorized
-//	@Failure		400	{string}	invalid		request
-//	@Failure		500	{string}	internal	error
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
 //	@Router			/pg-start-trainee/api/v1/script [get]
 func (h *Handler) GetScript(rw http.ResponseWriter, req *http.Request) {
 	id, err := handlerutils.GetIntHeaderByKey(req, "id")
 	if err != nil {
 		msg := fmt.Sprintf("no id header provided: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, idHeaderProblem(err), msg)
 		return
 	}
 
@@ -173,7 +202,7 @@ func (h *Handler) GetScript(rw http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		msg := fmt.Sprintf("error occurred fetching script: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, problemFromError(err), msg)
 		return
 	}
 
@@ -184,15 +213,31 @@ func (h *Handler) GetScript(rw http.ResponseWriter, req *http.Request) {
 // GetAllScripts godoc
 //
 //	@Summary		Get all scripts
-//	@Description	Get all scripts
+//	@Description	Get all scripts matching filter, by default ordered by creation time.
+//	@Description	If cursor param is provided (empty value means the first page) keyset pagination is used instead of offset:
+//	@Description	response is wrapped in response.ScriptsPage with next_cursor and prev_cursor, only sorting by created_at is allowed
 //	@Tags			Script
 //	@Accept			json
 //	@Produce		json
-//	@Param			offset	query		int	false	"Offset"
-//	@Param			limit	query		int	false	"Limit"
-//	@Success		200		{object}	[]response.GetScript
-//	@Failure		400		{string}	invalid		request
-//	@Failure		500		{string}	internal	error
+//	@Param			offset			query		int			false	"Offset"
+//	@Param			limit			query		int			false	"Limit"
+//	@Param			cursor			query		string		false	"Cursor"
+//	@Param			status			query		[]string	false	"Statuses"	collectionFormat(csv)	Enums(running, finished, failed, stopped, pending_approval, rejected, expired, queued, lost)
+//	@Param			is_running		query		bool		false	"Is script running"
+//	@Param			command			query		string		false	"Command substring"
+//	@Param			exit_code		query		int			false	"Exit code"
+//	@Param			tags			query		[]string	false	"Tags script must have"	collectionFormat(csv)
+//	@Param			created_from	query		string		false	"Created at lower bound (RFC3339)"
+//	@Param			created_to		query		string		false	"Created at upper bound (RFC3339)"
+//	@Param			finished_from	query		string		false	"Finished at lower bound (RFC3339)"
+//	@Param			finished_to		query		string		false	"Finished at upper bound (RFC3339)"
+//	@Param			sort			query		string		false	"Sort field"		Enums(id, command, exit_code, created_at, finished_at)
+//	@Param			order			query		string		false	"Sort direction"	Enums(asc, desc)
+//	@Success		200				{object}	[]response.GetScript
+//	@Failure		400				{object}	handler.Problem
+//	@Failure		401				{object}	handler.Problem
+//	@Failure		403				{object}	handler.Problem
+//	@Failure		500				{object}	handler.Problem
 //	@Router			/pg-start-trainee/api/v1/script/all [get]
 func (h *Handler) GetAllScripts(rw http.ResponseWriter, req *http.Request) {
 	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
@@ -200,16 +245,44 @@ func (h *Handler) GetAllScripts(rw http.ResponseWriter, req *http.Request) {
 	if err := paginationOpts.Validate(h.validator); err != nil {
 		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
 
 		return
 	}
 
-	scripts, err := h.Service.GetAllScripts(req.Context(), paginationOpts.Offset, paginationOpts.Limit)
+	filterReq, err := handlerinternalutils.GetScriptFilterFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing ScriptFilter request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	if err = filterReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid filter provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	if req.URL.Query().Has("cursor") {
+		h.getScriptsPage(rw, req, mapper.MapScriptFilterRequestToEntity(&filterReq), paginationOpts.Limit)
+
+		return
+	}
+
+	scripts, err := h.Service.GetAllScripts(
+		req.Context(),
+		mapper.MapScriptFilterRequestToEntity(&filterReq),
+		paginationOpts.Offset,
+		paginationOpts.Limit,
+	)
 	if err != nil {
 		msg := fmt.Sprintf("error occurred fetching scripts: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, problemFromError(err), msg)
 		return
 	}
 
@@ -217,6 +290,93 @@ func (h *Handler) GetAllScripts(rw http.ResponseWriter, req *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 }
 
+func (h *Handler) getScriptsPage(rw http.ResponseWriter, req *http.Request, filter entity.ScriptFilter, limit int) {
+	cursor, err := handlerinternalutils.DecodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred decoding cursor: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	page, err := h.Service.GetScriptsPage(req.Context(), filter, cursor, limit)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred fetching scripts page: %v", err)
+
+		h.writeProblem(rw, req, problemFromError(err), msg)
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapScriptsPageToResponse(page, handlerinternalutils.EncodeCursor))
+	rw.WriteHeader(http.StatusOK)
+}
+
+// SearchScripts godoc
+//
+//	@Summary		Search scripts output
+//	@Description	Search through output of all scripts with full-text search (default) or regexp,
+//	@Description	matched lines of output are returned with their numbers and highlighted snippets
+//	@Tags			Script
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string	true	"Search query"
+//	@Param			mode	query		string	false	"Search mode"	Enums(fts, regex)
+//	@Param			from	query		string	false	"Created at lower bound (RFC3339)"
+//	@Param			to		query		string	false	"Created at upper bound (RFC3339)"
+//	@Param			offset	query		int		false	"Offset"
+//	@Param			limit	query		int		false	"Limit"
+//	@Success		200		{object}	[]response.SearchScript
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v1/script/search [get]
+func (h *Handler) SearchScripts(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	searchReq, err := handlerinternalutils.GetSearchScriptsFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing SearchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	if err = searchReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating SearchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	results, err := h.Service.SearchScripts(
+		req.Context(),
+		mapper.MapSearchScriptsRequestToEntity(&searchReq),
+		paginationOpts.Offset,
+		paginationOpts.Limit,
+	)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred searching scripts: %v", err)
+
+		h.writeProblem(rw, req, problemFromError(err), msg)
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(results, mapper.MapScriptSearchResultToResponse))
+	rw.WriteHeader(http.StatusOK)
+}
+
 // DeleteScript godoc
 //
 //	@Summary		Delete script by ID
@@ -225,15 +385,18 @@ func (h *Handler) GetAllScripts(rw http.ResponseWriter, req *http.Request) {
 //	@Produce		json
 //	@Param			id	header	int	true	"script ID"
 //	@Success		200
-//	@Failure		400	{string}	invalid		request
-//	@Failure		500	{string}	internal	error
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
 //	@Router			/pg-start-trainee/api/v1/script [delete]
 func (h *Handler) DeleteScript(rw http.ResponseWriter, req *http.Request) {
 	id, err := handlerutils.GetIntHeaderByKey(req, "id")
 	if err != nil {
 		msg := fmt.Sprintf("no id header provided: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, idHeaderProblem(err), msg)
 		return
 	}
 
@@ -241,7 +404,7 @@ func (h *Handler) DeleteScript(rw http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		msg := fmt.Sprintf("error occurred deleting script: %v", err)
 
-		handlerutils.WriteErrResponseAndLog(rw, h.logger, http.StatusBadRequest, msg, msg)
+		h.writeProblem(rw, req, problemFromError(err), msg)
 		return
 	}
 
@@ -249,6 +412,6 @@ func (h *Handler) DeleteScript(rw http.ResponseWriter, req *http.Request) {
 
 	_, err = rw.Write([]byte("script successfully deleted."))
 	if err != nil {
-		h.logger.Errorf("error occurred writing response: %v", err)
+		h.logger.WithContext(req.Context()).Errorf("error occurred writing response: %v", err)
 	}
 }
@@ -0,0 +1,467 @@
+package script
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+
+	handlerinternalutils "pg-start-trainee-2024/internal/pkg/utils/handler"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+// RoutesV2 returns resource oriented routes where script is identified by path and errors are reported
+// with status codes matching their cause
+func (h *Handler) RoutesV2() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Post("/", h.idempotent(h.CreateScriptV2))
+		r.Get("/", h.GetScriptsV2)
+		r.Get("/search", h.SearchScriptsV2)
+		r.Post("/policy/evaluate", h.EvaluatePolicyV2)
+		r.Post("/batch/create", h.idempotent(h.CreateScriptsV2))
+		r.Post("/batch/stop", h.StopScriptsV2)
+		r.Post("/batch/delete", h.DeleteScriptsV2)
+		r.Get("/{id}", h.GetScriptV2)
+		r.Post("/{id}/stop", h.StopScriptV2)
+		r.Post("/{id}/approve", h.ApproveScriptV2)
+		r.Post("/{id}/reject", h.RejectScriptV2)
+		r.Delete("/{id}", h.DeleteScriptV2)
+	})
+
+	return router
+}
+
+// CreateScriptV2 godoc
+//
+//	@Summary		Create and run new script
+//	@Description	Create and run new script, location of created script is returned in Location header.
+//	@Description	Script policy requires approval for is stored without running and 202 is returned, 202 is returned as well
+//	@Description	if script is queued for workers. Request with Idempotency-Key header is processed once per key:
+//	@Description	retries with the same key get the original response with Idempotent-Replayed header
+//	@Tags			Script v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			Idempotency-Key	header		string					false	"idempotency key"
+//	@Param			input			body		request.CreateScript	true	"create script schema"
+//	@Success		201				{object}	response.CreateScript
+//	@Success		202				{object}	response.CreateScript
+//	@Failure		400				{object}	handler.Problem
+//	@Failure		401				{object}	handler.Problem
+//	@Failure		403				{object}	handler.Problem
+//	@Failure		409				{object}	handler.Problem
+//	@Failure		422				{object}	handler.Problem
+//	@Failure		429				{object}	handler.Problem
+//	@Failure		500				{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts [post]
+func (h *Handler) CreateScriptV2(rw http.ResponseWriter, req *http.Request) {
+	var scriptReq request.CreateScript
+
+	if err := render.DecodeJSON(req.Body, &scriptReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err := scriptReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	created, err := h.Service.CreateScript(req.Context(), mapper.MapCreateScriptRequestToEntity(&scriptReq))
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred creating script: %v", err))
+
+		return
+	}
+
+	rw.Header().Set("Location", path.Join(req.URL.Path, strconv.Itoa(created.ID)))
+
+	if created.Status == entity.ScriptStatusPendingApproval || created.Status == entity.ScriptStatusQueued {
+		render.Status(req, http.StatusAccepted)
+	} else {
+		render.Status(req, http.StatusCreated)
+	}
+
+	render.JSON(rw, req, mapper.MapScriptToCreateScriptResponse(created))
+}
+
+// EvaluatePolicyV2 godoc
+//
+//	@Summary		Evaluate command policy
+//	@Description	Dry-run policy evaluation, returns decision that would be made on creation of the script
+//	@Tags			Script v2
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		request.CreateScript	true	"create script schema"
+//	@Success		200		{object}	response.PolicyDecision
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/policy/evaluate [post]
+func (h *Handler) EvaluatePolicyV2(rw http.ResponseWriter, req *http.Request) {
+	var scriptReq request.CreateScript
+
+	if err := render.DecodeJSON(req.Body, &scriptReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err := scriptReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	decision, err := h.Service.EvaluatePolicy(req.Context(), mapper.MapCreateScriptRequestToEntity(&scriptReq))
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred evaluating policy: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapPolicyDecisionToResponse(decision))
+}
+
+// GetScriptsV2 godoc
+//
+//	@Summary		Get scripts
+//	@Description	Get page of scripts matching filter, pages are fetched with cursor returned in previous page
+//	@Tags			Script v2
+//	@Produce		json
+//	@Param			cursor			query		string		false	"Cursor, the first page is returned if empty"
+//	@Param			limit			query		int			false	"Limit"
+//	@Param			status			query		[]string	false	"Statuses"	collectionFormat(csv)	Enums(running, finished, failed, stopped, pending_approval, rejected, expired, queued, lost)
+//	@Param			is_running		query		bool		false	"Is script running"
+//	@Param			command			query		string		false	"Command substring"
+//	@Param			exit_code		query		int			false	"Exit code"
+//	@Param			tags			query		[]string	false	"Tags script must have"	collectionFormat(csv)
+//	@Param			created_from	query		string		false	"Created at lower bound (RFC3339)"
+//	@Param			created_to		query		string		false	"Created at upper bound (RFC3339)"
+//	@Param			finished_from	query		string		false	"Finished at lower bound (RFC3339)"
+//	@Param			finished_to		query		string		false	"Finished at upper bound (RFC3339)"
+//	@Param			order			query		string		false	"Sort direction by created_at"	Enums(asc, desc)
+//	@Success		200				{object}	response.ScriptsPage
+//	@Failure		400				{object}	handler.Problem
+//	@Failure		401				{object}	handler.Problem
+//	@Failure		403				{object}	handler.Problem
+//	@Failure		500				{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts [get]
+func (h *Handler) GetScriptsV2(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	filterReq, err := handlerinternalutils.GetScriptFilterFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing ScriptFilter request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	if err = filterReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid filter provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	cursor, err := handlerinternalutils.DecodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		msg := fmt.Sprintf("error occurred decoding cursor: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	page, err := h.Service.GetScriptsPage(req.Context(), mapper.MapScriptFilterRequestToEntity(&filterReq), cursor, paginationOpts.Limit)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching scripts page: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapScriptsPageToResponse(page, handlerinternalutils.EncodeCursor))
+}
+
+// SearchScriptsV2 godoc
+//
+//	@Summary		Search scripts output
+//	@Description	Search through output of all scripts with full-text search (default) or regexp
+//	@Tags			Script v2
+//	@Produce		json
+//	@Param			q		query		string	true	"Search query"
+//	@Param			mode	query		string	false	"Search mode"	Enums(fts, regex)
+//	@Param			from	query		string	false	"Created at lower bound (RFC3339)"
+//	@Param			to		query		string	false	"Created at upper bound (RFC3339)"
+//	@Param			offset	query		int		false	"Offset"
+//	@Param			limit	query		int		false	"Limit"
+//	@Success		200		{object}	[]response.SearchScript
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/search [get]
+func (h *Handler) SearchScriptsV2(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	searchReq, err := handlerinternalutils.GetSearchScriptsFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing SearchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	if err = searchReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating SearchScripts request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	results, err := h.Service.SearchScripts(
+		req.Context(),
+		mapper.MapSearchScriptsRequestToEntity(&searchReq),
+		paginationOpts.Offset,
+		paginationOpts.Limit,
+	)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred searching scripts: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(results, mapper.MapScriptSearchResultToResponse))
+}
+
+// GetScriptV2 godoc
+//
+//	@Summary		Get script
+//	@Description	Get script by ID
+//	@Tags			Script v2
+//	@Produce		json
+//	@Param			id	path		int	true	"script ID"
+//	@Success		200	{object}	response.GetScript
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/{id} [get]
+func (h *Handler) GetScriptV2(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid script id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("script id must be an integer"), msg)
+
+		return
+	}
+
+	script, err := h.Service.GetScript(req.Context(), id)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching script: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapScriptToGetScriptResponse(script))
+}
+
+// StopScriptV2 godoc
+//
+//	@Summary		Stop running script
+//	@Description	Stop running script, script run by another replica or worker is stopped by it shortly after request, stopping script that is not running is a conflict
+//	@Tags			Script v2
+//	@Param			id	path	int	true	"script ID"
+//	@Success		204
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		409	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/{id}/stop [post]
+func (h *Handler) StopScriptV2(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid script id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("script id must be an integer"), msg)
+
+		return
+	}
+
+	if err = h.Service.StopScript(req.Context(), id); err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred stopping script: %v", err))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// ApproveScriptV2 godoc
+//
+//	@Summary		Approve script
+//	@Description	Approve script pending approval and run it (or queue it for workers), script can't be approved by its creator
+//	@Tags			Script v2
+//	@Produce		json
+//	@Param			id	path		int	true	"script ID"
+//	@Success		200	{object}	response.CreateScript
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		409	{object}	handler.Problem
+//	@Failure		422	{object}	handler.Problem
+//	@Failure		429	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/{id}/approve [post]
+func (h *Handler) ApproveScriptV2(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid script id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("script id must be an integer"), msg)
+
+		return
+	}
+
+	script, err := h.Service.ApproveScript(req.Context(), id)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred approving script: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, mapper.MapScriptToCreateScriptResponse(script))
+}
+
+// RejectScriptV2 godoc
+//
+//	@Summary		Reject script
+//	@Description	Reject script pending approval, creator may reject own script
+//	@Tags			Script v2
+//	@Accept			json
+//	@Param			id		path	int						true	"script ID"
+//	@Param			input	body	request.RejectScript	true	"reject script schema"
+//	@Success		204
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		409	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/{id}/reject [post]
+func (h *Handler) RejectScriptV2(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid script id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("script id must be an integer"), msg)
+
+		return
+	}
+
+	var rejectReq request.RejectScript
+
+	if err = render.DecodeJSON(req.Body, &rejectReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to RejectScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err = rejectReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating RejectScript request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	if err = h.Service.RejectScript(req.Context(), id, rejectReq.Reason); err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred rejecting script: %v", err))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteScriptV2 godoc
+//
+//	@Summary		Delete script
+//	@Description	Delete script by ID, running script is stopped
+//	@Tags			Script v2
+//	@Param			id	path	int	true	"script ID"
+//	@Success		204
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/scripts/{id} [delete]
+func (h *Handler) DeleteScriptV2(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid script id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("script id must be an integer"), msg)
+
+		return
+	}
+
+	if err = h.Service.DeleteScript(req.Context(), id); err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred deleting script: %v", err))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	chimiddlewares "github.com/go-chi/chi/v5/middleware"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+
+	idempotencyservice "pg-start-trainee-2024/internal/service/idempotency"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// replayedHeader marks response replayed to retry of request
+	replayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// storedHeaders are headers of response stored to be replayed along with it
+var storedHeaders = []string{"Content-Type", "Location"}
+
+type Idempotency interface {
+	Claim(ctx context.Context, key, requestHash string) (*entity.IdempotencyKey, error)
+	KeepLease(ctx context.Context, claimed entity.IdempotencyKey) func()
+	Complete(ctx context.Context, claimed entity.IdempotencyKey, scriptID *int, status int, headers map[string]string, body string) error
+	Release(ctx context.Context, claimed entity.IdempotencyKey) error
+}
+
+// requestHash identifies request by its route and body, so key can't be reused with another request
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+
+	_, _ = fmt.Fprintf(hash, "%v %v\n", req.Method, req.URL.Path)
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotent makes request with Idempotency-Key header processed once per key: successful response is stored and
+// replayed to retries of the request until key expires, failed request may be retried with the same key
+func (h *Handler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(rw, req)
+
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			msg := fmt.Sprintf("idempotency key is longer than %v characters", maxIdempotencyKeyLength)
+
+			h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(msg), msg)
+
+			return
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			msg := fmt.Sprintf("error occurred reading request body: %v", err)
+
+			h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("request body can't be read"), msg)
+
+			return
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		claimed, err := h.Idempotency.Claim(req.Context(), key, requestHash(req, body))
+
+		switch {
+		case errors.Is(err, idempotencyservice.ErrKeyReused):
+			h.writeProblem(rw, req, handlerutils.NewIdempotencyKeyProblem(err.Error()), fmt.Sprintf("error occurred claiming idempotency key: %v", err))
+
+			return
+
+		case errors.Is(err, idempotencyservice.ErrRequestInProgress):
+			h.writeProblem(rw, req, handlerutils.NewInProgressProblem(err.Error()), fmt.Sprintf("error occurred claiming idempotency key: %v", err))
+
+			return
+
+		case err != nil:
+			h.writeProblem(rw, req, handlerutils.NewInternalProblem(), fmt.Sprintf("error occurred claiming idempotency key: %v", err))
+
+			return
+
+		case claimed.Completed():
+			h.replay(rw, req, claimed)
+
+			return
+		}
+
+		// key is released or completed even if client is gone, as the request is processed anyway
+		ctx := context.WithoutCancel(req.Context())
+
+		// key is held while request is processed, however long it takes, lease is stopped before key is completed
+		// or released
+		stopLease := h.Idempotency.KeepLease(ctx, *claimed)
+
+		// panicked request is not completed, so key is released for it to be retried and panic goes on
+		defer func() {
+			if rvr := recover(); rvr != nil {
+				stopLease()
+				h.releaseIdempotencyKey(ctx, *claimed)
+
+				panic(rvr)
+			}
+		}()
+
+		var buf bytes.Buffer
+
+		ww := chimiddlewares.NewWrapResponseWriter(rw, req.ProtoMajor)
+		ww.Tee(&buf)
+
+		next(ww, req)
+
+		stopLease()
+
+		if ww.Status() < http.StatusOK || ww.Status() >= http.StatusMultipleChoices {
+			h.releaseIdempotencyKey(ctx, *claimed)
+
+			return
+		}
+
+		headers := make(map[string]string)
+
+		for _, name := range storedHeaders {
+			if value := ww.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+
+		var scriptID *int
+
+		var created response.CreateScript
+		if err = json.Unmarshal(buf.Bytes(), &created); err == nil && created.ID != 0 {
+			scriptID = &created.ID
+		}
+
+		// response is already sent, so key that failed to complete stays in progress until its lease expires
+		// and script is not created twice meanwhile
+		if err = h.Idempotency.Complete(ctx, *claimed, scriptID, ww.Status(), headers, buf.String()); err != nil {
+			h.logger.WithContext(ctx).Errorf("error occurred storing response of request with idempotency key: %v", err)
+		}
+	}
+}
+
+func (h *Handler) releaseIdempotencyKey(ctx context.Context, claimed entity.IdempotencyKey) {
+	if err := h.Idempotency.Release(ctx, claimed); err != nil {
+		h.logger.WithContext(ctx).Errorf("error occurred releasing idempotency key: %v", err)
+	}
+}
+
+// replay writes stored response to retry of request
+func (h *Handler) replay(rw http.ResponseWriter, req *http.Request, stored *entity.IdempotencyKey) {
+	for name, value := range stored.ResponseHeaders {
+		rw.Header().Set(name, value)
+	}
+
+	rw.Header().Set(replayedHeader, "true")
+	rw.WriteHeader(*stored.ResponseStatus)
+
+	if _, err := io.WriteString(rw, *stored.ResponseBody); err != nil {
+		h.logger.WithContext(req.Context()).Errorf("error occurred writing response: %v", err)
+	}
+}
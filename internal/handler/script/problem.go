@@ -0,0 +1,63 @@
+package script
+
+import (
+	"errors"
+	"net/http"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+// problemFromError maps errors returned by Service to problems, unknown errors are internal ones
+func problemFromError(err error) *handlerutils.Problem {
+	switch {
+	case errors.Is(err, scriptservice.ErrNoSuchScript):
+		return handlerutils.NewNotFoundProblem("script not found")
+
+	case errors.Is(err, scriptservice.ErrForbidden),
+		errors.Is(err, scriptservice.ErrSelfApproval):
+		return handlerutils.NewForbiddenProblem(err.Error())
+
+	case errors.Is(err, scriptservice.ErrCommandDenied):
+		return handlerutils.NewPolicyViolationProblem(err.Error())
+
+	case errors.Is(err, scriptservice.ErrQuotaExceeded):
+		return handlerutils.NewLimitExceededProblem(err.Error())
+
+	case errors.Is(err, scriptservice.ErrUnschedulable):
+		return handlerutils.NewUnschedulableProblem(err.Error())
+
+	case errors.Is(err, scriptservice.ErrNoSuchRunningScript):
+		return handlerutils.NewInvalidStateProblem("script is not running")
+
+	case errors.Is(err, scriptservice.ErrNotPendingApproval),
+		errors.Is(err, scriptservice.ErrApprovalExpired):
+		return handlerutils.NewInvalidStateProblem(err.Error())
+
+	case errors.Is(err, scriptservice.ErrInvalidSearchQuery),
+		errors.Is(err, scriptservice.ErrInvalidSearchMode),
+		errors.Is(err, scriptservice.ErrSearchTimeout),
+		errors.Is(err, scriptservice.ErrUnsupportedCursorSort),
+		errors.Is(err, scriptservice.ErrUnknownInterpreter),
+		errors.Is(err, scriptservice.ErrInvalidEnv),
+		errors.Is(err, scriptservice.ErrInvalidLabel),
+		errors.Is(err, scriptservice.ErrInvalidCallbackURL):
+		return handlerutils.NewBadRequestProblem(err.Error())
+
+	default:
+		return handlerutils.NewInternalProblem()
+	}
+}
+
+// idHeaderProblem is problem of missing or malformed id header of v1 routes
+func idHeaderProblem(err error) *handlerutils.Problem {
+	if errors.Is(err, handlerutils.ErrNoHeaderProvided) {
+		return handlerutils.NewBadRequestProblem("id header is required")
+	}
+
+	return handlerutils.NewBadRequestProblem("id header must be an integer")
+}
+
+func (h *Handler) writeProblem(rw http.ResponseWriter, req *http.Request, problem *handlerutils.Problem, logMsg string) {
+	handlerutils.WriteProblemAndLog(rw, req, h.logger, problem, logMsg)
+}
@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/mapper"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/pkg/auth"
+
+	handlerinternalutils "pg-start-trainee-2024/internal/pkg/utils/handler"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+type Service interface {
+	CreateWebhook(ctx context.Context, webhook entity.Webhook) (*entity.Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+type Middleware = func(http.Handler) http.Handler
+
+type Handler struct {
+	Service     Service
+	Middlewares []Middleware
+
+	logger        *logrus.Logger
+	validator     *validator.Validate
+	defaultOffset int
+	defaultLimit  int
+}
+
+func New(service Service, logger *logrus.Logger, validator *validator.Validate, defaultOffset, defaultLimit int, middlewares ...Middleware) *Handler {
+	return &Handler{
+		Service:       service,
+		Middlewares:   middlewares,
+		logger:        logger,
+		validator:     validator,
+		defaultOffset: defaultOffset,
+		defaultLimit:  defaultLimit,
+	}
+}
+
+func (h *Handler) Routes() *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Group(func(r chi.Router) {
+		r.Use(h.Middlewares...)
+
+		r.Post("/", h.CreateWebhook)
+		r.Get("/", h.GetAllWebhooks)
+		r.Get("/deliveries", h.GetWebhookDeliveries)
+		r.Delete("/{id}", h.DeleteWebhook)
+	})
+
+	return router
+}
+
+// problemFromError maps errors returned by Service to problems, unknown errors are internal ones
+func problemFromError(err error) *handlerutils.Problem {
+	switch {
+	case errors.Is(err, webhookservice.ErrNoSuchWebhook):
+		return handlerutils.NewNotFoundProblem("webhook not found")
+
+	case errors.Is(err, webhookservice.ErrURLNotAllowed):
+		return handlerutils.NewBadRequestProblem(err.Error())
+
+	case errors.Is(err, auth.ErrOtherNamespace):
+		return handlerutils.NewForbiddenProblem(err.Error())
+
+	default:
+		return handlerutils.NewInternalProblem()
+	}
+}
+
+func (h *Handler) writeProblem(rw http.ResponseWriter, req *http.Request, problem *handlerutils.Problem, logMsg string) {
+	handlerutils.WriteProblemAndLog(rw, req, h.logger, problem, logMsg)
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Create webhook
+//	@Description	Subscribe url to script lifecycle events, payloads are signed with HMAC-SHA256 of secret returned only in this response.
+//	@Description	Admin bound to namespace subscribes to scripts of own namespace only
+//	@Tags			Webhook
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		request.CreateWebhook	true	"create webhook schema"
+//	@Success		201		{object}	response.CreateWebhook
+//	@Failure		400		{object}	handler.Problem
+//	@Failure		401		{object}	handler.Problem
+//	@Failure		403		{object}	handler.Problem
+//	@Failure		500		{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/webhooks [post]
+func (h *Handler) CreateWebhook(rw http.ResponseWriter, req *http.Request) {
+	var webhookReq request.CreateWebhook
+
+	if err := render.DecodeJSON(req.Body, &webhookReq); err != nil {
+		msg := fmt.Sprintf("error occurred decoding request body to CreateWebhook request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewMalformedBodyProblem(), msg)
+
+		return
+	}
+
+	if err := webhookReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("error occurred validating CreateWebhook request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	created, err := h.Service.CreateWebhook(req.Context(), mapper.MapCreateWebhookRequestToEntity(&webhookReq))
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred creating webhook: %v", err))
+
+		return
+	}
+
+	render.Status(req, http.StatusCreated)
+	render.JSON(rw, req, mapper.MapWebhookToCreateWebhookResponse(created))
+}
+
+// GetAllWebhooks godoc
+//
+//	@Summary		Get webhooks
+//	@Description	Get all webhooks of caller's namespace, secrets are never returned
+//	@Tags			Webhook
+//	@Produce		json
+//	@Success		200	{object}	[]response.Webhook
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/webhooks [get]
+func (h *Handler) GetAllWebhooks(rw http.ResponseWriter, req *http.Request) {
+	webhooks, err := h.Service.GetAllWebhooks(req.Context())
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching webhooks: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(webhooks, mapper.MapWebhookToResponse))
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Delete webhook
+//	@Description	Delete webhook by ID together with its deliveries
+//	@Tags			Webhook
+//	@Param			id	path	int	true	"webhook ID"
+//	@Success		204
+//	@Failure		400	{object}	handler.Problem
+//	@Failure		401	{object}	handler.Problem
+//	@Failure		403	{object}	handler.Problem
+//	@Failure		404	{object}	handler.Problem
+//	@Failure		500	{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(rw http.ResponseWriter, req *http.Request) {
+	id, err := handlerutils.GetIntURLParam(req, "id")
+	if err != nil {
+		msg := fmt.Sprintf("invalid webhook id provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem("webhook id must be an integer"), msg)
+
+		return
+	}
+
+	if err = h.Service.DeleteWebhook(req.Context(), id); err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred deleting webhook: %v", err))
+
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+//
+//	@Summary		Get webhook deliveries
+//	@Description	Get deliveries of webhooks and callback urls of scripts matching filter, most recent first
+//	@Tags			Webhook
+//	@Produce		json
+//	@Param			webhook_id	query		int		false	"Webhook ID"
+//	@Param			script_id	query		int		false	"Script ID"
+//	@Param			status		query		string	false	"Delivery status"	Enums(pending, succeeded, failed)
+//	@Param			namespace	query		string	false	"Namespace of scripts, admin bound to namespace gets deliveries of own namespace only"
+//	@Param			offset		query		int		false	"Offset"
+//	@Param			limit		query		int		false	"Limit"
+//	@Success		200			{object}	[]response.WebhookDelivery
+//	@Failure		400			{object}	handler.Problem
+//	@Failure		401			{object}	handler.Problem
+//	@Failure		403			{object}	handler.Problem
+//	@Failure		500			{object}	handler.Problem
+//	@Router			/pg-start-trainee/api/v2/admin/webhooks/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(rw http.ResponseWriter, req *http.Request) {
+	paginationOpts := handlerinternalutils.GetPaginationOptsFromQuery(req, h.defaultOffset, h.defaultLimit)
+
+	if err := paginationOpts.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid pagination options provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	filterReq, err := handlerinternalutils.GetWebhookDeliveryFilterFromQuery(req)
+	if err != nil {
+		msg := fmt.Sprintf("error occurred parsing WebhookDeliveryFilter request: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewBadRequestProblem(err.Error()), msg)
+
+		return
+	}
+
+	if err = filterReq.Validate(h.validator); err != nil {
+		msg := fmt.Sprintf("invalid filter provided: %v", err)
+
+		h.writeProblem(rw, req, handlerutils.NewValidationFailedProblem(err), msg)
+
+		return
+	}
+
+	deliveries, err := h.Service.GetWebhookDeliveries(
+		req.Context(),
+		mapper.MapWebhookDeliveryFilterRequestToEntity(&filterReq),
+		paginationOpts.Offset,
+		paginationOpts.Limit,
+	)
+	if err != nil {
+		h.writeProblem(rw, req, problemFromError(err), fmt.Sprintf("error occurred fetching webhook deliveries: %v", err))
+
+		return
+	}
+
+	render.JSON(rw, req, sliceutils.Map(deliveries, mapper.MapWebhookDeliveryToResponse))
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+)
+
+type requestMetaKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta entity.RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext returns meta of request ctx belongs to, false is returned outside of requests
+func RequestMetaFromContext(ctx context.Context) (entity.RequestMeta, bool) {
+	meta, ok := ctx.Value(requestMetaKey{}).(entity.RequestMeta)
+
+	return meta, ok
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// NewEvent returns event of action performed by caller in request ctx belongs to on target of namespace,
+// payload is hashed as json, nil payload is not hashed at all
+func NewEvent(
+	ctx context.Context,
+	action entity.AuditAction,
+	targetType entity.AuditTargetType,
+	targetID, namespace string,
+	payload any,
+) (entity.AuditEvent, error) {
+	event := entity.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Namespace:  namespace,
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		event.Actor = &principal.Subject
+	}
+
+	if meta, ok := RequestMetaFromContext(ctx); ok {
+		event.RequestID = optional(meta.RequestID)
+		event.ClientIP = optional(meta.ClientIP)
+	}
+
+	if payload != nil {
+		buf, err := json.Marshal(payload)
+		if err != nil {
+			return entity.AuditEvent{}, err
+		}
+
+		hash := sha256.Sum256(buf)
+		event.PayloadHash = optional(hex.EncodeToString(hash[:]))
+	}
+
+	return event, nil
+}
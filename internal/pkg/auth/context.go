@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *entity.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns authenticated caller, false is returned for anonymous requests
+// (e.g. when authentication is disabled)
+func PrincipalFromContext(ctx context.Context) (*entity.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*entity.Principal)
+
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+// ErrOtherNamespace is returned when caller bound to namespace refers to another one
+var ErrOtherNamespace = errors.New("namespace of another tenant")
+
+// Namespace returns namespace caller is bound to, empty namespace is returned for callers which may access
+// every namespace: global admins and anonymous callers when authentication is disabled.
+// Principal without namespace is bound to default one, global scope is never implied
+func Namespace(ctx context.Context) string {
+	principal, ok := PrincipalFromContext(ctx)
+
+	switch {
+	case !ok || principal.IsGlobal():
+		return ""
+
+	case principal.Namespace == "":
+		return entity.DefaultNamespace
+
+	default:
+		return principal.Namespace
+	}
+}
+
+// ConfineNamespace returns namespace operation requested for namespace is performed in. Caller bound to namespace
+// gets its own namespace if namespace is empty and ErrOtherNamespace if it's another one, other callers get namespace as is
+func ConfineNamespace(ctx context.Context, namespace string) (string, error) {
+	own := Namespace(ctx)
+
+	switch {
+	case own == "" || namespace == own:
+		return namespace, nil
+
+	case namespace == "":
+		return own, nil
+
+	default:
+		return "", fmt.Errorf("%w: %v", ErrOtherNamespace, namespace)
+	}
+}
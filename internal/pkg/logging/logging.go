@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	chimiddlewares "github.com/go-chi/chi/v5/middleware"
+
+	"pg-start-trainee-2024/internal/pkg/auth"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// names of fields entries are logged with
+const (
+	FieldRequestID = "request_id"
+	FieldActor     = "actor"
+	FieldTraceID   = "trace_id"
+	FieldScriptID  = "script_id"
+	FieldPID       = "pid"
+	FieldWorkerID  = "worker_id"
+)
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+type Options struct {
+	// Level is one of logrus levels, e.g. debug, info, warn or error
+	Level string
+	// Format is json or text
+	Format string
+}
+
+// Configure sets level and format of logger and makes entries logged with context
+// (logger.WithContext(ctx)) include request ID, actor and trace ID of ctx
+func Configure(logger *logrus.Logger, opts Options) error {
+	level, err := logrus.ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case FormatText:
+		logger.SetFormatter(&logrus.TextFormatter{})
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownFormat, opts.Format)
+	}
+
+	logger.SetLevel(level)
+	logger.AddHook(contextHook{})
+
+	return nil
+}
+
+// contextHook adds fields of request entry's context belongs to
+type contextHook struct{}
+
+func (contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (contextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	if requestID := chimiddlewares.GetReqID(entry.Context); requestID != "" {
+		entry.Data[FieldRequestID] = requestID
+	}
+
+	if principal, ok := auth.PrincipalFromContext(entry.Context); ok {
+		entry.Data[FieldActor] = principal.Subject
+	}
+
+	if spanCtx := trace.SpanContextFromContext(entry.Context); spanCtx.HasTraceID() {
+		entry.Data[FieldTraceID] = spanCtx.TraceID().String()
+	}
+
+	return nil
+}
+
+// ScriptEntry returns entry of logger with context and id of script
+func ScriptEntry(ctx context.Context, logger *logrus.Logger, id int) *logrus.Entry {
+	return logger.WithContext(ctx).WithField(FieldScriptID, id)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "pg_start"
+
+var (
+	ScriptsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scripts_created_total",
+		Help:      "Number of created scripts by status script is created with.",
+	}, []string{"status"})
+
+	ScriptsFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scripts_finished_total",
+		Help:      "Number of finished scripts by final status.",
+	}, []string{"status"})
+
+	ScriptRunDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "script_run_duration_seconds",
+		Help:      "Time scripts run for.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 12),
+	})
+
+	ScriptOutputBytes = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "script_output_bytes",
+		Help:      "Size of scripts' output.",
+		Buckets:   prometheus.ExponentialBuckets(64, 4, 12),
+	})
+
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests by route pattern and status code.",
+	}, []string{"method", "route", "code"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time HTTP requests are handled for by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time repository queries take by repository and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "method"})
+)
+
+// RegisterRunningScripts registers gauge of running scripts with registerer, running is called on every scrape.
+// Error is returned if gauge is already registered with it
+func RegisterRunningScripts(registerer prometheus.Registerer, running func() int) error {
+	return registerer.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scripts_running",
+		Help:      "Number of scripts currently running on this instance.",
+	}, func() float64 { return float64(running()) }))
+}
+
+// ObserveDBQuery starts measuring query of repository's method, returned function stops it:
+//
+//	defer metrics.ObserveDBQuery("script", "GetScript")()
+func ObserveDBQuery(repository, method string) func() {
+	start := time.Now()
+
+	return func() {
+		DBQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
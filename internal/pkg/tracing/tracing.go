@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const tracerName = "pg-start-trainee-2024"
+
+// Tracer returns tracer of globally registered provider, spans are dropped unless tracing is set up
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts span of service or repository method, name looks like <package>.<Type>.<Method>
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartDB starts span of repository query
+func StartDB(ctx context.Context, repository, method string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, repository+".Repo."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(method)),
+	)
+}
+
+// Env returns W3C trace context of span ctx belongs to as environment variables (TRACEPARENT and TRACESTATE),
+// so processes started by service can continue the trace. Nothing is returned if there is no span in ctx
+func Env(ctx context.Context) []string {
+	carrier := propagation.MapCarrier{}
+
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	env := make([]string, 0, len(carrier))
+
+	for _, key := range carrier.Keys() {
+		env = append(env, strings.ToUpper(key)+"="+carrier.Get(key))
+	}
+
+	return env
+}
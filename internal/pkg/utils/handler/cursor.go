@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+type cursorToken struct {
+	CreatedAt int64 `json:"t"`
+	ID        int   `json:"id"`
+	Backward  bool  `json:"b,omitempty"`
+}
+
+// EncodeCursor returns opaque token for cursor, empty token is returned for nil cursor
+func EncodeCursor(cursor *entity.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	// marshalling of struct with primitive fields can't fail
+	data, _ := json.Marshal(cursorToken{
+		CreatedAt: cursor.CreatedAt.UnixMicro(),
+		ID:        cursor.ID,
+		Backward:  cursor.Backward,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses token made by EncodeCursor, empty token means no cursor
+func DecodeCursor(token string) (*entity.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var ct cursorToken
+
+	if err = json.Unmarshal(data, &ct); err != nil || ct.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &entity.Cursor{
+		CreatedAt: time.UnixMicro(ct.CreatedAt).UTC(),
+		ID:        ct.ID,
+		Backward:  ct.Backward,
+	}, nil
+}
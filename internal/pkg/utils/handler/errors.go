@@ -0,0 +1,7 @@
+package handler
+
+import "errors"
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor provided")
+)
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"pg-start-trainee-2024/internal/handler/request"
 
@@ -26,3 +28,99 @@ func GetPaginationOptsFromQuery(req *http.Request, defaultOffset int, defaultLim
 
 	return paginationOpts
 }
+
+func GetSearchScriptsFromQuery(req *http.Request) (request.SearchScripts, error) {
+	from, err := handlerutils.GetTimeParamFromQuery(req, "from")
+	if err != nil {
+		return request.SearchScripts{}, fmt.Errorf("from: %w", err)
+	}
+
+	to, err := handlerutils.GetTimeParamFromQuery(req, "to")
+	if err != nil {
+		return request.SearchScripts{}, fmt.Errorf("to: %w", err)
+	}
+
+	return request.SearchScripts{
+		Query: req.URL.Query().Get("q"),
+		Mode:  req.URL.Query().Get("mode"),
+		From:  from,
+		To:    to,
+	}, nil
+}
+
+func GetScriptFilterFromQuery(req *http.Request) (request.ScriptFilter, error) {
+	var (
+		filter request.ScriptFilter
+		err    error
+	)
+
+	if filter.IsRunning, err = handlerutils.GetOptionalBoolParamFromQuery(req, "is_running"); err != nil {
+		return request.ScriptFilter{}, fmt.Errorf("is_running: %w", err)
+	}
+
+	if filter.ExitCode, err = handlerutils.GetOptionalIntParamFromQuery(req, "exit_code"); err != nil {
+		return request.ScriptFilter{}, fmt.Errorf("exit_code: %w", err)
+	}
+
+	timeParams := map[string]**time.Time{
+		"created_from":  &filter.CreatedFrom,
+		"created_to":    &filter.CreatedTo,
+		"finished_from": &filter.FinishedFrom,
+		"finished_to":   &filter.FinishedTo,
+	}
+
+	for key, dest := range timeParams {
+		if *dest, err = handlerutils.GetTimeParamFromQuery(req, key); err != nil {
+			return request.ScriptFilter{}, fmt.Errorf("%v: %w", key, err)
+		}
+	}
+
+	filter.Statuses = handlerutils.GetListParamFromQuery(req, "status")
+	filter.Tags = handlerutils.GetListParamFromQuery(req, "tags")
+	filter.Command = req.URL.Query().Get("command")
+	filter.Sort = req.URL.Query().Get("sort")
+	filter.Order = req.URL.Query().Get("order")
+
+	return filter, nil
+}
+
+func GetAuditFilterFromQuery(req *http.Request) (request.AuditFilter, error) {
+	from, err := handlerutils.GetTimeParamFromQuery(req, "from")
+	if err != nil {
+		return request.AuditFilter{}, fmt.Errorf("from: %w", err)
+	}
+
+	to, err := handlerutils.GetTimeParamFromQuery(req, "to")
+	if err != nil {
+		return request.AuditFilter{}, fmt.Errorf("to: %w", err)
+	}
+
+	return request.AuditFilter{
+		Actor:      req.URL.Query().Get("actor"),
+		Actions:    handlerutils.GetListParamFromQuery(req, "action"),
+		TargetType: req.URL.Query().Get("target_type"),
+		TargetID:   req.URL.Query().Get("target_id"),
+		Namespace:  req.URL.Query().Get("namespace"),
+		From:       from,
+		To:         to,
+	}, nil
+}
+
+func GetWebhookDeliveryFilterFromQuery(req *http.Request) (request.WebhookDeliveryFilter, error) {
+	webhookID, err := handlerutils.GetOptionalIntParamFromQuery(req, "webhook_id")
+	if err != nil {
+		return request.WebhookDeliveryFilter{}, fmt.Errorf("webhook_id: %w", err)
+	}
+
+	scriptID, err := handlerutils.GetOptionalIntParamFromQuery(req, "script_id")
+	if err != nil {
+		return request.WebhookDeliveryFilter{}, fmt.Errorf("script_id: %w", err)
+	}
+
+	return request.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		ScriptID:  scriptID,
+		Namespace: req.URL.Query().Get("namespace"),
+		Status:    req.URL.Query().Get("status"),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const apiKeyColumns = "id, name, namespace, prefix, secret_hash, scopes, created_at, last_used_at, expires_at, revoked_at"
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+func (r *Repo) CreateAPIKey(ctx context.Context, key entity.APIKey) (*entity.APIKey, error) {
+	ctx, end := instrument.Query(ctx, "apikey", "CreateAPIKey")
+	defer end()
+
+	result, err := sqlx.NamedQueryContext(ctx, dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`INSERT INTO api_key (name, namespace, prefix, secret_hash, scopes, expires_at) 
+VALUES (:name, :namespace, :prefix, :secret_hash, :scopes, :expires_at) 
+RETURNING %v`, apiKeyColumns),
+		&key)
+	if err != nil {
+		return nil, err
+	}
+
+	defer result.Close()
+
+	if result.Next() {
+		if err = result.StructScan(&key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &key, nil
+}
+
+func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string, dest any, args ...any) error {
+	result := dbutils.Ext(ctx, r.DB).QueryRowxContext(ctx, query, args...)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return result.StructScan(dest)
+}
+
+func (r *Repo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	ctx, end := instrument.Query(ctx, "apikey", "GetAPIKeyByPrefix")
+	defer end()
+
+	var key entity.APIKey
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM api_key WHERE prefix = $1`, apiKeyColumns),
+		&key,
+		prefix,
+	); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// GetAllAPIKeys returns keys of namespace, keys of all namespaces are returned if it's empty
+func (r *Repo) GetAllAPIKeys(ctx context.Context, namespace string) ([]*entity.APIKey, error) {
+	ctx, end := instrument.Query(ctx, "apikey", "GetAllAPIKeys")
+	defer end()
+
+	rows, err := dbutils.Ext(ctx, r.DB).QueryxContext(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM api_key WHERE $1 = '' OR namespace = $1 ORDER BY id`, apiKeyColumns),
+		namespace,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+
+	for rows.Next() {
+		var key entity.APIKey
+
+		if err = rows.StructScan(&key); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks key of namespace as revoked, key of any namespace is revoked if namespace is empty.
+// Revoking already revoked key keeps its revocation time
+func (r *Repo) RevokeAPIKey(ctx context.Context, namespace string, id int) (*entity.APIKey, error) {
+	ctx, end := instrument.Query(ctx, "apikey", "RevokeAPIKey")
+	defer end()
+
+	var key entity.APIKey
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`UPDATE api_key SET revoked_at = coalesce(revoked_at, now()) WHERE id = $1 AND ($2 = '' OR namespace = $2)
+        RETURNING %v`, apiKeyColumns),
+		&key,
+		id, namespace,
+	); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// TouchAPIKey updates time key was last used at, it's updated at most once a minute not to write on every request
+func (r *Repo) TouchAPIKey(ctx context.Context, id int) error {
+	ctx, end := instrument.Query(ctx, "apikey", "TouchAPIKey")
+	defer end()
+
+	_, err := dbutils.Ext(ctx, r.DB).ExecContext(
+		ctx,
+		`UPDATE api_key SET last_used_at = now() 
+        WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`,
+		id,
+	)
+
+	return err
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+	sliceutils "pg-start-trainee-2024/pkg/utils/slice"
+)
+
+const auditEventColumns = "id, actor, action, target_type, target_id, namespace, request_id, client_ip, payload_hash, created_at"
+
+// auditFilterCondition matches events with filter passed as $1-$7 args, see filterArgs
+const auditFilterCondition = `($1 = '' OR actor = $1)
+  AND (cardinality($2::text[]) = 0 OR action = ANY ($2::text[]))
+  AND ($3 = '' OR target_type = $3)
+  AND ($4 = '' OR target_id = $4)
+  AND ($5::timestamp IS NULL OR created_at >= $5)
+  AND ($6::timestamp IS NULL OR created_at < $6)
+  AND ($7 = '' OR namespace = $7)`
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+func filterArgs(filter entity.AuditFilter) []any {
+	actions := sliceutils.Map(filter.Actions, func(action entity.AuditAction) string { return string(action) })
+
+	return []any{
+		filter.Actor,
+		dbutils.StringArray(actions),
+		string(filter.TargetType),
+		filter.TargetID,
+		filter.From,
+		filter.To,
+		filter.Namespace,
+	}
+}
+
+// CreateAuditEvent appends event to audit log, it's written in transaction ctx is run in if any
+func (r *Repo) CreateAuditEvent(ctx context.Context, event entity.AuditEvent) (*entity.AuditEvent, error) {
+	ctx, end := instrument.Query(ctx, "audit", "CreateAuditEvent")
+	defer end()
+
+	result, err := sqlx.NamedQueryContext(ctx, dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`INSERT INTO audit_event (actor, action, target_type, target_id, namespace, request_id, client_ip, payload_hash) 
+VALUES (:actor, :action, :target_type, :target_id, :namespace, :request_id, :client_ip, :payload_hash) 
+RETURNING %v`, auditEventColumns),
+		&event)
+	if err != nil {
+		return nil, err
+	}
+
+	defer result.Close()
+
+	if result.Next() {
+		if err = result.StructScan(&event); err != nil {
+			return nil, err
+		}
+	}
+
+	return &event, result.Err()
+}
+
+func (r *Repo) queryxContextWithStructScan(ctx context.Context, query string, args ...any) ([]*entity.AuditEvent, error) {
+	rows, err := dbutils.Ext(ctx, r.DB).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]*entity.AuditEvent, 0)
+
+	for rows.Next() {
+		var event entity.AuditEvent
+
+		if err = rows.StructScan(&event); err != nil {
+			return nil, err
+		}
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetAuditEvents returns events matching filter, most recent first
+func (r *Repo) GetAuditEvents(ctx context.Context, filter entity.AuditFilter, offset, limit int) ([]*entity.AuditEvent, error) {
+	ctx, end := instrument.Query(ctx, "audit", "GetAuditEvents")
+	defer end()
+
+	return r.queryxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM audit_event WHERE %v
+        ORDER BY id DESC OFFSET $8 LIMIT $9`, auditEventColumns, auditFilterCondition),
+		append(filterArgs(filter), offset, limit)...,
+	)
+}
+
+// GetAuditEventsAfter returns at most limit events matching filter with ID greater than afterID in order they were written
+func (r *Repo) GetAuditEventsAfter(ctx context.Context, filter entity.AuditFilter, afterID, limit int) ([]*entity.AuditEvent, error) {
+	ctx, end := instrument.Query(ctx, "audit", "GetAuditEventsAfter")
+	defer end()
+
+	return r.queryxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM audit_event WHERE %v AND id > $8
+        ORDER BY id LIMIT $9`, auditEventColumns, auditFilterCondition),
+		append(filterArgs(filter), afterID, limit)...,
+	)
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+func (r *Repo) Ping(ctx context.Context) error {
+	return r.DB.PingContext(ctx)
+}
+
+// GetMigrationVersion returns the latest applied goose migration, migration which was rolled back
+// has later row with is_applied = false
+func (r *Repo) GetMigrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+
+	err := r.DB.GetContext(ctx, &version, `SELECT coalesce(max(version_id), 0) FROM (
+            SELECT DISTINCT ON (version_id) version_id, is_applied FROM goose_db_version ORDER BY version_id, id DESC
+        ) versions WHERE is_applied`)
+
+	return version, err
+}
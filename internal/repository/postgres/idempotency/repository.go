@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const idempotencyKeyColumns = "scope, key, request_hash, script_id, response_status, response_headers, response_body, lease_token, created_at, expires_at"
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string, dest any, args ...any) error {
+	result := dbutils.Ext(ctx, r.DB).QueryRowxContext(ctx, query, args...)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return result.StructScan(dest)
+}
+
+// ClaimIdempotencyKey stores key of request with hash leased to leaseToken which expires after lease, expired key
+// is claimed anew. If key is already stored and not expired, it's returned with claimed false
+func (r *Repo) ClaimIdempotencyKey(ctx context.Context, scope, key, requestHash, leaseToken string, lease time.Duration) (*entity.IdempotencyKey, bool, error) {
+	ctx, end := instrument.Query(ctx, "idempotency", "ClaimIdempotencyKey")
+	defer end()
+
+	var stored entity.IdempotencyKey
+
+	err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`INSERT INTO idempotency_key (scope, key, request_hash, lease_token, expires_at)
+VALUES ($1, $2, $3, $4, now() + make_interval(secs => $5))
+ON CONFLICT (scope, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, script_id = NULL, response_status = NULL, response_headers = '{}',
+    response_body = NULL, lease_token = EXCLUDED.lease_token, created_at = now(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_key.expires_at <= now()
+RETURNING %v`, idempotencyKeyColumns),
+		&stored,
+		scope, key, requestHash, leaseToken, lease.Seconds(),
+	)
+	if err == nil {
+		return &stored, true, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	// key is held by another request
+	if err = r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`SELECT %v FROM idempotency_key WHERE scope = $1 AND key = $2`, idempotencyKeyColumns),
+		&stored,
+		scope, key,
+	); err != nil {
+		return nil, false, err
+	}
+
+	return &stored, false, nil
+}
+
+// ExtendIdempotencyKeyLease makes key in progress leased to leaseToken expire after lease from now,
+// sql.ErrNoRows is returned if key is not leased to leaseToken anymore
+func (r *Repo) ExtendIdempotencyKeyLease(ctx context.Context, scope, key, leaseToken string, lease time.Duration) error {
+	ctx, end := instrument.Query(ctx, "idempotency", "ExtendIdempotencyKeyLease")
+	defer end()
+
+	var stored entity.IdempotencyKey
+
+	return r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`UPDATE idempotency_key SET expires_at = now() + make_interval(secs => $4)
+WHERE scope = $1 AND key = $2 AND lease_token = $3 AND response_status IS NULL
+RETURNING %v`, idempotencyKeyColumns),
+		&stored,
+		scope, key, leaseToken, lease.Seconds(),
+	)
+}
+
+// CompleteIdempotencyKey stores response to request made with key in progress leased to key.LeaseToken, which
+// expires after ttl from now, sql.ErrNoRows is returned if key is deleted or not leased to the token anymore
+func (r *Repo) CompleteIdempotencyKey(ctx context.Context, key entity.IdempotencyKey, ttl time.Duration) (*entity.IdempotencyKey, error) {
+	ctx, end := instrument.Query(ctx, "idempotency", "CompleteIdempotencyKey")
+	defer end()
+
+	var stored entity.IdempotencyKey
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`UPDATE idempotency_key
+SET script_id = $4, response_status = $5, response_headers = $6, response_body = $7,
+    lease_token = NULL, expires_at = now() + make_interval(secs => $8)
+WHERE scope = $1 AND key = $2 AND lease_token = $3 AND response_status IS NULL
+RETURNING %v`, idempotencyKeyColumns),
+		&stored,
+		key.Scope, key.Key, key.LeaseToken, key.ScriptID, key.ResponseStatus, key.ResponseHeaders, key.ResponseBody, ttl.Seconds(),
+	); err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+// DeleteIdempotencyKey deletes key in progress leased to leaseToken, so request with it may be made again.
+// Key claimed anew by another request after lease expired is kept
+func (r *Repo) DeleteIdempotencyKey(ctx context.Context, scope, key, leaseToken string) error {
+	ctx, end := instrument.Query(ctx, "idempotency", "DeleteIdempotencyKey")
+	defer end()
+
+	_, err := dbutils.Ext(ctx, r.DB).ExecContext(
+		ctx,
+		`DELETE FROM idempotency_key WHERE scope = $1 AND key = $2 AND lease_token = $3 AND response_status IS NULL`,
+		scope, key, leaseToken,
+	)
+
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys deletes expired keys and returns number of them
+func (r *Repo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, end := instrument.Query(ctx, "idempotency", "DeleteExpiredIdempotencyKeys")
+	defer end()
+
+	result, err := dbutils.Ext(ctx, r.DB).ExecContext(ctx, `DELETE FROM idempotency_key WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,23 @@
+package instrument
+
+import (
+	"context"
+
+	"pg-start-trainee-2024/internal/pkg/metrics"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// Query starts span of repository's method query and measures its duration, returned function ends both:
+//
+//	ctx, end := instrument.Query(ctx, "script", "GetScript")
+//	defer end()
+func Query(ctx context.Context, repository, method string) (context.Context, func()) {
+	observed := metrics.ObserveDBQuery(repository, method)
+
+	ctx, span := tracing.StartDB(ctx, repository, method)
+
+	return ctx, func() {
+		span.End()
+		observed()
+	}
+}
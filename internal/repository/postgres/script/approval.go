@@ -0,0 +1,67 @@
+package script
+
+import (
+	"context"
+	"fmt"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+)
+
+// ReviewScript moves script pending approval to status, sql.ErrNoRows is returned if script is not pending approval
+// (so script can't be reviewed twice) or if approval has expired
+func (r *Repo) ReviewScript(
+	ctx context.Context,
+	id int,
+	status entity.ScriptStatus,
+	reviewedBy *string,
+	rejectReason *string,
+) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "ReviewScript")
+	defer end()
+
+	op := entity.ScriptChangeUpdated
+	if status != entity.ScriptStatusRunning && status != entity.ScriptStatusQueued {
+		op = entity.ScriptChangeFinished
+	}
+
+	return r.changed(ctx, op, func(ctx context.Context) (*entity.Script, error) {
+		var script entity.Script
+
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`UPDATE script SET status = $1, reviewed_by = $2, reviewed_at = now(), reject_reason = $3,
+                  finished_at = CASE WHEN $1::text IN ('running', 'queued') THEN NULL ELSE now() END
+              WHERE id = $4 AND status = 'pending_approval'
+                AND ($1::text NOT IN ('running', 'queued') OR approval_expires_at IS NULL OR approval_expires_at >= now())
+        RETURNING %v`, scriptColumns),
+			&script,
+			status, reviewedBy, rejectReason, id,
+		); err != nil {
+			return nil, err
+		}
+
+		return &script, nil
+	})
+}
+
+// ExpirePendingScripts marks scripts not reviewed in time as expired, returns number of expired scripts
+func (r *Repo) ExpirePendingScripts(ctx context.Context) (int64, error) {
+	ctx, end := instrument.Query(ctx, "script", "ExpirePendingScripts")
+	defer end()
+
+	expired, err := r.changedAll(ctx, entity.ScriptChangeFinished, func(ctx context.Context) ([]*entity.Script, error) {
+		return r.queryxContextWithStructScan(
+			ctx,
+			0,
+			fmt.Sprintf(`UPDATE script SET status = 'expired', finished_at = now() 
+        WHERE status = 'pending_approval' AND approval_expires_at < now()
+        RETURNING %v`, scriptColumns),
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(expired)), nil
+}
@@ -0,0 +1,140 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// ScriptChangesChannel is channel changes of scripts are notified on
+const ScriptChangesChannel = "script_changes"
+
+// scriptChangePayload is payload of notification, it must stay below 8000 bytes, so command and output are not sent
+type scriptChangePayload struct {
+	Op        entity.ScriptChangeOp `json:"op"`
+	ID        int                   `json:"id"`
+	Namespace string                `json:"namespace"`
+	Status    entity.ScriptStatus   `json:"status"`
+	IsRunning bool                  `json:"is_running"`
+
+	StopRequested bool `json:"stop_requested"`
+}
+
+func (r *Repo) notify(ctx context.Context, op entity.ScriptChangeOp, scripts ...*entity.Script) error {
+	for _, script := range scripts {
+		payload, err := json.Marshal(scriptChangePayload{
+			Op:        op,
+			ID:        script.ID,
+			Namespace: script.Namespace,
+			Status:    script.Status,
+			IsRunning: script.IsRunning,
+
+			StopRequested: script.StopRequestedAt != nil,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err = dbutils.Ext(ctx, r.DB).ExecContext(ctx, `SELECT pg_notify($1, $2)`, ScriptChangesChannel, string(payload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changed runs fn changing script and notifies of change in one transaction,
+// so notification is delivered only if change is committed
+func (r *Repo) changed(
+	ctx context.Context,
+	op entity.ScriptChangeOp,
+	fn func(ctx context.Context) (*entity.Script, error),
+) (*entity.Script, error) {
+	var script *entity.Script
+
+	err := r.transactor.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if script, err = fn(ctx); err != nil {
+			return err
+		}
+
+		return r.notify(ctx, op, script)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
+
+// changedAll runs fn changing scripts and notifies of changes in one transaction
+func (r *Repo) changedAll(
+	ctx context.Context,
+	op entity.ScriptChangeOp,
+	fn func(ctx context.Context) ([]*entity.Script, error),
+) ([]*entity.Script, error) {
+	var scripts []*entity.Script
+
+	err := r.transactor.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if scripts, err = fn(ctx); err != nil {
+			return err
+		}
+
+		return r.notify(ctx, op, scripts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+// ChangeListener receives changes of scripts made by any replica of service
+type ChangeListener struct {
+	listener *dbutils.Listener
+	logger   *logrus.Logger
+}
+
+func NewChangeListener(connectionURL string, retryInterval time.Duration, logger *logrus.Logger) *ChangeListener {
+	return &ChangeListener{
+		listener: dbutils.NewListener(connectionURL, ScriptChangesChannel, retryInterval, logger),
+		logger:   logger,
+	}
+}
+
+// Listen passes changes to handle until ctx is done, resync change is passed every time connection is reestablished
+func (l *ChangeListener) Listen(ctx context.Context, handle func(change entity.ScriptChange)) error {
+	connected := false
+
+	return l.listener.Listen(
+		ctx,
+		func() {
+			// nothing is missed before the first connection
+			if connected {
+				handle(entity.ScriptChange{Op: entity.ScriptChangeResync})
+			}
+
+			connected = true
+		},
+		func(payload string) {
+			var change scriptChangePayload
+
+			if err := json.Unmarshal([]byte(payload), &change); err != nil {
+				l.logger.Errorf("error occurred decoding script change %q: %v", payload, err)
+
+				return
+			}
+
+			handle(entity.ScriptChange(change))
+		},
+	)
+}
@@ -0,0 +1,44 @@
+package script
+
+import (
+	"context"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// GetNamespaceUsage returns number of running (or queued) scripts, number of scripts created after since and size of stored output
+func (r *Repo) GetNamespaceUsage(ctx context.Context, namespace string, since time.Time) (*entity.NamespaceUsage, error) {
+	ctx, end := instrument.Query(ctx, "script", "GetNamespaceUsage")
+	defer end()
+
+	var usage entity.NamespaceUsage
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		`SELECT count(*) FILTER (WHERE is_running OR status = 'queued') AS running_scripts,
+                count(*) FILTER (WHERE created_at >= $2)                AS runs_per_day,
+                coalesce(sum(octet_length(output)), 0)::bigint          AS output_bytes
+         FROM script WHERE namespace = $1`,
+		&usage,
+		namespace, since,
+	); err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// LockNamespaceQuota locks quota of namespace until the end of transaction ctx is run in,
+// so checks of quota followed by changes of usage are serialized across replicas
+func (r *Repo) LockNamespaceQuota(ctx context.Context, namespace string) error {
+	ctx, end := instrument.Query(ctx, "script", "LockNamespaceQuota")
+	defer end()
+
+	_, err := dbutils.Ext(ctx, r.DB).ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, namespace)
+
+	return err
+}
@@ -0,0 +1,171 @@
+package script
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+// scriptSortColumns is a whitelist of columns scripts can be sorted by
+var scriptSortColumns = map[entity.ScriptSortField]string{
+	entity.ScriptSortByID:         "id",
+	entity.ScriptSortByCommand:    "command",
+	entity.ScriptSortByExitCode:   "exit_code",
+	entity.ScriptSortByCreatedAt:  "created_at",
+	entity.ScriptSortByFinishedAt: "finished_at",
+}
+
+// selectBuilder builds parametrized select query, values are never interpolated into query text:
+// every '?' in condition is replaced with positional placeholder and value is passed as query argument
+type selectBuilder struct {
+	from       string
+	conditions []string
+	args       []any
+	orderBy    []string
+	offset     int
+	limit      int
+}
+
+func newSelectBuilder(from string) *selectBuilder {
+	return &selectBuilder{
+		from:  from,
+		limit: math.MaxInt64,
+	}
+}
+
+func (b *selectBuilder) where(condition string, args ...any) *selectBuilder {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%v", len(b.args)), 1)
+	}
+
+	b.conditions = append(b.conditions, condition)
+
+	return b
+}
+
+func (b *selectBuilder) order(expressions ...string) *selectBuilder {
+	b.orderBy = append(b.orderBy, expressions...)
+
+	return b
+}
+
+func (b *selectBuilder) paginate(offset, limit int) *selectBuilder {
+	b.offset = offset
+	b.limit = limit
+
+	return b
+}
+
+func (b *selectBuilder) build() (string, []any) {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("SELECT %v FROM %v", scriptColumns, b.from))
+
+	if len(b.conditions) != 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(b.conditions, " AND "))
+	}
+
+	if len(b.orderBy) != 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(b.orderBy, ", "))
+	}
+
+	if b.limit != math.MaxInt64 {
+		sb.WriteString(fmt.Sprintf(" LIMIT %v", b.limit))
+	}
+
+	sb.WriteString(fmt.Sprintf(" OFFSET %v", b.offset))
+
+	return sb.String(), b.args
+}
+
+// capacity returns expected count of rows to preallocate result slice
+func (b *selectBuilder) capacity() int {
+	if b.limit == math.MaxInt64 {
+		return 0
+	}
+
+	return b.limit
+}
+
+func applyScriptFilter(b *selectBuilder, filter entity.ScriptFilter) *selectBuilder {
+	if filter.Namespace != "" {
+		b.where("namespace = ?", filter.Namespace)
+	}
+
+	if len(filter.Statuses) != 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+
+		b.where("status = ANY(?)", statuses)
+	}
+
+	if filter.IsRunning != nil {
+		b.where("is_running = ?", *filter.IsRunning)
+	}
+
+	if filter.Command != "" {
+		b.where("strpos(lower(command), lower(?::text)) > 0", filter.Command)
+	}
+
+	if filter.ExitCode != nil {
+		b.where("exit_code = ?", *filter.ExitCode)
+	}
+
+	if len(filter.Tags) != 0 {
+		b.where("tags @> ?", filter.Tags)
+	}
+
+	if filter.CreatedFrom != nil {
+		b.where("created_at >= ?", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		b.where("created_at < ?", *filter.CreatedTo)
+	}
+
+	if filter.FinishedFrom != nil {
+		b.where("finished_at >= ?", *filter.FinishedFrom)
+	}
+
+	if filter.FinishedTo != nil {
+		b.where("finished_at < ?", *filter.FinishedTo)
+	}
+
+	column, ok := scriptSortColumns[filter.SortBy]
+	if !ok {
+		column = scriptSortColumns[entity.ScriptSortByCreatedAt]
+	}
+
+	direction := "ASC"
+	if filter.SortDirection == entity.SortDesc {
+		direction = "DESC"
+	}
+
+	// id makes order stable for equal values of sort column
+	if column == "id" {
+		return b.order(fmt.Sprintf("id %v", direction))
+	}
+
+	return b.order(fmt.Sprintf("%v %v NULLS LAST", column, direction), fmt.Sprintf("id %v", direction))
+}
+
+// applyScriptCursor limits query to rows located after cursor in order given by direction
+func applyScriptCursor(b *selectBuilder, cursor *entity.Cursor, direction entity.SortDirection) *selectBuilder {
+	if cursor == nil {
+		return b
+	}
+
+	comparison := ">"
+	if direction == entity.SortDesc {
+		comparison = "<"
+	}
+
+	return b.where(fmt.Sprintf("(created_at, id) %v (?, ?)", comparison), cursor.CreatedAt, cursor.ID)
+}
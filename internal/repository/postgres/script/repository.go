@@ -3,27 +3,54 @@ package script
 import (
 	"context"
 	"fmt"
-	"math"
+	"slices"
 
 	"github.com/jmoiron/sqlx"
 
 	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
 )
 
+const scriptColumns = "id, namespace, command, output, is_running, pid, status, exit_code, tags, api_key_id, created_by, interpreter, env, run_as, policy_rule, reviewed_by, reviewed_at, reject_reason, approval_expires_at, callback_url, label_selector, worker_id, stop_requested_at, created_at, updated_at, finished_at"
+
+// Repo stores scripts, every change of script is notified on ScriptChangesChannel
 type Repo struct {
 	DB *sqlx.DB
+
+	transactor *dbutils.Transactor
 }
 
 func New(db *sqlx.DB) *Repo {
 	return &Repo{
-		DB: db,
+		DB:         db,
+		transactor: dbutils.NewTransactor(db),
 	}
 }
 
 func (r *Repo) CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error) {
-	result, err := r.DB.NamedQueryContext(ctx,
-		`INSERT INTO script (command, output, is_running, pid) VALUES (:command, :output, :is_running, :pid) 
-RETURNING id, command, output, is_running, pid, created_at, updated_at`,
+	ctx, end := instrument.Query(ctx, "script", "CreateScript")
+	defer end()
+
+	if script.Status == "" {
+		script.Status = entity.ScriptStatusRunning
+	}
+
+	if script.Namespace == "" {
+		script.Namespace = entity.DefaultNamespace
+	}
+
+	return r.changed(ctx, entity.ScriptChangeCreated, func(ctx context.Context) (*entity.Script, error) {
+		return r.insertScript(ctx, script)
+	})
+}
+
+func (r *Repo) insertScript(ctx context.Context, script entity.Script) (*entity.Script, error) {
+	result, err := sqlx.NamedQueryContext(ctx, dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`INSERT INTO script (namespace, command, output, is_running, pid, status, tags, api_key_id, created_by, interpreter, env, run_as, policy_rule, approval_expires_at, callback_url, label_selector) 
+VALUES (:namespace, :command, :output, :is_running, :pid, :status, :tags, :api_key_id, :created_by, :interpreter, :env, :run_as, :policy_rule, :approval_expires_at, :callback_url, :label_selector) 
+RETURNING %v`, scriptColumns),
 		&script)
 	if err != nil {
 		return nil, err
@@ -40,8 +67,8 @@ RETURNING id, command, output, is_running, pid, created_at, updated_at`,
 	return &script, nil
 }
 
-func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string, dest any) error {
-	result := r.DB.QueryRowxContext(ctx, query)
+func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string, dest any, args ...any) error {
+	result := dbutils.Ext(ctx, r.DB).QueryRowxContext(ctx, query, args...)
 
 	if err := result.Err(); err != nil {
 		return err
@@ -51,13 +78,18 @@ func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string,
 }
 
 func (r *Repo) UpdateScriptOutput(ctx context.Context, id int, output string) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "UpdateScriptOutput")
+	defer end()
+
+	// output is appended by every chunk of it, so it's not notified, only lifecycle changes are
 	var script entity.Script
 
 	if err := r.queryRowxContextWithStructScan(
 		ctx,
-		fmt.Sprintf(`UPDATE script SET output = output || '%v' WHERE id = %v 
-        RETURNING  id, command, output, is_running, pid, created_at, updated_at`, output, id),
+		fmt.Sprintf(`UPDATE script SET output = output || $1 WHERE id = $2 
+        RETURNING %v`, scriptColumns),
 		&script,
+		output, id,
 	); err != nil {
 		return nil, err
 	}
@@ -65,58 +97,109 @@ func (r *Repo) UpdateScriptOutput(ctx context.Context, id int, output string) (*
 	return &script, nil
 }
 
-func (r *Repo) DeleteScript(ctx context.Context, id int) (*entity.Script, error) {
-	var script entity.Script
+// DeleteScript deletes script of namespace, script of any namespace is deleted if namespace is empty
+func (r *Repo) DeleteScript(ctx context.Context, namespace string, id int) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "DeleteScript")
+	defer end()
 
-	if err := r.queryRowxContextWithStructScan(
-		ctx,
-		fmt.Sprintf(`DELETE FROM script WHERE id = %v
-        RETURNING  id, command, output, is_running, pid, created_at, updated_at`, id),
-		&script,
-	); err != nil {
-		return nil, err
-	}
+	return r.changed(ctx, entity.ScriptChangeDeleted, func(ctx context.Context) (*entity.Script, error) {
+		var script entity.Script
 
-	return &script, nil
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`DELETE FROM script WHERE id = $1 AND ($2 = '' OR namespace = $2)
+        RETURNING %v`, scriptColumns),
+			&script,
+			id, namespace,
+		); err != nil {
+			return nil, err
+		}
+
+		return &script, nil
+	})
 }
 
 func (r *Repo) UpdateScriptPIDAndRunningState(ctx context.Context, id, pid int, isRunning bool) (*entity.Script, error) {
-	var script entity.Script
+	ctx, end := instrument.Query(ctx, "script", "UpdateScriptPIDAndRunningState")
+	defer end()
 
-	if err := r.queryRowxContextWithStructScan(
-		ctx,
-		fmt.Sprintf(`UPDATE script SET pid = %v, is_running = %v WHERE id = %v
-        RETURNING  id, command, output, is_running, pid, created_at, updated_at`, pid, isRunning, id),
-		&script,
-	); err != nil {
-		return nil, err
-	}
+	return r.changed(ctx, entity.ScriptChangeUpdated, func(ctx context.Context) (*entity.Script, error) {
+		var script entity.Script
 
-	return &script, nil
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`UPDATE script SET pid = $1, is_running = $2 WHERE id = $3
+        RETURNING %v`, scriptColumns),
+			&script,
+			pid, isRunning, id,
+		); err != nil {
+			return nil, err
+		}
+
+		return &script, nil
+	})
 }
 
 func (r *Repo) UpdateScriptRunningState(ctx context.Context, id int, isRunning bool) (*entity.Script, error) {
-	var script entity.Script
+	ctx, end := instrument.Query(ctx, "script", "UpdateScriptRunningState")
+	defer end()
 
-	if err := r.queryRowxContextWithStructScan(
-		ctx,
-		fmt.Sprintf(`UPDATE script SET is_running = %v WHERE id = %v
-        RETURNING  id, command, output, is_running, pid, created_at, updated_at`, isRunning, id),
-		&script,
-	); err != nil {
-		return nil, err
-	}
+	return r.changed(ctx, entity.ScriptChangeUpdated, func(ctx context.Context) (*entity.Script, error) {
+		var script entity.Script
 
-	return &script, nil
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`UPDATE script SET is_running = $1 WHERE id = $2
+        RETURNING %v`, scriptColumns),
+			&script,
+			isRunning, id,
+		); err != nil {
+			return nil, err
+		}
+
+		return &script, nil
+	})
 }
 
-func (r *Repo) GetScript(ctx context.Context, id int) (*entity.Script, error) {
+// FinishScript marks script as not running with given final status and exit code,
+// script that is already finished keeps its status
+func (r *Repo) FinishScript(ctx context.Context, id int, status entity.ScriptStatus, exitCode *int) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "FinishScript")
+	defer end()
+
+	return r.changed(ctx, entity.ScriptChangeFinished, func(ctx context.Context) (*entity.Script, error) {
+		var script entity.Script
+
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`UPDATE script SET is_running = false, 
+                  status = CASE WHEN finished_at IS NULL THEN $1 ELSE status END, 
+                  exit_code = CASE WHEN finished_at IS NULL THEN $2 ELSE exit_code END,
+                  finished_at = coalesce(finished_at, now()) 
+              WHERE id = $3
+        RETURNING %v`, scriptColumns),
+			&script,
+			status, exitCode, id,
+		); err != nil {
+			return nil, err
+		}
+
+		return &script, nil
+	})
+}
+
+// GetScript returns script of namespace, script of any namespace is returned if namespace is empty
+func (r *Repo) GetScript(ctx context.Context, namespace string, id int) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "GetScript")
+	defer end()
+
 	var script entity.Script
 
 	if err := r.queryRowxContextWithStructScan(
 		ctx,
-		fmt.Sprintf(`SELECT * FROM script WHERE id = %v`, id),
+		fmt.Sprintf(`SELECT %v FROM script WHERE id = $1 AND ($2 = '' OR namespace = $2)`, scriptColumns),
 		&script,
+		id, namespace,
 	); err != nil {
 		return nil, err
 	}
@@ -124,27 +207,15 @@ func (r *Repo) GetScript(ctx context.Context, id int) (*entity.Script, error) {
 	return &script, nil
 }
 
-func (r *Repo) GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error) {
-	query := "SELECT * FROM script ORDER BY created_at"
-
-	if limit == math.MaxInt64 {
-		query = fmt.Sprintf(`%v OFFSET %v`, query, offset)
-	} else {
-		query = fmt.Sprintf(`%v LIMIT %v OFFSET %v`, query, limit, offset)
-	}
-
-	rows, err := r.DB.QueryxContext(ctx, query)
+func (r *Repo) queryxContextWithStructScan(ctx context.Context, capacity int, query string, args ...any) ([]*entity.Script, error) {
+	rows, err := dbutils.Ext(ctx, r.DB).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
-	var scripts []*entity.Script
-
-	if limit != math.MaxInt64 {
-		scripts = make([]*entity.Script, 0, limit)
-	}
+	scripts := make([]*entity.Script, 0, capacity)
 
 	for rows.Next() {
 		var script entity.Script
@@ -156,5 +227,53 @@ func (r *Repo) GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.
 		scripts = append(scripts, &script)
 	}
 
+	return scripts, rows.Err()
+}
+
+// GetAllScripts returns scripts matching filter, ordered as filter specifies
+func (r *Repo) GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "GetAllScripts")
+	defer end()
+
+	builder := applyScriptFilter(newSelectBuilder("script"), filter).paginate(offset, limit)
+
+	query, args := builder.build()
+
+	return r.queryxContextWithStructScan(ctx, builder.capacity(), query, args...)
+}
+
+// GetScriptsByCursor returns at most limit scripts matching filter located after (or before for backward cursor) cursor
+// in (created_at, id) order, scripts are always returned in order requested by filter
+func (r *Repo) GetScriptsByCursor(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) ([]*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "GetScriptsByCursor")
+	defer end()
+
+	backward := cursor != nil && cursor.Backward
+
+	// page before cursor is fetched walking in opposite direction and reversed afterwards
+	if backward {
+		if filter.SortDirection == entity.SortDesc {
+			filter.SortDirection = entity.SortAsc
+		} else {
+			filter.SortDirection = entity.SortDesc
+		}
+	}
+
+	filter.SortBy = entity.ScriptSortByCreatedAt
+
+	builder := applyScriptCursor(newSelectBuilder("script"), cursor, filter.SortDirection)
+	builder = applyScriptFilter(builder, filter).paginate(0, limit)
+
+	query, args := builder.build()
+
+	scripts, err := r.queryxContextWithStructScan(ctx, builder.capacity(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if backward {
+		slices.Reverse(scripts)
+	}
+
 	return scripts, nil
 }
@@ -0,0 +1,162 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const (
+	// matched lines and matches within line returned per script
+	maxMatchedLines  = 20
+	maxMatchesInLine = 20
+
+	// search scans output of every candidate script, so it's cancelled by postgres if it takes longer
+	searchStatementTimeout = 5 * time.Second
+)
+
+var (
+	embeddedOptionsRegexp = regexp.MustCompile(`^\(\?([a-zA-Z]+)\)`)
+
+	// output is never returned by search, only its matched lines are
+	searchColumns = strings.Replace(scriptColumns, " output,", "", 1)
+)
+
+// matchedLines is jsonb array of matched lines built by search query
+type matchedLines []entity.MatchedLine
+
+func (l *matchedLines) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T to matched lines", src)
+	}
+}
+
+type searchHit struct {
+	entity.Script
+	Lines matchedLines `db:"lines"`
+}
+
+// lineMatches returns condition of matched line and jsonb expression of character ranges of matches within it,
+// both refer to line as l.text and to argument of search as $arg
+func lineMatches(search entity.ScriptSearch, arg int) (string, string) {
+	if search.Mode == entity.SearchModeRegex {
+		return fmt.Sprintf("l.text ~ $%v", arg), fmt.Sprintf(`(SELECT jsonb_agg(jsonb_build_array(
+                regexp_instr(l.text, $%[1]v, 1, k) - 1, regexp_instr(l.text, $%[1]v, 1, k, 1) - 1) ORDER BY k)
+            FROM generate_series(1, least(regexp_count(l.text, $%[1]v), %[2]v)) k)`, arg, maxMatchesInLine)
+	}
+
+	return fmt.Sprintf("tsvector_to_array(to_tsvector('simple', l.text)) && $%v::text[]", arg), "NULL::jsonb"
+}
+
+// SearchScriptsOutput returns scripts which output matches search query, most recent first, with the first lines
+// of output matched. Both scripts and lines are matched by postgres, so they never disagree
+func (r *Repo) SearchScriptsOutput(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchHit, error) {
+	ctx, end := instrument.Query(ctx, "script", "SearchScriptsOutput")
+	defer end()
+
+	builder := newSelectBuilder("script")
+
+	var lineArg any
+
+	switch search.Mode {
+	case entity.SearchModeRegex:
+		// output is matched line by line, so '^' and '$' anchor lines as they do for matched lines,
+		// leading embedded options of caller are merged with 'n' as postgres allows only one such group
+		pattern := "(?n)" + search.Query
+		if options := embeddedOptionsRegexp.FindStringSubmatch(search.Query); options != nil {
+			pattern = "(?n" + options[1] + ")" + search.Query[len(options[0]):]
+		}
+
+		builder.where("output ~ ?", pattern)
+
+		lineArg = search.Query
+	default:
+		builder.where("to_tsvector('simple', coalesce(output, '')) @@ websearch_to_tsquery('simple', ?)", search.Query)
+
+		lineArg = dbutils.StringArray(search.Words)
+	}
+
+	if search.Namespace != "" {
+		builder.where("namespace = ?", search.Namespace)
+	}
+
+	if search.From != nil {
+		builder.where("created_at >= ?", *search.From)
+	}
+
+	if search.To != nil {
+		builder.where("created_at < ?", *search.To)
+	}
+
+	builder.order("created_at DESC", "id DESC").paginate(offset, limit)
+
+	page, args := builder.build()
+	args = append(args, lineArg)
+
+	condition, ranges := lineMatches(search, len(args))
+
+	// output of the page only is split into lines, lines are numbered in order, so scan stops at the last matched line
+	query := fmt.Sprintf(`WITH hit AS (%v)
+SELECT %v, coalesce(matched.lines, '[]') AS lines
+FROM hit
+    CROSS JOIN LATERAL (
+        SELECT jsonb_agg(jsonb_build_object('number', l.number, 'text', l.text, 'ranges', %v) ORDER BY l.number) AS lines
+        FROM (SELECT l.text, l.number
+              FROM regexp_split_to_table(coalesce(hit.output, ''), E'\n') WITH ORDINALITY AS l(text, number)
+              WHERE %v
+              LIMIT %v) l
+        ) matched
+ORDER BY created_at DESC, id DESC`, page, searchColumns, ranges, condition, maxMatchedLines)
+
+	hits := make([]*entity.ScriptSearchHit, 0, builder.capacity())
+
+	err := r.transactor.InTx(ctx, func(ctx context.Context) error {
+		if _, err := dbutils.Ext(ctx, r.DB).ExecContext(
+			ctx,
+			fmt.Sprintf("SET LOCAL statement_timeout = %v", searchStatementTimeout.Milliseconds()),
+		); err != nil {
+			return err
+		}
+
+		rows, err := dbutils.Ext(ctx, r.DB).QueryxContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			var hit searchHit
+
+			if err = rows.StructScan(&hit); err != nil {
+				return err
+			}
+
+			hits = append(hits, &entity.ScriptSearchHit{Script: &hit.Script, Lines: hit.Lines})
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,164 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// RegisterWorker stores worker, worker registered with the same id before is replaced
+func (r *Repo) RegisterWorker(ctx context.Context, worker entity.Worker) (*entity.Worker, error) {
+	ctx, end := instrument.Query(ctx, "script", "RegisterWorker")
+	defer end()
+
+	var registered entity.Worker
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		`INSERT INTO worker (id, hostname, max_concurrent, labels) VALUES ($1, $2, $3, $4)
+        ON CONFLICT (id) DO UPDATE SET hostname = excluded.hostname, max_concurrent = excluded.max_concurrent,
+                                       labels = excluded.labels, started_at = now(), heartbeat_at = now()
+        RETURNING id, hostname, max_concurrent, labels, started_at, heartbeat_at`,
+		&registered,
+		worker.ID, worker.Hostname, worker.MaxConcurrent, worker.Labels,
+	); err != nil {
+		return nil, err
+	}
+
+	return &registered, nil
+}
+
+// HeartbeatWorker prolongs ownership of worker on scripts it runs
+func (r *Repo) HeartbeatWorker(ctx context.Context, id string) error {
+	ctx, end := instrument.Query(ctx, "script", "HeartbeatWorker")
+	defer end()
+
+	_, err := dbutils.Ext(ctx, r.DB).ExecContext(ctx, `UPDATE worker SET heartbeat_at = now() WHERE id = $1`, id)
+
+	return err
+}
+
+func (r *Repo) DeregisterWorker(ctx context.Context, id string) error {
+	ctx, end := instrument.Query(ctx, "script", "DeregisterWorker")
+	defer end()
+
+	_, err := dbutils.Ext(ctx, r.DB).ExecContext(ctx, `DELETE FROM worker WHERE id = $1`, id)
+
+	return err
+}
+
+// ClaimQueuedScripts marks at most limit oldest queued scripts which label selector worker matches as running by worker
+// and returns them, scripts claimed concurrently by other workers are skipped
+func (r *Repo) ClaimQueuedScripts(ctx context.Context, workerID string, limit int) ([]*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "ClaimQueuedScripts")
+	defer end()
+
+	return r.changedAll(ctx, entity.ScriptChangeUpdated, func(ctx context.Context) ([]*entity.Script, error) {
+		return r.queryxContextWithStructScan(
+			ctx,
+			limit,
+			fmt.Sprintf(`UPDATE script SET status = 'running', worker_id = $1
+        WHERE status = 'queued' AND id IN (
+            SELECT id FROM script
+            WHERE status = 'queued' AND label_selector <@ (SELECT labels FROM worker WHERE id = $1)
+            ORDER BY created_at, id
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING %v`, scriptColumns),
+			workerID, limit,
+		)
+	})
+}
+
+// RequestScriptStop requests stop of running script, queued script is stopped right away as nobody runs it yet.
+// sql.ErrNoRows is returned if script is neither queued nor running
+func (r *Repo) RequestScriptStop(ctx context.Context, id int) (*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "RequestScriptStop")
+	defer end()
+
+	var script entity.Script
+
+	err := r.transactor.InTx(ctx, func(ctx context.Context) error {
+		if err := r.queryRowxContextWithStructScan(
+			ctx,
+			fmt.Sprintf(`UPDATE script SET stop_requested_at = coalesce(stop_requested_at, now()),
+                  is_running = CASE WHEN status = 'queued' THEN false ELSE is_running END,
+                  finished_at = CASE WHEN status = 'queued' THEN now() ELSE finished_at END,
+                  status = CASE WHEN status = 'queued' THEN 'stopped' ELSE status END
+              WHERE id = $1 AND status IN ('queued', 'running')
+        RETURNING %v`, scriptColumns),
+			&script,
+			id,
+		); err != nil {
+			return err
+		}
+
+		op := entity.ScriptChangeUpdated
+		if script.Status == entity.ScriptStatusStopped {
+			op = entity.ScriptChangeFinished
+		}
+
+		return r.notify(ctx, op, &script)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
+
+// GetStopRequestedScripts returns scripts run by worker which stop is requested
+func (r *Repo) GetStopRequestedScripts(ctx context.Context, workerID string) ([]*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "GetStopRequestedScripts")
+	defer end()
+
+	return r.queryxContextWithStructScan(
+		ctx,
+		0,
+		fmt.Sprintf(`SELECT %v FROM script
+        WHERE status = 'running' AND worker_id = $1 AND stop_requested_at IS NOT NULL`, scriptColumns),
+		workerID,
+	)
+}
+
+// MarkLostScripts marks running scripts of workers that haven't heartbeated for lostAfter as lost and returns them
+func (r *Repo) MarkLostScripts(ctx context.Context, lostAfter time.Duration) ([]*entity.Script, error) {
+	ctx, end := instrument.Query(ctx, "script", "MarkLostScripts")
+	defer end()
+
+	return r.changedAll(ctx, entity.ScriptChangeFinished, func(ctx context.Context) ([]*entity.Script, error) {
+		return r.queryxContextWithStructScan(
+			ctx,
+			0,
+			fmt.Sprintf(`UPDATE script SET status = 'lost', is_running = false, finished_at = now()
+        WHERE status = 'running' AND worker_id IS NOT NULL
+          AND worker_id NOT IN (SELECT id FROM worker WHERE heartbeat_at >= now() - make_interval(secs => $1))
+        RETURNING %v`, scriptColumns),
+			lostAfter.Seconds(),
+		)
+	})
+}
+
+// CountMatchingWorkers returns number of workers heartbeated within aliveWithin which labels match selector
+func (r *Repo) CountMatchingWorkers(ctx context.Context, selector dbutils.StringMap, aliveWithin time.Duration) (int, error) {
+	ctx, end := instrument.Query(ctx, "script", "CountMatchingWorkers")
+	defer end()
+
+	var count int
+
+	if err := dbutils.Ext(ctx, r.DB).QueryRowxContext(
+		ctx,
+		`SELECT count(*) FROM worker WHERE labels @> $1::jsonb AND heartbeat_at >= now() - make_interval(secs => $2)`,
+		selector, aliveWithin.Seconds(),
+	).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const deliveryColumns = "id, webhook_id, script_id, namespace, event, url, payload, signature, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at"
+
+func (r *Repo) CreateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "CreateWebhookDelivery")
+	defer end()
+
+	result, err := sqlx.NamedQueryContext(ctx, dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`INSERT INTO webhook_delivery (webhook_id, script_id, namespace, event, url, payload, signature) 
+VALUES (:webhook_id, :script_id, :namespace, :event, :url, :payload, :signature) 
+RETURNING %v`, deliveryColumns),
+		&delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	defer result.Close()
+
+	if result.Next() {
+		if err = result.StructScan(&delivery); err != nil {
+			return nil, err
+		}
+	}
+
+	return &delivery, result.Err()
+}
+
+// ClaimDueWebhookDeliveries returns at most limit pending deliveries which next attempt is due and postpones
+// their next attempt by lease, so other workers don't pick them up while they are delivered.
+// Delivery is attempted again after lease if worker is gone before attempt is recorded
+func (r *Repo) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "ClaimDueWebhookDeliveries")
+	defer end()
+
+	return queryxContextWithStructScan[entity.WebhookDelivery](
+		ctx,
+		dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`UPDATE webhook_delivery SET next_attempt_at = now() + make_interval(secs => $2)
+        WHERE id IN (SELECT id FROM webhook_delivery WHERE status = 'pending' AND next_attempt_at <= now()
+                     ORDER BY next_attempt_at, id LIMIT $1 FOR UPDATE SKIP LOCKED)
+        RETURNING %v`, deliveryColumns),
+		limit, lease.Seconds(),
+	)
+}
+
+// CompleteWebhookDelivery records successful attempt of delivery
+func (r *Repo) CompleteWebhookDelivery(ctx context.Context, id, responseStatus int) (*entity.WebhookDelivery, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "CompleteWebhookDelivery")
+	defer end()
+
+	var delivery entity.WebhookDelivery
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`UPDATE webhook_delivery SET status = 'succeeded', attempts = attempts + 1, 
+                            response_status = $1, last_error = NULL, delivered_at = now()
+        WHERE id = $2
+        RETURNING %v`, deliveryColumns),
+		&delivery,
+		responseStatus, id,
+	); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// FailWebhookDeliveryAttempt records failed attempt of delivery, delivery is attempted again in retryIn
+// or marked as failed if retryIn is nil. Response status is nil if no response was received
+func (r *Repo) FailWebhookDeliveryAttempt(
+	ctx context.Context,
+	id int,
+	responseStatus *int,
+	lastError string,
+	retryIn *time.Duration,
+) (*entity.WebhookDelivery, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "FailWebhookDeliveryAttempt")
+	defer end()
+
+	var retryInSecs *float64
+	if retryIn != nil {
+		secs := retryIn.Seconds()
+		retryInSecs = &secs
+	}
+
+	var delivery entity.WebhookDelivery
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`UPDATE webhook_delivery SET attempts = attempts + 1, response_status = $1, last_error = $2,
+                            status = CASE WHEN $3::float8 IS NULL THEN 'failed' ELSE status END,
+                            next_attempt_at = CASE WHEN $3::float8 IS NULL THEN next_attempt_at 
+                                                   ELSE now() + make_interval(secs => $3::float8) END
+        WHERE id = $4
+        RETURNING %v`, deliveryColumns),
+		&delivery,
+		responseStatus, lastError, retryInSecs, id,
+	); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries returns deliveries matching filter, most recent first
+func (r *Repo) GetWebhookDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "GetWebhookDeliveries")
+	defer end()
+
+	return queryxContextWithStructScan[entity.WebhookDelivery](
+		ctx,
+		dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`SELECT %v FROM webhook_delivery 
+        WHERE ($1::bigint IS NULL OR webhook_id = $1) 
+          AND ($2::bigint IS NULL OR script_id = $2) 
+          AND ($3 = '' OR status = $3)
+          AND ($4 = '' OR namespace = $4)
+        ORDER BY id DESC OFFSET $5 LIMIT $6`, deliveryColumns),
+		filter.WebhookID, filter.ScriptID, string(filter.Status), filter.Namespace, offset, limit,
+	)
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/repository/postgres/instrument"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const webhookColumns = "id, name, url, namespace, events, secret, created_by, created_at"
+
+type Repo struct {
+	DB *sqlx.DB
+}
+
+func New(db *sqlx.DB) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+func queryxContextWithStructScan[T any](ctx context.Context, ext sqlx.ExtContext, query string, args ...any) ([]*T, error) {
+	rows, err := ext.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make([]*T, 0)
+
+	for rows.Next() {
+		var item T
+
+		if err = rows.StructScan(&item); err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *Repo) queryRowxContextWithStructScan(ctx context.Context, query string, dest any, args ...any) error {
+	result := dbutils.Ext(ctx, r.DB).QueryRowxContext(ctx, query, args...)
+
+	if err := result.Err(); err != nil {
+		return err
+	}
+
+	return result.StructScan(dest)
+}
+
+func (r *Repo) CreateWebhook(ctx context.Context, webhook entity.Webhook) (*entity.Webhook, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "CreateWebhook")
+	defer end()
+
+	result, err := sqlx.NamedQueryContext(ctx, dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`INSERT INTO webhook (name, url, namespace, events, secret, created_by) 
+VALUES (:name, :url, :namespace, :events, :secret, :created_by) 
+RETURNING %v`, webhookColumns),
+		&webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	defer result.Close()
+
+	if result.Next() {
+		if err = result.StructScan(&webhook); err != nil {
+			return nil, err
+		}
+	}
+
+	return &webhook, result.Err()
+}
+
+// GetAllWebhooks returns webhooks of namespace, webhooks of all namespaces are returned if it's empty
+func (r *Repo) GetAllWebhooks(ctx context.Context, namespace string) ([]*entity.Webhook, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "GetAllWebhooks")
+	defer end()
+
+	return queryxContextWithStructScan[entity.Webhook](
+		ctx,
+		dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`SELECT %v FROM webhook WHERE $1 = '' OR namespace = $1 ORDER BY id`, webhookColumns),
+		namespace,
+	)
+}
+
+// GetWebhooksForEvent returns webhooks subscribed to event of script of namespace
+func (r *Repo) GetWebhooksForEvent(ctx context.Context, namespace string, event entity.ScriptEvent) ([]*entity.Webhook, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "GetWebhooksForEvent")
+	defer end()
+
+	return queryxContextWithStructScan[entity.Webhook](
+		ctx,
+		dbutils.Ext(ctx, r.DB),
+		fmt.Sprintf(`SELECT %v FROM webhook WHERE (namespace = '' OR namespace = $1) AND $2 = ANY (events)
+        ORDER BY id`, webhookColumns),
+		namespace, event,
+	)
+}
+
+// DeleteWebhook deletes webhook of namespace together with its deliveries, webhook of any namespace is deleted
+// if namespace is empty
+func (r *Repo) DeleteWebhook(ctx context.Context, namespace string, id int) (*entity.Webhook, error) {
+	ctx, end := instrument.Query(ctx, "webhook", "DeleteWebhook")
+	defer end()
+
+	var webhook entity.Webhook
+
+	if err := r.queryRowxContextWithStructScan(
+		ctx,
+		fmt.Sprintf(`DELETE FROM webhook WHERE id = $1 AND ($2 = '' OR namespace = $2) RETURNING %v`, webhookColumns),
+		&webhook,
+		id, namespace,
+	); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
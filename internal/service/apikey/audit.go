@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/audit"
+)
+
+type AuditLog interface {
+	CreateAuditEvent(ctx context.Context, event entity.AuditEvent) (*entity.AuditEvent, error)
+}
+
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// keyPayload is part of api key audit event's payload hash is computed of, secret is never part of it
+type keyPayload struct {
+	Name      string     `json:"name"`
+	Namespace string     `json:"namespace"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// audited runs fn changing key and writes audit event of action on this key in one transaction,
+// nothing is written if fn fails
+func (s *Service) audited(
+	ctx context.Context,
+	action entity.AuditAction,
+	payload any,
+	fn func(ctx context.Context) (*entity.APIKey, error),
+) (*entity.APIKey, error) {
+	var key *entity.APIKey
+
+	err := s.Transactor.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if key, err = fn(ctx); err != nil {
+			return err
+		}
+
+		event, err := audit.NewEvent(ctx, action, entity.AuditTargetAPIKey, strconv.Itoa(key.ID), key.Namespace, payload)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Audit.CreateAuditEvent(ctx, event)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,15 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	ErrAPIKeyRevoked = errors.New("api key is revoked")
+	ErrAPIKeyExpired = errors.New("api key is expired")
+
+	ErrNoSuchAPIKey = errors.New("no such api key")
+	ErrUnknownScope = errors.New("unknown scope")
+
+	ErrNamespaceRequired = errors.New("namespace of api key is required")
+	ErrGlobalKeyNotAdmin = errors.New("only admin api key may be granted every namespace")
+)
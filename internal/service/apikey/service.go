@@ -0,0 +1,208 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+const (
+	// key looks like pgst_<prefix>_<secret>, prefix is stored as is to look key up, secret is stored hashed
+	keyMarker    = "pgst"
+	prefixLength = 6
+	secretLength = 32
+
+	bootstrapSubject = "bootstrap"
+)
+
+var knownScopes = []entity.Scope{entity.ScopeScriptsRead, entity.ScopeScriptsWrite, entity.ScopeAdmin}
+
+type Repo interface {
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (*entity.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	GetAllAPIKeys(ctx context.Context, namespace string) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, namespace string, id int) (*entity.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int) error
+}
+
+type Service struct {
+	Repo Repo
+
+	// Audit is written in one transaction with changes of keys
+	Audit      AuditLog
+	Transactor Transactor
+
+	logger       *logrus.Logger
+	bootstrapKey string
+}
+
+// New creates service, non-empty bootstrapKey is accepted as admin key, so the first keys can be created
+func New(repo Repo, audit AuditLog, transactor Transactor, logger *logrus.Logger, bootstrapKey string) *Service {
+	return &Service{
+		Repo:         repo,
+		Audit:        audit,
+		Transactor:   transactor,
+		logger:       logger,
+		bootstrapKey: bootstrapKey,
+	}
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, n)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return encode(buf), nil
+}
+
+// CreateAPIKey creates new key, plain key is returned only here and can't be restored later.
+// Caller bound to namespace creates keys of own namespace only
+func (s *Service) CreateAPIKey(ctx context.Context, key entity.APIKey) (*entity.APIKey, string, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.CreateAPIKey")
+	defer span.End()
+
+	for _, scope := range key.Scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return nil, "", fmt.Errorf("%w: %v", ErrUnknownScope, scope)
+		}
+	}
+
+	prefix, err := randomString(prefixLength, hex.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomString(secretLength, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if key.Namespace, err = auth.ConfineNamespace(ctx, key.Namespace); err != nil {
+		return nil, "", err
+	}
+
+	// caller which may access every namespace names namespace of key explicitly, global keys are admin ones only
+	if key.Namespace == "" {
+		return nil, "", ErrNamespaceRequired
+	}
+
+	if key.Namespace == entity.GlobalNamespace && !slices.Contains(key.Scopes, entity.ScopeAdmin) {
+		return nil, "", ErrGlobalKeyNotAdmin
+	}
+
+	key.Prefix = prefix
+	key.SecretHash = hashSecret(secret)
+
+	payload := keyPayload{
+		Name:      key.Name,
+		Namespace: key.Namespace,
+		Scopes:    key.Scopes,
+		ExpiresAt: key.ExpiresAt,
+	}
+
+	created, err := s.audited(ctx, entity.AuditActionCreateAPIKey, payload, func(ctx context.Context) (*entity.APIKey, error) {
+		return s.Repo.CreateAPIKey(ctx, key)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return created, fmt.Sprintf("%v_%v_%v", keyMarker, prefix, secret), nil
+}
+
+// GetAllAPIKeys returns keys of caller's namespace
+func (s *Service) GetAllAPIKeys(ctx context.Context) ([]*entity.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.GetAllAPIKeys")
+	defer span.End()
+
+	return s.Repo.GetAllAPIKeys(ctx, auth.Namespace(ctx))
+}
+
+// RevokeAPIKey revokes key of caller's namespace, keys of other namespaces are reported as not existing
+func (s *Service) RevokeAPIKey(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "apikey.Service.RevokeAPIKey")
+	defer span.End()
+
+	_, err := s.audited(ctx, entity.AuditActionRevokeAPIKey, nil, func(ctx context.Context) (*entity.APIKey, error) {
+		return s.Repo.RevokeAPIKey(ctx, auth.Namespace(ctx), id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchAPIKey
+	}
+
+	return err
+}
+
+// Authenticate returns principal identified by plain api key
+func (s *Service) Authenticate(ctx context.Context, plainKey string) (*entity.Principal, error) {
+	ctx, span := tracing.Start(ctx, "apikey.Service.Authenticate")
+	defer span.End()
+
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(plainKey), []byte(s.bootstrapKey)) == 1 {
+		return &entity.Principal{
+			Subject:   bootstrapSubject,
+			Scopes:    []entity.Scope{entity.ScopeAdmin},
+			Namespace: entity.GlobalNamespace,
+		}, nil
+	}
+
+	parts := strings.SplitN(plainKey, "_", 3)
+	if len(parts) != 3 || parts[0] != keyMarker {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.Repo.GetAPIKeyByPrefix(ctx, parts[1])
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(parts[2])), []byte(key.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now().UTC()) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if err = s.Repo.TouchAPIKey(ctx, key.ID); err != nil {
+		s.logger.WithContext(ctx).WithField("api_key_id", key.ID).Errorf("error occurred updating api key's last usage time: %v", err)
+	}
+
+	return &entity.Principal{
+		Subject:   fmt.Sprintf("apikey:%v", key.ID),
+		APIKeyID:  &key.ID,
+		Scopes:    key.Scopes,
+		Namespace: key.Namespace,
+	}, nil
+}
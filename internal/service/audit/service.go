@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// exportBatchSize is number of events fetched at once during export
+const exportBatchSize = 500
+
+type Repo interface {
+	GetAuditEvents(ctx context.Context, filter entity.AuditFilter, offset, limit int) ([]*entity.AuditEvent, error)
+	GetAuditEventsAfter(ctx context.Context, filter entity.AuditFilter, afterID, limit int) ([]*entity.AuditEvent, error)
+}
+
+type Service struct {
+	Repo Repo
+}
+
+func New(repo Repo) *Service {
+	return &Service{
+		Repo: repo,
+	}
+}
+
+func (s *Service) GetAuditEvents(ctx context.Context, filter entity.AuditFilter, offset, limit int) ([]*entity.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "audit.Service.GetAuditEvents")
+	defer span.End()
+
+	var err error
+
+	if filter.Namespace, err = auth.ConfineNamespace(ctx, filter.Namespace); err != nil {
+		return nil, err
+	}
+
+	return s.Repo.GetAuditEvents(ctx, filter, offset, limit)
+}
+
+// ExportAuditEvents passes every event matching filter to write in order events were written,
+// events are fetched by batches so the whole log is never loaded into memory
+func (s *Service) ExportAuditEvents(ctx context.Context, filter entity.AuditFilter, write func(event *entity.AuditEvent) error) error {
+	ctx, span := tracing.Start(ctx, "audit.Service.ExportAuditEvents")
+	defer span.End()
+
+	var err error
+
+	if filter.Namespace, err = auth.ConfineNamespace(ctx, filter.Namespace); err != nil {
+		return err
+	}
+
+	afterID := 0
+
+	for {
+		events, err := s.Repo.GetAuditEventsAfter(ctx, filter, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err = write(event); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < exportBatchSize {
+			return nil
+		}
+
+		afterID = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,100 @@
+package bus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+type Listener interface {
+	Listen(ctx context.Context, handle func(change entity.ScriptChange)) error
+}
+
+// Bus fans changes of scripts made by any replica of service out to in-process subscribers
+type Bus struct {
+	Listener Listener
+
+	mutex       *sync.RWMutex
+	subscribers map[int]chan entity.ScriptChange
+	nextID      int
+
+	logger *logrus.Logger
+}
+
+func New(listener Listener, logger *logrus.Logger) *Bus {
+	return &Bus{
+		Listener:    listener,
+		mutex:       &sync.RWMutex{},
+		subscribers: make(map[int]chan entity.ScriptChange),
+		logger:      logger,
+	}
+}
+
+// Subscribe returns channel changes are sent to and function cancelling subscription, which closes the channel.
+// Changes are not waited to be received: if subscriber has buffer changes not received yet, they are replaced with
+// resync change, so no change is lost silently and subscriber reloads its state instead. Buffer is at least 1,
+// so there is always room for resync
+func (b *Bus) Subscribe(buffer int) (<-chan entity.ScriptChange, func()) {
+	buffer = max(buffer, 1)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	changes := make(chan entity.ScriptChange, buffer)
+	b.subscribers[id] = changes
+
+	once := sync.Once{}
+
+	return changes, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+
+			delete(b.subscribers, id)
+			close(changes)
+		})
+	}
+}
+
+func (b *Bus) publish(change entity.ScriptChange) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for id, changes := range b.subscribers {
+		select {
+		case changes <- change:
+		default:
+			b.logger.Warnf("subscriber %v is too slow: %v change of script %v is replaced with resync", id, change.Op, change.ID)
+
+			// changes are sent by publish only, so buffer has room for resync once it's drained, send never blocks
+			// anyway, as it's done under lock cancelling subscription waits for
+			drain(changes)
+
+			select {
+			case changes <- entity.ScriptChange{Op: entity.ScriptChangeResync}:
+			default:
+			}
+		}
+	}
+}
+
+func drain(changes chan entity.ScriptChange) {
+	for {
+		select {
+		case <-changes:
+		default:
+			return
+		}
+	}
+}
+
+// Run listens to changes and publishes them to subscribers until ctx is done
+func (b *Bus) Run(ctx context.Context) error {
+	return b.Listener.Listen(ctx, b.publish)
+}
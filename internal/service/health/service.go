@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+
+	osutils "pg-start-trainee-2024/pkg/utils/os"
+)
+
+// checkTimeout limits every readiness check, so hanging db doesn't hang probes
+const checkTimeout = 2 * time.Second
+
+var (
+	ErrShuttingDown             = errors.New("service is shutting down")
+	ErrMigrationVersionMismatch = errors.New("db migration version mismatch")
+)
+
+type Repo interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (int64, error)
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type Service struct {
+	Repo Repo
+
+	expectedMigrationVersion int64
+	shuttingDown             atomic.Bool
+}
+
+// New creates service, readiness requires db to be migrated exactly to expectedMigrationVersion
+func New(repo Repo, expectedMigrationVersion int64) *Service {
+	return &Service{
+		Repo:                     repo,
+		expectedMigrationVersion: expectedMigrationVersion,
+	}
+}
+
+// StartShutdown makes service not ready, so no new requests are routed to it while it's shutting down
+func (s *Service) StartShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// Live reports that process is alive, it's up as long as process serves requests
+func (s *Service) Live() entity.HealthReport {
+	return entity.HealthReport{Status: entity.HealthStatusUp, Checks: make([]entity.HealthCheck, 0)}
+}
+
+func (s *Service) checkShutdown(context.Context) error {
+	if s.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+
+	return nil
+}
+
+func (s *Service) checkMigrations(ctx context.Context) error {
+	version, err := s.Repo.GetMigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version != s.expectedMigrationVersion {
+		return fmt.Errorf("%w: db is migrated to %v, expected %v", ErrMigrationVersionMismatch, version, s.expectedMigrationVersion)
+	}
+
+	return nil
+}
+
+// Ready reports whether service can serve requests: it's not shutting down, db is reachable and migrated
+// and scripts can be started
+func (s *Service) Ready(ctx context.Context) entity.HealthReport {
+	checks := []check{
+		{name: "shutdown", run: s.checkShutdown},
+		{name: "database", run: s.Repo.Ping},
+		{name: "migrations", run: s.checkMigrations},
+		{name: "executor", run: osutils.CheckCanRun},
+	}
+
+	report := entity.HealthReport{
+		Status: entity.HealthStatusUp,
+		Checks: make([]entity.HealthCheck, 0, len(checks)),
+	}
+
+	for _, c := range checks {
+		result := s.runCheck(ctx, c)
+		if result.Status != entity.HealthStatusUp {
+			report.Status = entity.HealthStatusDown
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+func (s *Service) runCheck(ctx context.Context, c check) entity.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.run(ctx)
+
+	result := entity.HealthCheck{
+		Name:     c.name,
+		Status:   entity.HealthStatusUp,
+		Duration: time.Since(start),
+	}
+
+	if err != nil {
+		result.Status = entity.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
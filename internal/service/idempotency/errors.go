@@ -0,0 +1,9 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrKeyReused         = errors.New("idempotency key is already used with different request")
+	ErrRequestInProgress = errors.New("request with idempotency key is in progress")
+	ErrLeaseLost         = errors.New("lease of idempotency key is lost")
+)
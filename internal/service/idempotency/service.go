@@ -0,0 +1,193 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// leaseTokenLength is length in bytes of token identifying request holding key
+const leaseTokenLength = 16
+
+type Repo interface {
+	ClaimIdempotencyKey(ctx context.Context, scope, key, requestHash, leaseToken string, lease time.Duration) (*entity.IdempotencyKey, bool, error)
+	ExtendIdempotencyKeyLease(ctx context.Context, scope, key, leaseToken string, lease time.Duration) error
+	CompleteIdempotencyKey(ctx context.Context, key entity.IdempotencyKey, ttl time.Duration) (*entity.IdempotencyKey, error)
+	DeleteIdempotencyKey(ctx context.Context, scope, key, leaseToken string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+type Service struct {
+	Repo Repo
+
+	logger *logrus.Logger
+	// ttl is time response to request is replayed for
+	ttl time.Duration
+	// lease is time claimed key of request being processed is held for
+	lease time.Duration
+}
+
+func New(repo Repo, logger *logrus.Logger, ttl, lease time.Duration) *Service {
+	return &Service{
+		Repo:   repo,
+		logger: logger,
+		ttl:    ttl,
+		lease:  lease,
+	}
+}
+
+// scope returns scope of caller's keys, anonymous callers share the same scope
+func scope(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+
+	return ""
+}
+
+// Claim claims key for request with hash. Claimed key is returned not completed, request should be processed then
+// and key completed or released with it. Completed key is returned if request was already processed and its response
+// should be replayed, ErrKeyReused is returned if key was used with another request and ErrRequestInProgress if request
+// with key is being processed. Key is claimed for lease only, so key of request that never completed is not held for
+// the whole ttl, and lease should be kept while request is processed
+func (s *Service) Claim(ctx context.Context, key, requestHash string) (*entity.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Claim")
+	defer span.End()
+
+	buf := make([]byte, leaseTokenLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	stored, claimed, err := s.Repo.ClaimIdempotencyKey(ctx, scope(ctx), key, requestHash, hex.EncodeToString(buf), s.lease)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claimed:
+		return stored, nil
+
+	case stored.RequestHash != requestHash:
+		return nil, ErrKeyReused
+
+	case !stored.Completed():
+		return nil, ErrRequestInProgress
+
+	default:
+		return stored, nil
+	}
+}
+
+// KeepLease extends lease of claimed key every third of lease until returned function is called, so key of request
+// processed longer than lease is not claimed by retry of the request meanwhile. Returned function waits for extension
+// in progress, so lease is not extended after it returns
+func (s *Service) KeepLease(ctx context.Context, claimed entity.IdempotencyKey) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(s.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				err := s.Repo.ExtendIdempotencyKeyLease(ctx, claimed.Scope, claimed.Key, *claimed.LeaseToken, s.lease)
+
+				switch {
+				case ctx.Err() != nil:
+					return
+
+				case errors.Is(err, sql.ErrNoRows):
+					s.logger.WithContext(ctx).Warnf("lease of idempotency key %v is lost", claimed.Key)
+
+					return
+
+				case err != nil:
+					s.logger.WithContext(ctx).Errorf("error occurred extending lease of idempotency key: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// Complete stores response to request made with claimed key, so it's replayed to retries of the request for ttl.
+// ErrLeaseLost is returned if lease of key expired and key was released or claimed by another request
+func (s *Service) Complete(ctx context.Context, claimed entity.IdempotencyKey, scriptID *int, status int, headers map[string]string, body string) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Complete")
+	defer span.End()
+
+	_, err := s.Repo.CompleteIdempotencyKey(ctx, entity.IdempotencyKey{
+		Scope:           claimed.Scope,
+		Key:             claimed.Key,
+		LeaseToken:      claimed.LeaseToken,
+		ScriptID:        scriptID,
+		ResponseStatus:  &status,
+		ResponseHeaders: headers,
+		ResponseBody:    &body,
+	}, s.ttl)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLeaseLost
+	}
+
+	return err
+}
+
+// Release releases claimed key of request that failed, so the request may be retried with it. Key is kept if lease
+// of it expired and key was claimed by another request
+func (s *Service) Release(ctx context.Context, claimed entity.IdempotencyKey) error {
+	ctx, span := tracing.Start(ctx, "idempotency.Service.Release")
+	defer span.End()
+
+	return s.Repo.DeleteIdempotencyKey(ctx, claimed.Scope, claimed.Key, *claimed.LeaseToken)
+}
+
+// DeleteExpiredKeysPeriodically deletes expired keys every interval until ctx is done, expired key is claimed anew
+// anyway, so it's only a cleanup
+func (s *Service) DeleteExpiredKeysPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			deleted, err := s.Repo.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				s.logger.Errorf("error occurred deleting expired idempotency keys: %v", err)
+
+				continue
+			}
+
+			if deleted != 0 {
+				s.logger.Debugf("%v expired idempotency keys deleted", deleted)
+			}
+		}
+	}
+}
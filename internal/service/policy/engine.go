@@ -0,0 +1,141 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pg-start-trainee-2024/domain/entity"
+)
+
+// commandSeparatorRegexp splits command into simple commands: lines, lists (';', '&&', '||', '&'), pipelines,
+// subshells and command substitutions
+var commandSeparatorRegexp = regexp.MustCompile("[\n;&|(){}`]|\\$\\(")
+
+const (
+	anyValue = "*"
+
+	// runAsNotAllowedRule denies running script as user not in allowlist before any configured rule is evaluated
+	runAsNotAllowedRule = "run-as-not-allowed"
+)
+
+type rule struct {
+	entity.PolicyRule
+
+	regex *regexp.Regexp
+}
+
+// Engine evaluates rules in order they are configured, the first matching rule decides
+type Engine struct {
+	rules         []rule
+	defaultAction entity.PolicyAction
+	runAsUsers    []string
+}
+
+func validAction(action entity.PolicyAction) bool {
+	return action == entity.PolicyActionAllow ||
+		action == entity.PolicyActionDeny ||
+		action == entity.PolicyActionRequireApproval
+}
+
+func New(defaultAction entity.PolicyAction, rules []entity.PolicyRule, runAsUsers []string) (*Engine, error) {
+	if !validAction(defaultAction) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAction, defaultAction)
+	}
+
+	engine := &Engine{
+		rules:         make([]rule, 0, len(rules)),
+		defaultAction: defaultAction,
+		runAsUsers:    runAsUsers,
+	}
+
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("%w: rule name is required", ErrInvalidRule)
+		}
+
+		if !validAction(r.Action) {
+			return nil, fmt.Errorf("%w: rule '%v': %w: %v", ErrInvalidRule, r.Name, ErrInvalidAction, r.Action)
+		}
+
+		compiled := rule{PolicyRule: r}
+
+		if r.CommandRegex != "" {
+			regex, err := regexp.Compile(r.CommandRegex)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule '%v': %v", ErrInvalidRule, r.Name, err)
+			}
+
+			compiled.regex = regex
+		}
+
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+func (r *rule) matchesCommand(command string) bool {
+	if r.regex != nil && !r.regex.MatchString(command) {
+		return false
+	}
+
+	if r.CommandPrefix == "" {
+		return true
+	}
+
+	// every simple command is checked, so prefix can't be bypassed by chaining command after another one
+	for _, simple := range commandSeparatorRegexp.Split(command, -1) {
+		if strings.HasPrefix(strings.TrimSpace(simple), r.CommandPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *rule) matchesEnv(env map[string]string) bool {
+	if len(r.EnvKeys) == 0 {
+		return true
+	}
+
+	for key := range env {
+		if slices.Contains(r.EnvKeys, anyValue) || slices.Contains(r.EnvKeys, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *rule) matchesRunAs(runAs string) bool {
+	if len(r.RunAs) == 0 {
+		return true
+	}
+
+	return slices.Contains(r.RunAs, runAs) || (runAs != "" && slices.Contains(r.RunAs, anyValue))
+}
+
+func (r *rule) matches(script *entity.Script) bool {
+	return r.matchesCommand(script.Command) &&
+		(len(r.Interpreters) == 0 || slices.Contains(r.Interpreters, script.Interpreter)) &&
+		r.matchesEnv(script.Env) &&
+		r.matchesRunAs(script.RunAs)
+}
+
+// Evaluate returns decision of the first rule script matches or default action, script run as user not in allowlist
+// is denied regardless of rules
+func (e *Engine) Evaluate(script entity.Script) entity.PolicyDecision {
+	if script.RunAs != "" && !slices.Contains(e.runAsUsers, script.RunAs) {
+		return entity.PolicyDecision{Action: entity.PolicyActionDeny, Rule: runAsNotAllowedRule}
+	}
+
+	for i := range e.rules {
+		if e.rules[i].matches(&script) {
+			return entity.PolicyDecision{Action: e.rules[i].Action, Rule: e.rules[i].Name}
+		}
+	}
+
+	return entity.PolicyDecision{Action: e.defaultAction}
+}
@@ -0,0 +1,8 @@
+package policy
+
+import "errors"
+
+var (
+	ErrInvalidAction = errors.New("invalid policy action")
+	ErrInvalidRule   = errors.New("invalid policy rule")
+)
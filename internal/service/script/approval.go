@@ -0,0 +1,151 @@
+package script
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// reviewer returns subject of caller, nil is returned for anonymous callers
+func reviewer(ctx context.Context) *string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return &principal.Subject
+	}
+
+	return nil
+}
+
+// getScriptForReview returns script pending approval caller may review
+func (s *Service) getScriptForReview(ctx context.Context, id int) (*entity.Script, error) {
+	script, err := s.GetScript(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = authorize(ctx, entity.OperationReviewScript, script); err != nil {
+		return nil, err
+	}
+
+	if script.Status != entity.ScriptStatusPendingApproval {
+		return nil, ErrNotPendingApproval
+	}
+
+	return script, nil
+}
+
+// ApproveScript starts (or queues for workers) script pending approval, script must be approved by someone other than its creator
+func (s *Service) ApproveScript(ctx context.Context, id int) (*entity.Script, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.ApproveScript")
+	defer span.End()
+
+	script, err := s.getScriptForReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewedBy := reviewer(ctx)
+
+	if reviewedBy != nil && script.CreatedBy != nil && *reviewedBy == *script.CreatedBy {
+		return nil, ErrSelfApproval
+	}
+
+	if script.ApprovalExpiresAt != nil && script.ApprovalExpiresAt.Before(time.Now().UTC()) {
+		return nil, ErrApprovalExpired
+	}
+
+	// workers matching script could leave while it waited for approval
+	if err = s.checkSchedulable(ctx, script.LabelSelector); err != nil {
+		return nil, err
+	}
+
+	status := entity.ScriptStatusRunning
+	if s.execution.Mode == ExecuteByWorkers {
+		status = entity.ScriptStatusQueued
+	}
+
+	approved, err := s.audited(ctx, entity.AuditActionApproveScript, nil, func(ctx context.Context) (*entity.Script, error) {
+		// script is already counted as run of the day when it was created
+		if err := s.checkQuota(ctx, script.Namespace, true, false); err != nil {
+			return nil, err
+		}
+
+		return s.Repo.ReviewScript(ctx, id, status, reviewedBy, nil)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// reviewed concurrently or expired just now
+		return nil, ErrNotPendingApproval
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if approved.Status != entity.ScriptStatusRunning {
+		return approved, nil
+	}
+
+	return s.run(ctx, approved), nil
+}
+
+// RejectScript rejects script pending approval, creator may reject (withdraw) own script
+func (s *Service) RejectScript(ctx context.Context, id int, reason string) error {
+	ctx, span := tracing.Start(ctx, "script.Service.RejectScript")
+	defer span.End()
+
+	if _, err := s.getScriptForReview(ctx, id); err != nil {
+		return err
+	}
+
+	payload := struct {
+		Reason string `json:"reason"`
+	}{reason}
+
+	_, err := s.audited(ctx, entity.AuditActionRejectScript, payload, func(ctx context.Context) (*entity.Script, error) {
+		return s.Repo.ReviewScript(ctx, id, entity.ScriptStatusRejected, reviewer(ctx), &reason)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotPendingApproval
+	}
+
+	return err
+}
+
+// ExpirePendingScripts marks scripts that are not reviewed in time as expired
+func (s *Service) ExpirePendingScripts(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "script.Service.ExpirePendingScripts")
+	defer span.End()
+
+	expired, err := s.Repo.ExpirePendingScripts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if expired != 0 {
+		s.logger.WithContext(ctx).Infof("%v scripts pending approval expired", expired)
+	}
+
+	return nil
+}
+
+// ExpirePendingScriptsPeriodically expires scripts every interval until ctx is done
+func (s *Service) ExpirePendingScriptsPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := s.ExpirePendingScripts(ctx); err != nil {
+				s.logger.Errorf("error occurred expiring scripts pending approval: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package script
+
+import (
+	"context"
+	"strconv"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/audit"
+)
+
+type AuditLog interface {
+	CreateAuditEvent(ctx context.Context, event entity.AuditEvent) (*entity.AuditEvent, error)
+}
+
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// scriptPayload is part of script audit event's payload hash is computed of
+type scriptPayload struct {
+	Command     string            `json:"command"`
+	Tags        []string          `json:"tags"`
+	Interpreter string            `json:"interpreter"`
+	Env         map[string]string `json:"env"`
+	RunAs       string            `json:"run_as"`
+}
+
+// audited runs fn changing script and writes audit event of action on this script in one transaction,
+// nothing is written if fn fails
+func (s *Service) audited(
+	ctx context.Context,
+	action entity.AuditAction,
+	payload any,
+	fn func(ctx context.Context) (*entity.Script, error),
+) (*entity.Script, error) {
+	var script *entity.Script
+
+	err := s.Transactor.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if script, err = fn(ctx); err != nil {
+			return err
+		}
+
+		event, err := audit.NewEvent(ctx, action, entity.AuditTargetScript, strconv.Itoa(script.ID), script.Namespace, payload)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Audit.CreateAuditEvent(ctx, event)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
@@ -0,0 +1,31 @@
+package script
+
+import (
+	"context"
+	"fmt"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+)
+
+// authorize checks that caller's role permits operation, script is nil for operations not bound to one script.
+// Anonymous callers are not restricted as there are none unless authentication is disabled
+func authorize(ctx context.Context, op entity.Operation, script *entity.Script) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	own := script != nil && script.CreatedBy != nil && *script.CreatedBy == principal.Subject
+
+	role := principal.Role()
+	if !role.Can(op, own) {
+		if role == "" {
+			return fmt.Errorf("%w: caller has no role", ErrForbidden)
+		}
+
+		return fmt.Errorf("%w: role %v may not %v this script", ErrForbidden, role, op)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package script
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// CreateScripts creates scripts one by one, as every script is started on its own, failure of one of them
+// doesn't affect the others
+func (s *Service) CreateScripts(ctx context.Context, scripts []entity.Script) []entity.BatchResult {
+	ctx, span := tracing.Start(ctx, "script.Service.CreateScripts")
+	defer span.End()
+
+	results := make([]entity.BatchResult, len(scripts))
+
+	for i, script := range scripts {
+		created, err := s.CreateScript(ctx, script)
+
+		results[i] = entity.BatchResult{Index: i, Script: created, Err: err}
+		if created != nil {
+			results[i].ID = created.ID
+		}
+	}
+
+	return results
+}
+
+// StopScripts stops scripts one by one, failure to stop one of them doesn't affect the others
+func (s *Service) StopScripts(ctx context.Context, ids []int) []entity.BatchResult {
+	ctx, span := tracing.Start(ctx, "script.Service.StopScripts")
+	defer span.End()
+
+	results := make([]entity.BatchResult, len(ids))
+
+	for i, id := range ids {
+		results[i] = entity.BatchResult{Index: i, ID: id, Err: s.StopScript(ctx, id)}
+	}
+
+	return results
+}
+
+// isItemError reports whether err concerns one item of batch, such errors don't abort transaction of batch
+func isItemError(err error) bool {
+	return errors.Is(err, ErrNoSuchScript) || errors.Is(err, ErrForbidden)
+}
+
+// DeleteScripts deletes scripts in one transaction: scripts that can't be deleted (don't exist, access is denied)
+// are reported and skipped, but failure of database rolls back the whole batch and is returned.
+// Processes of deleted scripts are cancelled once deletion is committed
+func (s *Service) DeleteScripts(ctx context.Context, ids []int) ([]entity.BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.DeleteScripts")
+	defer span.End()
+
+	results := make([]entity.BatchResult, len(ids))
+
+	err := s.Transactor.InTx(ctx, func(ctx context.Context) error {
+		for i, id := range ids {
+			results[i] = entity.BatchResult{Index: i, ID: id}
+
+			script, err := s.GetScript(ctx, id)
+			if err == nil {
+				err = authorize(ctx, entity.OperationDeleteScript, script)
+			}
+
+			if err == nil {
+				_, err = s.audited(ctx, entity.AuditActionDeleteScript, nil, func(ctx context.Context) (*entity.Script, error) {
+					return s.Repo.DeleteScript(ctx, script.Namespace, id)
+				})
+			}
+
+			// script deleted concurrently
+			if errors.Is(err, sql.ErrNoRows) {
+				err = ErrNoSuchScript
+			}
+
+			if isItemError(err) {
+				results[i].Err = err
+
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Err == nil {
+			s.cancelLocalRun(result.ID)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package script
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/logging"
+)
+
+// WatchScriptChanges keeps processes run here consistent with changes made by other replicas until ctx is done
+// or changes are closed: process of script deleted or finished elsewhere or which stop is requested is cancelled.
+// As changes may have been missed before resync, every script run here is checked against db on it
+func (s *Service) WatchScriptChanges(ctx context.Context, changes <-chan entity.ScriptChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+
+			if change.Op == entity.ScriptChangeResync {
+				s.resyncLocalRuns(ctx)
+
+				continue
+			}
+
+			if change.Op == entity.ScriptChangeDeleted || change.Op == entity.ScriptChangeFinished || change.StopRequested {
+				s.cancelLocalRun(change.ID)
+			}
+		}
+	}
+}
+
+// cancelLocalRun cancels process of script if it's run here, cancelling already finished process is no-op
+func (s *Service) cancelLocalRun(id int) {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+
+	if cmdContextAny, exist := s.Cache.Get(strconv.Itoa(id)); exist {
+		if cmdContext, ok := cmdContextAny.(entity.CmdContext); ok {
+			cmdContext.Cancel()
+		}
+	}
+}
+
+// resyncLocalRuns cancels processes run here of scripts deleted, finished or which stop is requested according to db
+func (s *Service) resyncLocalRuns(ctx context.Context) {
+	s.cacheMutex.RLock()
+
+	ids := make([]int, 0, s.Cache.ItemCount())
+	for key := range s.Cache.Items() {
+		if id, err := strconv.Atoi(key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	s.cacheMutex.RUnlock()
+
+	for _, id := range ids {
+		script, err := s.Repo.GetScript(ctx, "", id)
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			s.cancelLocalRun(id)
+
+		case err != nil:
+			logging.ScriptEntry(ctx, s.logger, id).Errorf("error occurred resyncing script run here: %v", err)
+
+		case script.FinishedAt != nil || script.StopRequestedAt != nil:
+			s.cancelLocalRun(id)
+		}
+	}
+}
@@ -7,4 +7,26 @@ var (
 	ErrCannotCastToCancelFunc = errors.New("cannot cast cache value to context.CancelFunc")
 
 	ErrNoSuchScript = errors.New("no such script")
+
+	ErrForbidden = errors.New("operation is forbidden")
+
+	ErrCommandDenied      = errors.New("command is denied by policy")
+	ErrUnknownInterpreter = errors.New("unknown interpreter")
+	ErrInvalidEnv         = errors.New("invalid env variable name")
+	ErrInvalidLabel       = errors.New("invalid label")
+	ErrInvalidCallbackURL = errors.New("invalid callback url")
+
+	ErrUnschedulable = errors.New("script is unschedulable")
+
+	ErrNotPendingApproval = errors.New("script is not pending approval")
+	ErrApprovalExpired    = errors.New("script approval has expired")
+	ErrSelfApproval       = errors.New("script can't be approved by its creator")
+
+	ErrQuotaExceeded = errors.New("namespace quota exceeded")
+
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+	ErrInvalidSearchMode  = errors.New("invalid search mode")
+	ErrSearchTimeout      = errors.New("search took too long, narrow down query or time range")
+
+	ErrUnsupportedCursorSort = errors.New("cursor pagination supports only sorting by created_at")
 )
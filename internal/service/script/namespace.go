@@ -0,0 +1,59 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+)
+
+// scopeNamespace returns namespace caller may access, empty namespace means every namespace
+// and is returned for anonymous callers and principals not bound to a namespace
+func scopeNamespace(ctx context.Context) string {
+	return auth.Namespace(ctx)
+}
+
+// createNamespace returns namespace caller's scripts are created in
+func createNamespace(ctx context.Context) string {
+	if namespace := scopeNamespace(ctx); namespace != "" {
+		return namespace
+	}
+
+	return entity.DefaultNamespace
+}
+
+// checkQuota returns ErrQuotaExceeded if namespace's quota doesn't allow one more script. Running scripts limit
+// is checked if script is about to start, runs per day limit is checked if script is about to be created.
+// It must be called in transaction changing usage of namespace, quota stays locked until the transaction ends,
+// so concurrent changes on any replica can't exceed quota together
+func (s *Service) checkQuota(ctx context.Context, namespace string, starts, creates bool) error {
+	quota := s.Quotas.For(namespace)
+	if quota == (entity.Quota{}) {
+		return nil
+	}
+
+	if err := s.Repo.LockNamespaceQuota(ctx, namespace); err != nil {
+		return err
+	}
+
+	usage, err := s.Repo.GetNamespaceUsage(ctx, namespace, time.Now().UTC().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	if starts && quota.MaxConcurrentScripts != 0 && usage.RunningScripts >= quota.MaxConcurrentScripts {
+		return fmt.Errorf("%w: namespace %v already runs %v scripts", ErrQuotaExceeded, namespace, usage.RunningScripts)
+	}
+
+	if creates && quota.MaxRunsPerDay != 0 && usage.RunsPerDay >= quota.MaxRunsPerDay {
+		return fmt.Errorf("%w: namespace %v created %v scripts during last day", ErrQuotaExceeded, namespace, usage.RunsPerDay)
+	}
+
+	if quota.MaxOutputBytes != 0 && usage.OutputBytes >= quota.MaxOutputBytes {
+		return fmt.Errorf("%w: namespace %v stores %v bytes of output", ErrQuotaExceeded, namespace, usage.OutputBytes)
+	}
+
+	return nil
+}
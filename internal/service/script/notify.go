@@ -0,0 +1,27 @@
+package script
+
+import (
+	"context"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/logging"
+)
+
+// finishEvent returns event of script finished with status
+func finishEvent(status entity.ScriptStatus) entity.ScriptEvent {
+	switch status {
+	case entity.ScriptStatusFailed, entity.ScriptStatusLost:
+		return entity.ScriptEventFailed
+	case entity.ScriptStatusStopped:
+		return entity.ScriptEventStopped
+	default:
+		return entity.ScriptEventFinished
+	}
+}
+
+// notify passes event to notifier, script is not affected if notifier fails, so error is only logged
+func (s *Service) notify(ctx context.Context, event entity.ScriptEvent, script *entity.Script) {
+	if err := s.Notifier.NotifyScriptEvent(ctx, event, script); err != nil {
+		logging.ScriptEntry(ctx, s.logger, script.ID).Errorf("error occurred notifying of %v: %v", event, err)
+	}
+}
@@ -0,0 +1,52 @@
+package script
+
+import (
+	"context"
+	"time"
+
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// outputPollInterval is how often output of watched script is checked
+const outputPollInterval = 250 * time.Millisecond
+
+// WatchOutput passes output of script to send from the beginning as it's appended until script finishes, ctx is done
+// or send fails. Output is checked once more after script finishes, as the rest of it is stored right after
+func (s *Service) WatchOutput(ctx context.Context, id int, send func(output string) error) error {
+	ctx, span := tracing.Start(ctx, "script.Service.WatchOutput")
+	defer span.End()
+
+	ticker := time.NewTicker(outputPollInterval)
+	defer ticker.Stop()
+
+	sent := 0
+	finished := false
+
+	for {
+		script, err := s.GetScript(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(script.Output) > sent {
+			if err = send(script.Output[sent:]); err != nil {
+				return err
+			}
+
+			sent = len(script.Output)
+		}
+
+		if finished {
+			return nil
+		}
+
+		finished = script.FinishedAt != nil
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
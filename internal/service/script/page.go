@@ -0,0 +1,87 @@
+package script
+
+import (
+	"context"
+	"math"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+func scriptCursor(script *entity.Script, backward bool) *entity.Cursor {
+	return &entity.Cursor{
+		CreatedAt: script.CreatedAt,
+		ID:        script.ID,
+		Backward:  backward,
+	}
+}
+
+// GetScriptsPage returns page of scripts located after cursor (or before it for backward cursor),
+// nil cursor means the first page. Unlike offset pagination pages are not shifted by concurrently created scripts
+func (s *Service) GetScriptsPage(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) (*entity.ScriptsPage, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.GetScriptsPage")
+	defer span.End()
+
+	if err := authorize(ctx, entity.OperationReadScript, nil); err != nil {
+		return nil, err
+	}
+
+	if namespace := scopeNamespace(ctx); namespace != "" {
+		filter.Namespace = namespace
+	}
+
+	if filter.SortBy != "" && filter.SortBy != entity.ScriptSortByCreatedAt {
+		return nil, ErrUnsupportedCursorSort
+	}
+
+	// one extra script is fetched to know whether there is one more page in walking direction
+	fetch := limit
+	if limit != math.MaxInt64 {
+		fetch++
+	}
+
+	scripts, err := s.Repo.GetScriptsByCursor(ctx, filter, cursor, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(scripts) > limit
+
+	if hasMore {
+		if backward {
+			scripts = scripts[1:]
+		} else {
+			scripts = scripts[:limit]
+		}
+	}
+
+	page := &entity.ScriptsPage{Scripts: scripts}
+
+	if len(scripts) == 0 {
+		return page, nil
+	}
+
+	first, last := scripts[0], scripts[len(scripts)-1]
+
+	if backward {
+		// we came here from the page after this one
+		page.NextCursor = scriptCursor(last, false)
+
+		if hasMore {
+			page.PrevCursor = scriptCursor(first, true)
+		}
+
+		return page, nil
+	}
+
+	if hasMore {
+		page.NextCursor = scriptCursor(last, false)
+	}
+
+	if cursor != nil {
+		page.PrevCursor = scriptCursor(first, true)
+	}
+
+	return page, nil
+}
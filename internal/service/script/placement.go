@@ -0,0 +1,58 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+var labelRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// ParseLabels parses labels written as key=value or just key, label written as key has empty value
+func ParseLabels(labels []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(labels))
+
+	for _, label := range labels {
+		key, value, _ := strings.Cut(label, "=")
+
+		if !labelRegexp.MatchString(key) || (value != "" && !labelRegexp.MatchString(value)) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLabel, label)
+		}
+
+		parsed[key] = value
+	}
+
+	return parsed, nil
+}
+
+// checkSchedulable checks that script with label selector can be placed: any script can be run locally
+// unless it has label selector, script run by workers needs live worker having all labels of selector
+func (s *Service) checkSchedulable(ctx context.Context, selector dbutils.StringMap) error {
+	for key, value := range selector {
+		if !labelRegexp.MatchString(key) || (value != "" && !labelRegexp.MatchString(value)) {
+			return fmt.Errorf("%w: %v=%v", ErrInvalidLabel, key, value)
+		}
+	}
+
+	if s.execution.Mode != ExecuteByWorkers {
+		if len(selector) != 0 {
+			return fmt.Errorf("%w: scripts are not run by workers", ErrUnschedulable)
+		}
+
+		return nil
+	}
+
+	matching, err := s.Repo.CountMatchingWorkers(ctx, selector, s.execution.WorkerLostAfter)
+	if err != nil {
+		return err
+	}
+
+	if matching == 0 {
+		return fmt.Errorf("%w: no live worker matches label selector", ErrUnschedulable)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+
+	osutils "pg-start-trainee-2024/pkg/utils/os"
+)
+
+const defaultInterpreter = "sh"
+
+// interpreters maps interpreter names clients may request to their paths
+var interpreters = map[string]string{
+	"sh":   "/bin/sh",
+	"bash": "/bin/bash",
+}
+
+var envKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedEnvKeys can't be set by clients, as they change what interpreter loads or runs instead of the command
+var (
+	reservedEnvKeys = []string{
+		"PATH", "IFS", "ENV", "BASH_ENV", "SHELLOPTS", "BASHOPTS", "PS4", "PROMPT_COMMAND", "CDPATH", "GLOBIGNORE",
+	}
+	reservedEnvKeyPrefixes = []string{"LD_", "DYLD_", "BASH_FUNC_"}
+)
+
+func reservedEnvKey(key string) bool {
+	if slices.Contains(reservedEnvKeys, key) {
+		return true
+	}
+
+	return slices.ContainsFunc(reservedEnvKeyPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+type Policy interface {
+	Evaluate(script entity.Script) entity.PolicyDecision
+}
+
+// prepareRunOptions sets default interpreter and checks that run options are valid
+func prepareRunOptions(script *entity.Script) error {
+	if script.Interpreter == "" {
+		script.Interpreter = defaultInterpreter
+	}
+
+	if _, ok := interpreters[script.Interpreter]; !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownInterpreter, script.Interpreter)
+	}
+
+	for key := range script.Env {
+		if !envKeyRegexp.MatchString(key) {
+			return fmt.Errorf("%w: %v", ErrInvalidEnv, key)
+		}
+
+		if reservedEnvKey(key) {
+			return fmt.Errorf("%w: %v is reserved", ErrInvalidEnv, key)
+		}
+	}
+
+	return nil
+}
+
+func commandOptions(script entity.Script) osutils.CommandOptions {
+	env := make([]string, 0, len(script.Env))
+	for key, value := range script.Env {
+		env = append(env, fmt.Sprintf("%v=%v", key, value))
+	}
+
+	sort.Strings(env)
+
+	return osutils.CommandOptions{
+		Interpreter: interpreters[script.Interpreter],
+		Env:         env,
+		RunAs:       script.RunAs,
+	}
+}
+
+// checkPolicy returns error if policy denies script, pendingApproval is true if script must be approved to run,
+// matched rule is recorded on script
+func (s *Service) checkPolicy(script *entity.Script) (pendingApproval bool, err error) {
+	decision := s.Policy.Evaluate(*script)
+
+	if decision.Rule != "" {
+		script.PolicyRule = &decision.Rule
+	}
+
+	switch decision.Action {
+	case entity.PolicyActionAllow:
+		return false, nil
+
+	case entity.PolicyActionRequireApproval:
+		return true, nil
+
+	default:
+		if decision.Rule == "" {
+			return false, fmt.Errorf("%w: no rule allows it", ErrCommandDenied)
+		}
+
+		return false, fmt.Errorf("%w: rule '%v'", ErrCommandDenied, decision.Rule)
+	}
+}
+
+// EvaluatePolicy returns decision policy would make on script creation without creating it
+func (s *Service) EvaluatePolicy(ctx context.Context, script entity.Script) (*entity.PolicyDecision, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.EvaluatePolicy")
+	defer span.End()
+
+	if err := authorize(ctx, entity.OperationReadScript, nil); err != nil {
+		return nil, err
+	}
+
+	if err := prepareRunOptions(&script); err != nil {
+		return nil, err
+	}
+
+	decision := s.Policy.Evaluate(script)
+
+	return &decision, nil
+}
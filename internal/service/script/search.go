@@ -0,0 +1,217 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+const (
+	maxSnippetLength = 200
+
+	highlightOpen  = "<b>"
+	highlightClose = "</b>"
+
+	// invalid_regular_expression and query_canceled, search is cancelled by statement timeout
+	pgInvalidRegularExpression = "2201B"
+	pgQueryCanceled            = "57014"
+)
+
+var (
+	// words of output are split the way postgres parser splits them, so they are highlighted where full-text search found them
+	ftsWordRegexp = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+	// output is matched line by line, so only leading embedded options not changing newline sensitivity are allowed
+	regexOptionsRegexp  = regexp.MustCompile(`^\(\?([a-zA-Z]+)\)`)
+	regexAllowedOptions = "im"
+)
+
+// ftsWords returns positive words of websearch_to_tsquery-like query lowercased, lines containing any of them are matched
+func ftsWords(query string) ([]string, error) {
+	words := make([]string, 0)
+
+	for _, field := range strings.Fields(query) {
+		// negated words do not appear in matched output
+		if strings.HasPrefix(field, "-") || strings.EqualFold(field, "or") {
+			continue
+		}
+
+		for _, word := range ftsWordRegexp.FindAllString(field, -1) {
+			if word = strings.ToLower(word); !slices.Contains(words, word) {
+				words = append(words, word)
+			}
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	return words, nil
+}
+
+// wordRanges returns byte ranges of words of line
+func wordRanges(line string, words []string) [][]int {
+	ranges := make([][]int, 0)
+
+	for _, loc := range ftsWordRegexp.FindAllStringIndex(line, -1) {
+		if slices.Contains(words, strings.ToLower(line[loc[0]:loc[1]])) {
+			ranges = append(ranges, loc)
+		}
+	}
+
+	return ranges
+}
+
+// byteRanges converts character ranges of matches found by postgres to byte ranges of line
+func byteRanges(line string, ranges [][2]int) [][]int {
+	offsets := make([]int, 0, len(line)+1)
+	for i := range line {
+		offsets = append(offsets, i)
+	}
+
+	offsets = append(offsets, len(line))
+
+	converted := make([][]int, 0, len(ranges))
+
+	for _, r := range ranges {
+		if r[0] < 0 || r[0] > r[1] || r[1] >= len(offsets) {
+			continue
+		}
+
+		converted = append(converted, []int{offsets[r[0]], offsets[r[1]]})
+	}
+
+	return converted
+}
+
+// highlight wraps every match in line with highlight tags and cuts line to maxSnippetLength around first match
+func highlight(line string, matches [][]int) string {
+	start := 0
+	end := len(line)
+
+	if len(line) > maxSnippetLength {
+		start = max(0, matches[0][0]-maxSnippetLength/4)
+		end = min(len(line), start+maxSnippetLength)
+	}
+
+	var sb strings.Builder
+
+	if start > 0 {
+		sb.WriteString("...")
+	}
+
+	pos := start
+
+	for _, m := range matches {
+		if m[0] < pos || m[1] > end || m[0] == m[1] {
+			continue
+		}
+
+		// output is arbitrary text, so it is escaped to make highlight tags the only markup of snippet
+		sb.WriteString(html.EscapeString(line[pos:m[0]]))
+		sb.WriteString(highlightOpen)
+		sb.WriteString(html.EscapeString(line[m[0]:m[1]]))
+		sb.WriteString(highlightClose)
+
+		pos = m[1]
+	}
+
+	sb.WriteString(html.EscapeString(line[pos:end]))
+
+	if end < len(line) {
+		sb.WriteString("...")
+	}
+
+	// cutting line could break multibyte characters
+	return strings.ToValidUTF8(sb.String(), "")
+}
+
+func outputMatches(search entity.ScriptSearch, lines []entity.MatchedLine) []entity.OutputMatch {
+	matches := make([]entity.OutputMatch, 0, len(lines))
+
+	for _, line := range lines {
+		ranges := byteRanges(line.Text, line.Ranges)
+		if search.Mode != entity.SearchModeRegex {
+			ranges = wordRanges(line.Text, search.Words)
+		}
+
+		if len(ranges) == 0 {
+			continue
+		}
+
+		matches = append(matches, entity.OutputMatch{
+			Line:    line.Number,
+			Snippet: highlight(line.Text, ranges),
+		})
+	}
+
+	return matches
+}
+
+// SearchScripts searches through output of all scripts either with postgres full-text search or with postgres regexp
+func (s *Service) SearchScripts(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchResult, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.SearchScripts")
+	defer span.End()
+
+	if err := authorize(ctx, entity.OperationReadScript, nil); err != nil {
+		return nil, err
+	}
+
+	if namespace := scopeNamespace(ctx); namespace != "" {
+		search.Namespace = namespace
+	}
+
+	switch search.Mode {
+	case entity.SearchModeRegex:
+		if options := regexOptionsRegexp.FindStringSubmatch(search.Query); options != nil {
+			if strings.Trim(options[1], regexAllowedOptions) != "" {
+				return nil, fmt.Errorf("%w: only %q embedded options are supported", ErrInvalidSearchQuery, regexAllowedOptions)
+			}
+		}
+
+	case entity.SearchModeFTS, "":
+		words, err := ftsWords(search.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		search.Words = words
+	default:
+		return nil, ErrInvalidSearchMode
+	}
+
+	hits, err := s.Repo.SearchScriptsOutput(ctx, search, offset, limit)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgInvalidRegularExpression {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSearchQuery, pgErr.Message)
+		}
+
+		if errors.As(err, &pgErr) && pgErr.Code == pgQueryCanceled {
+			return nil, ErrSearchTimeout
+		}
+
+		return nil, err
+	}
+
+	results := make([]*entity.ScriptSearchResult, 0, len(hits))
+
+	for _, hit := range hits {
+		results = append(results, &entity.ScriptSearchResult{
+			Script:  hit.Script,
+			Matches: outputMatches(search, hit.Lines),
+		})
+	}
+
+	return results, nil
+}
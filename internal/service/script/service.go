@@ -2,52 +2,132 @@ package script
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strconv"
 	"sync"
 	"time"
 
+	gocache "github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"pg-start-trainee-2024/internal/pkg/metrics"
+	"pg-start-trainee-2024/internal/pkg/tracing"
 
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
 	osutils "pg-start-trainee-2024/pkg/utils/os"
 )
 
 type Repo interface {
 	CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error)
 	UpdateScriptOutput(ctx context.Context, id int, output string) (*entity.Script, error)
-	DeleteScript(ctx context.Context, id int) (*entity.Script, error)
+	DeleteScript(ctx context.Context, namespace string, id int) (*entity.Script, error)
 	UpdateScriptPIDAndRunningState(ctx context.Context, id, pid int, isRunning bool) (*entity.Script, error)
 	UpdateScriptRunningState(ctx context.Context, id int, isRunning bool) (*entity.Script, error)
-	GetScript(ctx context.Context, id int) (*entity.Script, error)
-	GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error)
+	FinishScript(ctx context.Context, id int, status entity.ScriptStatus, exitCode *int) (*entity.Script, error)
+	GetScript(ctx context.Context, namespace string, id int) (*entity.Script, error)
+	GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error)
+	GetScriptsByCursor(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) ([]*entity.Script, error)
+	SearchScriptsOutput(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchHit, error)
+	ReviewScript(ctx context.Context, id int, status entity.ScriptStatus, reviewedBy, rejectReason *string) (*entity.Script, error)
+	ExpirePendingScripts(ctx context.Context) (int64, error)
+	GetNamespaceUsage(ctx context.Context, namespace string, since time.Time) (*entity.NamespaceUsage, error)
+	LockNamespaceQuota(ctx context.Context, namespace string) error
+	RequestScriptStop(ctx context.Context, id int) (*entity.Script, error)
+	RegisterWorker(ctx context.Context, worker entity.Worker) (*entity.Worker, error)
+	HeartbeatWorker(ctx context.Context, id string) error
+	DeregisterWorker(ctx context.Context, id string) error
+	ClaimQueuedScripts(ctx context.Context, workerID string, limit int) ([]*entity.Script, error)
+	GetStopRequestedScripts(ctx context.Context, workerID string) ([]*entity.Script, error)
+	MarkLostScripts(ctx context.Context, lostAfter time.Duration) ([]*entity.Script, error)
+	CountMatchingWorkers(ctx context.Context, selector dbutils.StringMap, aliveWithin time.Duration) (int, error)
 }
 
 type Cache interface {
 	Set(key string, value any, duration time.Duration)
 	Get(key string) (any, bool)
 	Delete(key string)
+	ItemCount() int
+	Items() map[string]gocache.Item
+}
+
+// Notifier is notified of lifecycle events of scripts, e.g. to deliver webhooks
+type Notifier interface {
+	NotifyScriptEvent(ctx context.Context, event entity.ScriptEvent, script *entity.Script) error
+	// CheckCallbackURL checks that events of script may be delivered to url
+	CheckCallbackURL(ctx context.Context, url string) error
+}
+
+// ExecutionMode tells where created scripts are run
+type ExecutionMode string
+
+const (
+	// ExecuteLocally runs scripts on replica they are created on
+	ExecuteLocally ExecutionMode = "local"
+	// ExecuteByWorkers queues scripts, so they are claimed and run by workers
+	ExecuteByWorkers ExecutionMode = "workers"
+)
+
+// ExecutionOptions tell where scripts are run, workers which haven't heartbeated for WorkerLostAfter
+// are not considered when scripts are placed
+type ExecutionOptions struct {
+	Mode            ExecutionMode
+	WorkerLostAfter time.Duration
 }
 
 type Service struct {
 	Repo Repo
 
+	// Audit is written in one transaction with changes of scripts
+	Audit      AuditLog
+	Transactor Transactor
+
 	cacheMutex *sync.RWMutex
 	Cache      Cache
 
+	Policy   Policy
+	Notifier Notifier
+
+	Quotas entity.Quotas
+
 	logger             *logrus.Logger
+	execution          ExecutionOptions
 	outputBufferLength int
+	approvalTTL        time.Duration
 }
 
-func New(repo Repo, cache Cache, outputBufferLength int) *Service {
+func New(
+	repo Repo,
+	audit AuditLog,
+	transactor Transactor,
+	cache Cache,
+	policy Policy,
+	notifier Notifier,
+	quotas entity.Quotas,
+	logger *logrus.Logger,
+	execution ExecutionOptions,
+	outputBufferLength int,
+	approvalTTL time.Duration,
+) *Service {
 	return &Service{
 		Repo:               repo,
+		Audit:              audit,
+		Transactor:         transactor,
 		cacheMutex:         &sync.RWMutex{},
 		Cache:              cache,
-		logger:             logrus.New(),
+		Policy:             policy,
+		Notifier:           notifier,
+		Quotas:             quotas,
+		logger:             logger,
+		execution:          execution,
 		outputBufferLength: outputBufferLength,
+		approvalTTL:        approvalTTL,
 	}
 }
 
@@ -62,49 +142,159 @@ func (s *Service) updateScriptOutputWithStrings(ctx context.Context, id int, str
 
 func (s *Service) outCallback(ctx context.Context, n int, id int) func(chan string) {
 	return func(outChan chan string) {
-		strs := make([]string, n)
+		strs := make([]string, 0, n)
+		outputBytes := 0
+
+		defer func() { metrics.ScriptOutputBytes.Observe(float64(outputBytes)) }()
 
-		i := 0
 		for str := range outChan {
-			if i < n {
-				strs[i] = fmt.Sprintf("%v\n", str)
-				i++
-			} else {
-				// update script and 'clear' strs
-				i = 0
+			strs = append(strs, fmt.Sprintf("%v\n", str))
+			outputBytes += len(str) + 1
 
+			if len(strs) == n {
+				// update script and clear strs
 				_, err := s.updateScriptOutputWithStrings(ctx, id, strs...)
 				if err != nil {
-					s.logger.Errorf("error occurred udating script's output: %v", err)
+					logging.ScriptEntry(ctx, s.logger, id).Errorf("error occurred udating script's output: %v", err)
+
+					// output is read to the end anyway, so script doesn't block writing it
+					for range outChan {
+					}
 
 					return
 				}
+
+				strs = strs[:0]
 			}
 		}
 
+		if len(strs) == 0 {
+			return
+		}
+
 		// chan is closed => update script with stored output in strs
 		_, err := s.updateScriptOutputWithStrings(ctx, id, strs...)
 		if err != nil {
-			s.logger.Errorf("error occurred udating script's output: %v", err)
+			logging.ScriptEntry(ctx, s.logger, id).Errorf("error occurred udating script's output: %v", err)
 
 			return
 		}
 	}
 }
 
-// CreateScript creates and runs new script
+// runResult converts error returned by osutils.RunCommand to script's final status and exit code
+func runResult(cmdCtx context.Context, runErr error) (entity.ScriptStatus, *int) {
+	if cmdCtx.Err() != nil || errors.Is(runErr, osutils.ErrContextCancelled) {
+		return entity.ScriptStatusStopped, nil
+	}
+
+	if runErr == nil {
+		exitCode := 0
+
+		return entity.ScriptStatusFinished, &exitCode
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		exitCode := exitErr.ExitCode()
+
+		return entity.ScriptStatusFailed, &exitCode
+	}
+
+	// script was not even started
+	return entity.ScriptStatusFailed, nil
+}
+
+// CreateScript creates and runs new script in caller's namespace, script policy requires approval for is stored without running,
+// script is queued for workers instead of running here if service executes scripts by workers
 func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error) {
-	pidChan := make(chan int, 1)
-	cmdChan := make(chan *exec.Cmd, 1)
+	ctx, span := tracing.Start(ctx, "script.Service.CreateScript")
+	defer span.End()
+
+	if err := authorize(ctx, entity.OperationCreateScript, nil); err != nil {
+		return nil, err
+	}
 
-	scpt, err := s.Repo.CreateScript(ctx, script)
+	if err := prepareRunOptions(&script); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSchedulable(ctx, script.LabelSelector); err != nil {
+		return nil, err
+	}
+
+	if script.CallbackURL != nil {
+		if err := s.Notifier.CheckCallbackURL(ctx, *script.CallbackURL); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCallbackURL, err)
+		}
+	}
+
+	// policy is checked before anything is persisted
+	pendingApproval, err := s.checkPolicy(&script)
+	if err != nil {
+		return nil, err
+	}
+
+	// record who script is created by
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		script.APIKeyID = principal.APIKeyID
+		script.CreatedBy = &principal.Subject
+	}
+
+	script.Namespace = createNamespace(ctx)
+
+	if pendingApproval {
+		expiresAt := time.Now().UTC().Add(s.approvalTTL)
+
+		script.Status = entity.ScriptStatusPendingApproval
+		script.ApprovalExpiresAt = &expiresAt
+	} else if s.execution.Mode == ExecuteByWorkers {
+		script.Status = entity.ScriptStatusQueued
+	}
+
+	payload := scriptPayload{
+		Command:     script.Command,
+		Tags:        script.Tags,
+		Interpreter: script.Interpreter,
+		Env:         script.Env,
+		RunAs:       script.RunAs,
+	}
+
+	scpt, err := s.audited(ctx, entity.AuditActionCreateScript, payload, func(ctx context.Context) (*entity.Script, error) {
+		if err := s.checkQuota(ctx, script.Namespace, !pendingApproval, true); err != nil {
+			return nil, err
+		}
+
+		return s.Repo.CreateScript(ctx, script)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	metrics.ScriptsCreated.WithLabelValues(string(scpt.Status)).Inc()
+
+	if scpt.Status != entity.ScriptStatusRunning {
+		return scpt, nil
+	}
+
+	return s.run(ctx, scpt), nil
+}
+
+// run starts already persisted script and returns as soon as it's started
+func (s *Service) run(ctx context.Context, scpt *entity.Script) *entity.Script {
+	pidChan := make(chan int, 1)
+	cmdChan := make(chan *exec.Cmd, 1)
+
+	// span of script execution outlives request, script continues trace with TRACEPARENT env variable
+	runCtx, span := tracing.Start(ctx, "script.Service.run", attribute.Int("script.id", scpt.ID))
+
+	command, opts := scpt.Command, commandOptions(*scpt)
+	opts.Env = append(opts.Env, tracing.Env(runCtx)...)
+
 	scptMutex := &sync.RWMutex{}
 
-	cmdCtx, cancel := context.WithCancel(context.Background())
+	// script outlives request, but keeps its values, so output updates and logs refer to request and trace
+	cmdCtx, cancel := context.WithCancel(context.WithoutCancel(runCtx))
 
 	wg := sync.WaitGroup{}
 
@@ -113,18 +303,24 @@ func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*enti
 	go func() {
 		defer wg.Done()
 
-		for {
-			if pid := <-pidChan; pid != 0 {
-				// just as we captured pid => we can update script's PID
-				var updateErr error
+		// chan is closed without pid if script was not started
+		for pid := range pidChan {
+			if pid != 0 {
+				logger := logging.ScriptEntry(ctx, s.logger, scpt.ID).WithField(logging.FieldPID, pid)
+				logger.Info("script started")
 
+				// just as we captured pid => we can update script's PID
 				scptMutex.Lock()
 
-				scpt, updateErr = s.Repo.UpdateScriptPIDAndRunningState(ctx, scpt.ID, pid, true)
+				updated, updateErr := s.Repo.UpdateScriptPIDAndRunningState(ctx, scpt.ID, pid, true)
 				if updateErr != nil {
-					s.logger.Errorf("error occurred updating script's PID and running state: %v", err)
+					logger.Errorf("error occurred updating script's PID and running state: %v", updateErr)
+				} else {
+					scpt = updated
 				}
 
+				s.notify(ctx, entity.ScriptEventStarted, scpt)
+
 				scptMutex.Unlock()
 
 				break
@@ -132,6 +328,12 @@ func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*enti
 		}
 	}()
 
+	// script is cached before it's run, as goroutine running it removes it from cache once it finishes,
+	// so finished script never stays cached and counted as running
+	s.cacheMutex.Lock()
+	s.Cache.Set(strconv.Itoa(scpt.ID), entity.CmdContext{Cancel: cancel}, -1)
+	s.cacheMutex.Unlock()
+
 	var cmd *exec.Cmd
 
 	wg.Add(1)
@@ -139,8 +341,8 @@ func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*enti
 	go func() {
 		defer wg.Done()
 
-		for {
-			if c := <-cmdChan; c != nil {
+		for c := range cmdChan {
+			if c != nil {
 				cmd = c
 
 				break
@@ -149,22 +351,38 @@ func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*enti
 	}()
 
 	go func() {
+		start := time.Now()
+
 		runErr := osutils.RunCommand(
 			cmdCtx,
-			script.Command,
+			command,
+			opts,
 			pidChan,
 			cmdChan,
 			s.outCallback(cmdCtx, s.outputBufferLength, scpt.ID),
 		)
 
 		if runErr != nil {
-			s.logger.Errorf("error occurred running script: %v", runErr)
+			logging.ScriptEntry(ctx, s.logger, scpt.ID).Errorf("error occurred running script: %v", runErr)
 		}
 
-		// script execution not started or stopped: whether it's cancelled or not -> update is_running to false
+		// script execution not started, finished or stopped -> update is_running to false and save its result
+		status, exitCode := runResult(cmdCtx, runErr)
+
+		logging.ScriptEntry(ctx, s.logger, scpt.ID).WithField("status", status).Info("script finished")
+
+		span.SetAttributes(attribute.String("script.status", string(status)))
+		span.End()
+
+		metrics.ScriptsFinished.WithLabelValues(string(status)).Inc()
+		metrics.ScriptRunDuration.Observe(time.Since(start).Seconds())
+
 		scptMutex.RLock()
-		if _, updateErr := s.Repo.UpdateScriptRunningState(context.Background(), scpt.ID, false); updateErr != nil {
-			s.logger.Errorf("error occurred updating script is_running column: %v", err)
+		finished, updateErr := s.Repo.FinishScript(context.WithoutCancel(runCtx), scpt.ID, status, exitCode)
+		if updateErr != nil {
+			logging.ScriptEntry(ctx, s.logger, scpt.ID).Errorf("error occurred updating script's status: %v", updateErr)
+		} else {
+			s.notify(context.WithoutCancel(runCtx), finishEvent(finished.Status), finished)
 		}
 		scptMutex.RUnlock()
 
@@ -177,22 +395,63 @@ func (s *Service) CreateScript(ctx context.Context, script entity.Script) (*enti
 
 	wg.Wait()
 
-	// as script started we can add to inmemory cache tuple (script.ID, context.CancelFunc)
+	// as script started we can add its command to cached tuple (script.ID, context.CancelFunc),
+	// unless script has already finished and is removed from cache
 	s.cacheMutex.Lock()
 	defer s.cacheMutex.Unlock()
 
-	s.Cache.Set(strconv.Itoa(scpt.ID), entity.CmdContext{Cmd: cmd, Cancel: cancel}, -1)
+	if _, exist := s.Cache.Get(strconv.Itoa(scpt.ID)); exist {
+		s.Cache.Set(strconv.Itoa(scpt.ID), entity.CmdContext{Cmd: cmd, Cancel: cancel}, -1)
+	}
+
+	return scpt
+}
+
+// RunningScripts returns number of scripts running on this instance, which are the ones cached with their cancel functions
+func (s *Service) RunningScripts() int {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
 
-	return scpt, err
+	return s.Cache.ItemCount()
 }
 
+// StopScript stops script run here right away, stop of script run elsewhere is requested via db,
+// so replica or worker running it stops it
 func (s *Service) StopScript(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "script.Service.StopScript")
+	defer span.End()
+
+	script, err := s.GetScript(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = authorize(ctx, entity.OperationStopScript, script); err != nil {
+		return err
+	}
+
 	s.cacheMutex.RLock()
 	defer s.cacheMutex.RUnlock()
 
 	cmdContextAny, exist := s.Cache.Get(strconv.Itoa(id))
 	if !exist {
-		return ErrNoSuchRunningScript
+		stopped, err := s.audited(ctx, entity.AuditActionStopScript, nil, func(ctx context.Context) (*entity.Script, error) {
+			return s.Repo.RequestScriptStop(ctx, id)
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoSuchRunningScript
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// queued script is stopped right away, running one is reported by replica or worker running it
+		if stopped.Status == entity.ScriptStatusStopped {
+			s.notify(ctx, entity.ScriptEventStopped, stopped)
+		}
+
+		return nil
 	}
 
 	cmdContext, ok := cmdContextAny.(entity.CmdContext)
@@ -202,40 +461,67 @@ func (s *Service) StopScript(ctx context.Context, id int) error {
 
 	cmdContext.Cancel()
 
-	if _, err := s.Repo.UpdateScriptRunningState(ctx, id, false); err != nil {
-		return err
-	}
+	_, err = s.audited(ctx, entity.AuditActionStopScript, nil, func(ctx context.Context) (*entity.Script, error) {
+		return s.Repo.FinishScript(ctx, id, entity.ScriptStatusStopped, nil)
+	})
 
-	return nil
+	return err
 }
 
-func (s *Service) GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error) {
-	return s.Repo.GetAllScripts(ctx, offset, limit)
+func (s *Service) GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error) {
+	ctx, span := tracing.Start(ctx, "script.Service.GetAllScripts")
+	defer span.End()
+
+	if err := authorize(ctx, entity.OperationReadScript, nil); err != nil {
+		return nil, err
+	}
+
+	if namespace := scopeNamespace(ctx); namespace != "" {
+		filter.Namespace = namespace
+	}
+
+	return s.Repo.GetAllScripts(ctx, filter, offset, limit)
 }
 
+// GetScript returns script of caller's namespace, scripts of other namespaces are reported as not existing
 func (s *Service) GetScript(ctx context.Context, id int) (*entity.Script, error) {
-	return s.Repo.GetScript(ctx, id)
-}
+	ctx, span := tracing.Start(ctx, "script.Service.GetScript")
+	defer span.End()
+
+	script, err := s.Repo.GetScript(ctx, scopeNamespace(ctx), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSuchScript
+	}
 
-func (s *Service) DeleteScript(ctx context.Context, id int) error {
-	_, err := s.GetScript(ctx, id)
 	if err != nil {
-		return ErrNoSuchScript
+		return nil, err
 	}
 
-	s.cacheMutex.RLock()
-	defer s.cacheMutex.RUnlock()
+	if err = authorize(ctx, entity.OperationReadScript, script); err != nil {
+		return nil, err
+	}
 
-	if cmdContextAny, exist := s.Cache.Get(strconv.Itoa(id)); exist {
-		cmdContext, ok := cmdContextAny.(entity.CmdContext)
-		if ok {
-			cmdContext.Cancel()
-		}
+	return script, nil
+}
+
+func (s *Service) DeleteScript(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "script.Service.DeleteScript")
+	defer span.End()
+
+	script, err := s.GetScript(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	if _, err = s.Repo.DeleteScript(ctx, id); err != nil {
+	if err = authorize(ctx, entity.OperationDeleteScript, script); err != nil {
 		return err
 	}
 
-	return nil
+	s.cancelLocalRun(id)
+
+	_, err = s.audited(ctx, entity.AuditActionDeleteScript, nil, func(ctx context.Context) (*entity.Script, error) {
+		return s.Repo.DeleteScript(ctx, script.Namespace, id)
+	})
+
+	return err
 }
@@ -0,0 +1,153 @@
+package script
+
+import (
+	"context"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+)
+
+// WorkerOptions configure worker, worker runs at most MaxConcurrent scripts at once and only scripts which label selector
+// its Labels match, running scripts of workers which haven't heartbeated for LostAfter are marked as lost
+type WorkerOptions struct {
+	ID                string
+	Hostname          string
+	MaxConcurrent     int
+	Labels            map[string]string
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	LostAfter         time.Duration
+}
+
+// Work runs scripts queued by any replica as worker until ctx is done: queued scripts are claimed every PollInterval,
+// every HeartbeatInterval worker heartbeats, stops scripts which stop is requested and marks scripts of dead workers as lost.
+// Scripts still running when ctx is done are not stopped, they must be stopped by caller
+func (s *Service) Work(ctx context.Context, opts WorkerOptions) error {
+	worker, err := s.Repo.RegisterWorker(ctx, entity.Worker{
+		ID:            opts.ID,
+		Hostname:      opts.Hostname,
+		MaxConcurrent: opts.MaxConcurrent,
+		Labels:        opts.Labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger := s.logger.WithField(logging.FieldWorkerID, worker.ID)
+	logger.Info("worker registered")
+
+	defer func() {
+		if deregisterErr := s.Repo.DeregisterWorker(context.WithoutCancel(ctx), worker.ID); deregisterErr != nil {
+			logger.Errorf("error occurred deregistering worker: %v", deregisterErr)
+		}
+	}()
+
+	poll := time.NewTicker(opts.PollInterval)
+	defer poll.Stop()
+
+	heartbeat := time.NewTicker(opts.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		if err = s.claimQueuedScripts(ctx, opts); err != nil {
+			logger.Errorf("error occurred claiming queued scripts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-poll.C:
+
+		case <-heartbeat.C:
+			if err = s.heartbeat(ctx, opts); err != nil {
+				logger.Errorf("error occurred heartbeating: %v", err)
+			}
+		}
+	}
+}
+
+// claimQueuedScripts claims as many queued scripts as worker has free capacity for and runs them
+func (s *Service) claimQueuedScripts(ctx context.Context, opts WorkerOptions) error {
+	ctx, span := tracing.Start(ctx, "script.Service.claimQueuedScripts")
+	defer span.End()
+
+	capacity := opts.MaxConcurrent - s.RunningScripts()
+	if capacity <= 0 {
+		return nil
+	}
+
+	claimed, err := s.Repo.ClaimQueuedScripts(ctx, opts.ID, capacity)
+	if err != nil {
+		return err
+	}
+
+	for _, script := range claimed {
+		s.run(ctx, script)
+	}
+
+	return nil
+}
+
+// heartbeat prolongs ownership of worker on its scripts, stops its scripts which stop is requested
+// (in case notification was missed) and marks scripts of dead workers as lost
+func (s *Service) heartbeat(ctx context.Context, opts WorkerOptions) error {
+	ctx, span := tracing.Start(ctx, "script.Service.heartbeat")
+	defer span.End()
+
+	if err := s.Repo.HeartbeatWorker(ctx, opts.ID); err != nil {
+		return err
+	}
+
+	stopRequested, err := s.Repo.GetStopRequestedScripts(ctx, opts.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, script := range stopRequested {
+		s.cancelLocalRun(script.ID)
+	}
+
+	return s.MarkLostScripts(ctx, opts.LostAfter)
+}
+
+// MarkLostScripts marks running scripts of workers which haven't heartbeated for lostAfter as lost and notifies about them.
+// Workers do it on heartbeat and api replicas periodically, so scripts are marked even if no worker is alive
+func (s *Service) MarkLostScripts(ctx context.Context, lostAfter time.Duration) error {
+	ctx, span := tracing.Start(ctx, "script.Service.MarkLostScripts")
+	defer span.End()
+
+	lost, err := s.Repo.MarkLostScripts(ctx, lostAfter)
+	if err != nil {
+		return err
+	}
+
+	for _, script := range lost {
+		logging.ScriptEntry(ctx, s.logger, script.ID).WithField(logging.FieldWorkerID, *script.WorkerID).Warn("script lost")
+
+		s.notify(ctx, entity.ScriptEventFailed, script)
+	}
+
+	return nil
+}
+
+// MarkLostScriptsPeriodically marks scripts of dead workers as lost every interval until ctx is done,
+// sweeps of several replicas don't conflict as every script is marked once
+func (s *Service) MarkLostScriptsPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := s.MarkLostScripts(ctx, s.execution.WorkerLostAfter); err != nil {
+				s.logger.Errorf("error occurred marking lost scripts: %v", err)
+			}
+		}
+	}
+}
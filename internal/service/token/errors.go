@@ -0,0 +1,7 @@
+package token
+
+import "errors"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+)
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/pkg/jwt"
+)
+
+var tokenErrs = []error{
+	jwt.ErrMalformedToken,
+	jwt.ErrUnsupportedAlgorithm,
+	jwt.ErrUnknownKey,
+	jwt.ErrInvalidSignature,
+	jwt.ErrTokenExpired,
+	jwt.ErrTokenNotYetValid,
+	jwt.ErrInvalidIssuer,
+	jwt.ErrInvalidAudience,
+}
+
+// Service authenticates callers by JWT issued by SSO
+type Service struct {
+	keys jwt.KeyProvider
+	opts jwt.VerifyOptions
+}
+
+func New(keys jwt.KeyProvider, opts jwt.VerifyOptions) *Service {
+	return &Service{
+		keys: keys,
+		opts: opts,
+	}
+}
+
+// scopesFromClaims returns scopes granted by space separated scope claim (RFC 8693) or scp array
+func scopesFromClaims(claims jwt.Claims) []entity.Scope {
+	scopes := strings.Fields(claims.String("scope"))
+
+	return append(scopes, claims.Strings("scp")...)
+}
+
+// rolesFromClaims returns known roles listed in roles claim
+func rolesFromClaims(claims jwt.Claims) []entity.Role {
+	roles := make([]entity.Role, 0)
+
+	for _, name := range claims.Strings("roles") {
+		if role := entity.Role(name); role.IsValid() {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// Authenticate returns principal identified by token's sub claim, principal's namespace is taken from namespace claim
+func (s *Service) Authenticate(ctx context.Context, token string) (*entity.Principal, error) {
+	claims, err := jwt.Verify(ctx, token, s.keys, s.opts)
+	if err != nil {
+		for _, tokenErr := range tokenErrs {
+			if errors.Is(err, tokenErr) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+			}
+		}
+
+		return nil, err
+	}
+
+	subject := claims.String("sub")
+	if subject == "" {
+		return nil, fmt.Errorf("%w: sub claim is required", ErrInvalidToken)
+	}
+
+	namespace := claims.String("namespace")
+	if namespace == "" {
+		namespace = entity.DefaultNamespace
+	}
+
+	principal := &entity.Principal{
+		Subject:   subject,
+		Scopes:    scopesFromClaims(claims),
+		Namespace: namespace,
+		Roles:     rolesFromClaims(claims),
+		Claims:    claims,
+	}
+
+	if principal.Namespace == entity.GlobalNamespace && !principal.IsGlobal() {
+		return nil, fmt.Errorf("%w: only admins may be granted every namespace", ErrInvalidToken)
+	}
+
+	return principal, nil
+}
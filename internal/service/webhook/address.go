@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// reservedNetworks are not publicly routable besides loopback, private, link-local and multicast ones
+var reservedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// addressAllowed reports whether webhooks may be delivered to address: it must be public unless it's in one of
+// allowed networks, so webhooks can't reach services of internal network or cloud metadata
+func (s *Service) addressAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range s.opts.AllowedNetworks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range reservedNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL checks that host of url resolves to addresses webhooks may be delivered to
+func (s *Service) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+
+	for _, addr := range addrs {
+		if addr = addr.Unmap(); !s.addressAllowed(addr) {
+			return fmt.Errorf("%w: %v resolves to not public address %v", ErrURLNotAllowed, u.Hostname(), addr)
+		}
+	}
+
+	return nil
+}
+
+// CheckCallbackURL checks that url may be callback url of script, callback urls are not accepted at all
+// if there is no secret to sign their payloads with
+func (s *Service) CheckCallbackURL(ctx context.Context, rawURL string) error {
+	if s.opts.CallbackSecret == "" {
+		return ErrCallbacksDisabled
+	}
+
+	return s.CheckURL(ctx, rawURL)
+}
+
+// dialControl refuses connections to addresses webhooks may not be delivered to. It's checked on every connection,
+// so hosts resolving to another address after CheckURL and redirects are refused as well
+func (s *Service) dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if addr := addrPort.Addr().Unmap(); !s.addressAllowed(addr) {
+		return fmt.Errorf("%w: %v is not public address", ErrURLNotAllowed, addr)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"strconv"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/audit"
+)
+
+type AuditLog interface {
+	CreateAuditEvent(ctx context.Context, event entity.AuditEvent) (*entity.AuditEvent, error)
+}
+
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// webhookPayload is part of webhook audit event's payload hash is computed of, secret is never part of it
+type webhookPayload struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Namespace string   `json:"namespace"`
+	Events    []string `json:"events"`
+}
+
+// audited runs fn changing webhook and writes audit event of action on this webhook in one transaction,
+// nothing is written if fn fails
+func (s *Service) audited(
+	ctx context.Context,
+	action entity.AuditAction,
+	payload any,
+	fn func(ctx context.Context) (*entity.Webhook, error),
+) (*entity.Webhook, error) {
+	var wh *entity.Webhook
+
+	err := s.Transactor.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if wh, err = fn(ctx); err != nil {
+			return err
+		}
+
+		event, err := audit.NewEvent(ctx, action, entity.AuditTargetWebhook, strconv.Itoa(wh.ID), wh.Namespace, payload)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Audit.CreateAuditEvent(ctx, event)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wh, nil
+}
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+	"pg-start-trainee-2024/pkg/webhook"
+)
+
+// maxErrorBodyLength is number of bytes of unsuccessful response body stored as delivery's error
+const maxErrorBodyLength = 512
+
+// backoff returns delay before retry of delivery attempted attempts times
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.opts.BaseBackoff
+
+	for i := 1; i < attempts && delay < s.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.opts.MaxBackoff)
+}
+
+// send makes delivery attempt, response status is nil if no response was received
+func (s *Service) send(ctx context.Context, delivery *entity.WebhookDelivery) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderEvent, string(delivery.Event))
+	req.Header.Set(webhook.HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(webhook.HeaderSignature, delivery.Signature)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	status := resp.StatusCode
+
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+
+		return &status, fmt.Errorf("unexpected response status %v: %s", status, body)
+	}
+
+	return &status, nil
+}
+
+// deliver attempts delivery and records result, failed delivery is retried with exponential backoff
+// until it's attempted MaxAttempts times
+func (s *Service) deliver(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	ctx, span := tracing.Start(ctx, "webhook.Service.deliver")
+	defer span.End()
+
+	status, sendErr := s.send(ctx, delivery)
+	if sendErr == nil {
+		_, err := s.Repo.CompleteWebhookDelivery(ctx, delivery.ID, *status)
+
+		return err
+	}
+
+	var retryIn *time.Duration
+
+	if attempts := delivery.Attempts + 1; attempts < s.opts.MaxAttempts {
+		backoff := s.backoff(attempts)
+		retryIn = &backoff
+	}
+
+	_, err := s.Repo.FailWebhookDeliveryAttempt(ctx, delivery.ID, status, sendErr.Error(), retryIn)
+
+	return err
+}
+
+// DeliverDueWebhooks attempts deliveries which next attempt is due, deliveries are claimed,
+// so several instances of service may deliver webhooks concurrently
+func (s *Service) DeliverDueWebhooks(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "webhook.Service.DeliverDueWebhooks")
+	defer span.End()
+
+	// delivery is claimed for longer than it can be attempted, so it's not attempted twice at once
+	deliveries, err := s.Repo.ClaimDueWebhookDeliveries(ctx, s.opts.BatchSize, 2*s.opts.Timeout)
+	if err != nil {
+		return err
+	}
+
+	wg := sync.WaitGroup{}
+
+	for _, delivery := range deliveries {
+		wg.Add(1)
+
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+
+			if deliverErr := s.deliver(ctx, delivery); deliverErr != nil {
+				s.logger.WithContext(ctx).WithField("delivery_id", delivery.ID).
+					Errorf("error occurred recording webhook delivery attempt: %v", deliverErr)
+			}
+		}(delivery)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// DeliverDueWebhooksPeriodically delivers webhooks every interval until ctx is done
+func (s *Service) DeliverDueWebhooksPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := s.DeliverDueWebhooks(ctx); err != nil {
+				s.logger.Errorf("error occurred delivering webhooks: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrNoSuchWebhook = errors.New("no such webhook")
+
+	ErrURLNotAllowed     = errors.New("url is not allowed")
+	ErrCallbacksDisabled = errors.New("callback urls are disabled as no callback secret is configured")
+)
@@ -0,0 +1,238 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"pg-start-trainee-2024/internal/pkg/tracing"
+	"pg-start-trainee-2024/pkg/webhook"
+)
+
+const secretLength = 32
+
+type Repo interface {
+	CreateWebhook(ctx context.Context, webhook entity.Webhook) (*entity.Webhook, error)
+	GetAllWebhooks(ctx context.Context, namespace string) ([]*entity.Webhook, error)
+	GetWebhooksForEvent(ctx context.Context, namespace string, event entity.ScriptEvent) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, namespace string, id int) (*entity.Webhook, error)
+
+	CreateWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (*entity.WebhookDelivery, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id, responseStatus int) (*entity.WebhookDelivery, error)
+	FailWebhookDeliveryAttempt(ctx context.Context, id int, responseStatus *int, lastError string, retryIn *time.Duration) (*entity.WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, error)
+}
+
+// Options configures delivery of webhooks
+type Options struct {
+	// CallbackSecret signs payloads delivered to callback urls of scripts
+	CallbackSecret string
+	// Timeout is timeout of single delivery attempt
+	Timeout time.Duration
+	// MaxAttempts is number of attempts delivery is failed after
+	MaxAttempts int
+	// BaseBackoff is delay before the first retry, every next retry is delayed twice longer up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// BatchSize is number of deliveries attempted at once
+	BatchSize int
+	// AllowedNetworks may be delivered to although they are not public
+	AllowedNetworks []netip.Prefix
+}
+
+type Service struct {
+	Repo   Repo
+	Client *http.Client
+
+	// Audit is written in one transaction with changes of webhooks
+	Audit      AuditLog
+	Transactor Transactor
+
+	logger *logrus.Logger
+	opts   Options
+}
+
+func New(repo Repo, audit AuditLog, transactor Transactor, logger *logrus.Logger, opts Options) *Service {
+	s := &Service{
+		Repo:       repo,
+		Audit:      audit,
+		Transactor: transactor,
+		logger:     logger,
+		opts:       opts,
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout, Control: s.dialControl}
+
+	// deliveries are never sent through proxy, as addresses are checked on connection
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	s.Client = &http.Client{Timeout: opts.Timeout, Transport: transport}
+
+	return s
+}
+
+// CreateWebhook creates subscription with generated secret, secret is returned only here. Webhook of caller bound
+// to namespace is subscribed to scripts of the namespace only
+func (s *Service) CreateWebhook(ctx context.Context, wh entity.Webhook) (*entity.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhook.Service.CreateWebhook")
+	defer span.End()
+
+	var err error
+
+	if wh.Namespace, err = auth.ConfineNamespace(ctx, wh.Namespace); err != nil {
+		return nil, err
+	}
+
+	if err = s.CheckURL(ctx, wh.URL); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, secretLength)
+
+	if _, err = rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	wh.Secret = hex.EncodeToString(buf)
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		wh.CreatedBy = &principal.Subject
+	}
+
+	payload := webhookPayload{
+		Name:      wh.Name,
+		URL:       wh.URL,
+		Namespace: wh.Namespace,
+		Events:    wh.Events,
+	}
+
+	return s.audited(ctx, entity.AuditActionCreateWebhook, payload, func(ctx context.Context) (*entity.Webhook, error) {
+		return s.Repo.CreateWebhook(ctx, wh)
+	})
+}
+
+// GetAllWebhooks returns webhooks of caller's namespace
+func (s *Service) GetAllWebhooks(ctx context.Context) ([]*entity.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "webhook.Service.GetAllWebhooks")
+	defer span.End()
+
+	return s.Repo.GetAllWebhooks(ctx, auth.Namespace(ctx))
+}
+
+// DeleteWebhook deletes subscription of caller's namespace, its pending deliveries are not delivered anymore
+func (s *Service) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := tracing.Start(ctx, "webhook.Service.DeleteWebhook")
+	defer span.End()
+
+	_, err := s.audited(ctx, entity.AuditActionDeleteWebhook, nil, func(ctx context.Context) (*entity.Webhook, error) {
+		return s.Repo.DeleteWebhook(ctx, auth.Namespace(ctx), id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoSuchWebhook
+	}
+
+	return err
+}
+
+// GetWebhookDeliveries returns deliveries of events of scripts of caller's namespace
+func (s *Service) GetWebhookDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "webhook.Service.GetWebhookDeliveries")
+	defer span.End()
+
+	var err error
+
+	if filter.Namespace, err = auth.ConfineNamespace(ctx, filter.Namespace); err != nil {
+		return nil, err
+	}
+
+	return s.Repo.GetWebhookDeliveries(ctx, filter, offset, limit)
+}
+
+func mapScriptToPayload(script *entity.Script) webhook.Script {
+	return webhook.Script{
+		ID:         script.ID,
+		Namespace:  script.Namespace,
+		Command:    script.Command,
+		Status:     string(script.Status),
+		PID:        script.PID,
+		ExitCode:   script.ExitCode,
+		Tags:       script.Tags,
+		CreatedBy:  script.CreatedBy,
+		CreatedAt:  script.CreatedAt,
+		FinishedAt: script.FinishedAt,
+	}
+}
+
+// NotifyScriptEvent enqueues delivery of event to webhooks subscribed to it and to callback url of script,
+// deliveries are sent by DeliverDueWebhooks
+func (s *Service) NotifyScriptEvent(ctx context.Context, event entity.ScriptEvent, script *entity.Script) error {
+	ctx, span := tracing.Start(ctx, "webhook.Service.NotifyScriptEvent")
+	defer span.End()
+
+	payload, err := json.Marshal(webhook.Payload{
+		Event:      string(event),
+		OccurredAt: time.Now().UTC(),
+		Script:     mapScriptToPayload(script),
+	})
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := s.Repo.GetWebhooksForEvent(ctx, script.Namespace, event)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhooks)+1)
+
+	for _, wh := range webhooks {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			WebhookID: &wh.ID,
+			URL:       wh.URL,
+			Signature: webhook.Sign(wh.Secret, payload),
+		})
+	}
+
+	// callback url may be set before callback secret was removed from config, payloads are never signed with empty secret
+	if script.CallbackURL != nil && s.opts.CallbackSecret == "" {
+		logging.ScriptEntry(ctx, s.logger, script.ID).Warnf("%v is not delivered to callback url: %v", event, ErrCallbacksDisabled)
+	}
+
+	if script.CallbackURL != nil && s.opts.CallbackSecret != "" {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			URL:       *script.CallbackURL,
+			Signature: webhook.Sign(s.opts.CallbackSecret, payload),
+		})
+	}
+
+	for _, delivery := range deliveries {
+		delivery.ScriptID = script.ID
+		delivery.Namespace = script.Namespace
+		delivery.Event = event
+		delivery.Payload = string(payload)
+
+		created, err := s.Repo.CreateWebhookDelivery(ctx, delivery)
+		if err != nil {
+			return err
+		}
+
+		logging.ScriptEntry(ctx, s.logger, script.ID).WithField("delivery_id", created.ID).Debugf("%v delivery enqueued", event)
+	}
+
+	return nil
+}
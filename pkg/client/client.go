@@ -0,0 +1,234 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+// scriptPath is path of v1 script routes, client methods mirror them
+const scriptPath = "/pg-start-trainee/api/v1/script"
+
+// headers client authenticates with
+const (
+	apiKeyHeader        = "X-API-Key"
+	authorizationHeader = "Authorization"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxRetries   = 3
+	defaultBaseBackoff  = 200 * time.Millisecond
+	defaultMaxBackoff   = 5 * time.Second
+	defaultPollInterval = time.Second
+)
+
+// Options configures Client, zero values are replaced with defaults
+type Options struct {
+	// HTTPClient sends requests, http.DefaultClient is used if nil
+	HTTPClient *http.Client
+
+	// APIKey is sent in X-API-Key header, Token is sent as bearer token if APIKey is empty
+	APIKey string
+	Token  string
+
+	// Timeout limits every attempt of request, deadline of context limits request with all its retries
+	Timeout time.Duration
+
+	// MaxRetries is number of retries of request failed with transient error, negative value disables retries
+	MaxRetries int
+	// BaseBackoff is delay before the first retry, every next retry is delayed twice longer up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// PollInterval is how often Wait and Output check script
+	PollInterval time.Duration
+}
+
+// Client calls v1 script API of pg-start-trainee server
+type Client struct {
+	baseURL string
+	opts    Options
+}
+
+// New creates client of server at baseURL, e.g. http://localhost:5000
+func New(baseURL string, opts Options) *Client {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+
+	if opts.BaseBackoff == 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		opts:    opts,
+	}
+}
+
+// backoff returns delay before retry of request attempted attempts times
+func (c *Client) backoff(attempts int) time.Duration {
+	delay := c.opts.BaseBackoff
+
+	for i := 1; i < attempts && delay < c.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, c.opts.MaxBackoff)
+}
+
+// response is response of single attempt
+type response struct {
+	status int
+	body   []byte
+}
+
+// send makes single attempt of request limited by Timeout
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, payload []byte) (*response, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	switch {
+	case c.opts.APIKey != "":
+		req.Header.Set(apiKeyHeader, c.opts.APIKey)
+
+	case c.opts.Token != "":
+		req.Header.Set(authorizationHeader, "Bearer "+c.opts.Token)
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response{status: resp.StatusCode, body: respBody}, nil
+}
+
+// isTransient reports whether failed attempt may succeed if retried. Script creation is retried only
+// if connection wasn't established, so the script is never run twice
+func isTransient(ctx context.Context, method string, resp *response, err error) bool {
+	// cancellation or deadline of the whole call is not transient, timeout of attempt is
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if method == http.MethodPost {
+		var opErr *net.OpError
+
+		return err != nil && errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// do sends request with body encoded to JSON and decodes JSON response into out if it's not nil,
+// request is retried with exponential backoff on transient errors
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out any) error {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, header, payload)
+
+		if attempt >= c.opts.MaxRetries || !isTransient(ctx, method, resp, err) {
+			if err != nil {
+				return err
+			}
+
+			return decodeResponse(resp, out)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-time.After(c.backoff(attempt + 1)):
+		}
+	}
+}
+
+// decodeResponse decodes successful response into out, problem is returned as error for failed one
+func decodeResponse(resp *response, out any) error {
+	if resp.status >= http.StatusOK && resp.status < http.StatusMultipleChoices {
+		if out == nil {
+			return nil
+		}
+
+		return json.Unmarshal(resp.body, out)
+	}
+
+	var problem handlerutils.Problem
+	if err := json.Unmarshal(resp.body, &problem); err == nil && problem.Type != "" {
+		return &problem
+	}
+
+	return fmt.Errorf("%w: status %v: %v", ErrUnexpectedResponse, resp.status, strings.TrimSpace(string(resp.body)))
+}
@@ -0,0 +1,17 @@
+package client
+
+import (
+	"errors"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+var ErrUnexpectedResponse = errors.New("client: unexpected response")
+
+// IsProblem reports whether err is problem of type returned by server. Note that v1 routes respond with
+// 400 to all problems, so type is the only way to tell the cause
+func IsProblem(err error, problemType handlerutils.ProblemType) bool {
+	var problem *handlerutils.Problem
+
+	return errors.As(err, &problem) && problem.Type == problemType
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// script statuses
+const (
+	StatusRunning         = "running"
+	StatusFinished        = "finished"
+	StatusFailed          = "failed"
+	StatusStopped         = "stopped"
+	StatusPendingApproval = "pending_approval"
+	StatusRejected        = "rejected"
+	StatusExpired         = "expired"
+	StatusQueued          = "queued"
+	StatusLost            = "lost"
+)
+
+// CreateScriptRequest is script to create, only Command is required
+type CreateScriptRequest struct {
+	Command string   `json:"command"`
+	Tags    []string `json:"tags,omitempty"`
+
+	Interpreter string            `json:"interpreter,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	RunAs       string            `json:"run_as,omitempty"`
+
+	// LabelSelector holds labels worker running script must have
+	LabelSelector map[string]string `json:"label_selector,omitempty"`
+
+	// CallbackURL receives signed events of script
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+// CreatedScript is script just created, it's either running or waiting for approval or worker
+type CreatedScript struct {
+	ID      int      `json:"id"`
+	Command string   `json:"command"`
+	PID     int      `json:"pid"`
+	Status  string   `json:"status"`
+	Tags    []string `json:"tags"`
+}
+
+// Script is script as v1 routes return it, fields are encoded with their names
+type Script struct {
+	ID                int
+	Namespace         string
+	Command           string
+	Output            string
+	IsRunning         bool
+	PID               int
+	Status            string
+	ExitCode          *int
+	Tags              []string
+	APIKeyID          *int
+	CreatedBy         *string
+	Interpreter       string
+	RunAs             string
+	PolicyRule        *string
+	ReviewedBy        *string
+	ReviewedAt        *time.Time
+	RejectReason      *string
+	ApprovalExpiresAt *time.Time
+	CallbackURL       *string
+	LabelSelector     map[string]string
+	WorkerID          *string
+	StopRequestedAt   *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	FinishedAt        *time.Time
+}
+
+// Done reports whether script won't change anymore: it's finished, stopped, rejected etc.
+func (s *Script) Done() bool {
+	return s.FinishedAt != nil
+}
+
+// Filter selects scripts returned by GetAllScripts, zero values don't filter
+type Filter struct {
+	Statuses  []string
+	IsRunning *bool
+	Command   string
+	ExitCode  *int
+	Tags      []string
+
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	FinishedFrom *time.Time
+	FinishedTo   *time.Time
+
+	// Sort is one of id, command, exit_code, created_at or finished_at, Order is asc or desc
+	Sort  string
+	Order string
+}
+
+func (f *Filter) query(offset, limit int) url.Values {
+	query := url.Values{}
+
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	if len(f.Statuses) != 0 {
+		query.Set("status", strings.Join(f.Statuses, ","))
+	}
+
+	if f.IsRunning != nil {
+		query.Set("is_running", strconv.FormatBool(*f.IsRunning))
+	}
+
+	if f.Command != "" {
+		query.Set("command", f.Command)
+	}
+
+	if f.ExitCode != nil {
+		query.Set("exit_code", strconv.Itoa(*f.ExitCode))
+	}
+
+	if len(f.Tags) != 0 {
+		query.Set("tags", strings.Join(f.Tags, ","))
+	}
+
+	for key, t := range map[string]*time.Time{
+		"created_from":  f.CreatedFrom,
+		"created_to":    f.CreatedTo,
+		"finished_from": f.FinishedFrom,
+		"finished_to":   f.FinishedTo,
+	} {
+		if t != nil {
+			query.Set(key, t.Format(time.RFC3339))
+		}
+	}
+
+	if f.Sort != "" {
+		query.Set("sort", f.Sort)
+	}
+
+	if f.Order != "" {
+		query.Set("order", f.Order)
+	}
+
+	return query
+}
+
+func idHeader(id int) http.Header {
+	return http.Header{"Id": []string{strconv.Itoa(id)}}
+}
+
+// CreateScript creates script and runs it unless it requires approval or is queued for workers
+func (c *Client) CreateScript(ctx context.Context, script CreateScriptRequest) (*CreatedScript, error) {
+	var created CreatedScript
+
+	if err := c.do(ctx, http.MethodPost, scriptPath, nil, script, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (c *Client) GetScript(ctx context.Context, id int) (*Script, error) {
+	var script Script
+
+	if err := c.do(ctx, http.MethodGet, scriptPath, idHeader(id), nil, &script); err != nil {
+		return nil, err
+	}
+
+	return &script, nil
+}
+
+func (c *Client) GetAllScripts(ctx context.Context, filter Filter, offset, limit int) ([]*Script, error) {
+	var scripts []*Script
+
+	if err := c.do(ctx, http.MethodGet, scriptPath+"/all?"+filter.query(offset, limit).Encode(), nil, nil, &scripts); err != nil {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+func (c *Client) StopScript(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPatch, scriptPath, idHeader(id), nil, nil)
+}
+
+func (c *Client) DeleteScript(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, scriptPath, idHeader(id), nil, nil)
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Wait blocks until script is done and returns its final state
+func (c *Client) Wait(ctx context.Context, id int) (*Script, error) {
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		script, err := c.GetScript(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if script.Done() {
+			return script, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// OutputStream iterates over output of script as it's appended, it's used like bufio.Scanner:
+//
+//	stream := client.Output(ctx, id)
+//	for stream.Next() {
+//		fmt.Print(stream.Chunk())
+//	}
+//	if err := stream.Err(); err != nil { ... }
+type OutputStream struct {
+	client *Client
+	ctx    context.Context
+	id     int
+
+	chunk  string
+	sent   int
+	script *Script
+	polled bool
+	done   bool
+	err    error
+}
+
+// Output returns stream of output of script from the beginning until script is done
+func (c *Client) Output(ctx context.Context, id int) *OutputStream {
+	return &OutputStream{client: c, ctx: ctx, id: id}
+}
+
+// Next waits for the next chunk of output, false is returned when script is done and all its output is read
+// or error occurred
+func (s *OutputStream) Next() bool {
+	for !s.done && s.err == nil {
+		if s.polled {
+			select {
+			case <-s.ctx.Done():
+				s.err = s.ctx.Err()
+
+				return false
+
+			case <-time.After(s.client.opts.PollInterval):
+			}
+		}
+
+		script, err := s.client.GetScript(s.ctx, s.id)
+		if err != nil {
+			s.err = err
+
+			return false
+		}
+
+		// output is checked once more after script is done, as the rest of it is stored right after
+		s.done = s.script != nil && s.script.Done()
+		s.script = script
+		s.polled = true
+
+		if len(script.Output) > s.sent {
+			s.chunk = script.Output[s.sent:]
+			s.sent = len(script.Output)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Chunk returns output appended since previous chunk
+func (s *OutputStream) Chunk() string {
+	return s.chunk
+}
+
+// Script returns the latest state of script, it's final one once Next returns false without error
+func (s *OutputStream) Script() *Script {
+	return s.script
+}
+
+func (s *OutputStream) Err() error {
+	return s.err
+}
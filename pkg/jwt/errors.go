@@ -0,0 +1,16 @@
+package jwt
+
+import "errors"
+
+var (
+	ErrMalformedToken       = errors.New("jwt: malformed token")
+	ErrUnsupportedAlgorithm = errors.New("jwt: unsupported algorithm")
+	ErrUnknownKey           = errors.New("jwt: unknown key")
+	ErrInvalidSignature     = errors.New("jwt: invalid signature")
+	ErrTokenExpired         = errors.New("jwt: token is expired")
+	ErrTokenNotYetValid     = errors.New("jwt: token is not valid yet")
+	ErrInvalidIssuer        = errors.New("jwt: invalid issuer")
+	ErrInvalidAudience      = errors.New("jwt: invalid audience")
+
+	ErrInvalidJWKS = errors.New("jwt: invalid jwks")
+)
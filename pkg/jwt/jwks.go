@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Key is verification key from JWKS, Public is *rsa.PublicKey, *ecdsa.PublicKey or []byte for HMAC keys
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    any
+}
+
+type KeySet struct {
+	Keys []Key
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %v", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+
+		return key, nil
+
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %v", k.Kty)
+	}
+}
+
+// ParseJWKS parses JSON Web Key Set, keys not meant for signature verification are skipped
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var raw struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWKS, err)
+	}
+
+	set := &KeySet{Keys: make([]Key, 0, len(raw.Keys))}
+
+	for _, k := range raw.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		public, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("%w: key '%v': %v", ErrInvalidJWKS, k.Kid, err)
+		}
+
+		set.Keys = append(set.Keys, Key{ID: k.Kid, Algorithm: k.Alg, Public: public})
+	}
+
+	return set, nil
+}
+
+// Lookup returns keys matching kid that can be used with alg, every suitable key is returned if kid is empty
+func (s *KeySet) Lookup(kid, alg string) []Key {
+	keys := make([]Key, 0, 1)
+
+	for _, key := range s.Keys {
+		if kid != "" && key.ID != kid {
+			continue
+		}
+
+		if key.Algorithm != "" && key.Algorithm != alg {
+			continue
+		}
+
+		if keyFitsAlgorithm(key.Public, alg) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// keyFitsAlgorithm prevents algorithm confusion, e.g. RSA public key can't be used as HMAC secret
+func keyFitsAlgorithm(public any, alg string) bool {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256
+	case []byte:
+		return alg == AlgHS256
+	default:
+		return false
+	}
+}
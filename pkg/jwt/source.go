@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// minRefreshInterval limits refreshes caused by tokens signed with unknown keys
+const minRefreshInterval = 30 * time.Second
+
+// Source is KeyProvider caching JWKS for ttl, JWKS is refreshed earlier if token is signed with unknown key,
+// so rotated keys are picked up without restart. If JWKS can't be refreshed, the last loaded one is used
+// until it's older than maxStale
+type Source struct {
+	load     func(ctx context.Context) ([]byte, error)
+	ttl      time.Duration
+	maxStale time.Duration
+
+	mutex *sync.Mutex
+	set   *KeySet
+	// checkedAt is time of the last refresh attempt, failed attempts are not retried on every call
+	checkedAt time.Time
+	// loadedAt is time of the last successful refresh
+	loadedAt time.Time
+
+	logger *logrus.Logger
+}
+
+func newSource(load func(ctx context.Context) ([]byte, error), ttl, maxStale time.Duration, logger *logrus.Logger) *Source {
+	return &Source{
+		load:     load,
+		ttl:      ttl,
+		maxStale: maxStale,
+		mutex:    &sync.Mutex{},
+		logger:   logger,
+	}
+}
+
+func NewFileSource(path string, ttl, maxStale time.Duration, logger *logrus.Logger) *Source {
+	return newSource(func(_ context.Context) ([]byte, error) { return os.ReadFile(path) }, ttl, maxStale, logger)
+}
+
+func NewURLSource(url string, client *http.Client, ttl, maxStale time.Duration, logger *logrus.Logger) *Source {
+	return newSource(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code fetching jwks: %v", resp.StatusCode)
+		}
+
+		return io.ReadAll(resp.Body)
+	}, ttl, maxStale, logger)
+}
+
+// refresh loads JWKS, failure is logged and previously loaded JWKS is kept
+func (s *Source) refresh(ctx context.Context) error {
+	s.checkedAt = time.Now()
+
+	data, err := s.load(ctx)
+	if err == nil {
+		var set *KeySet
+
+		if set, err = ParseJWKS(data); err == nil {
+			s.set = set
+			s.loadedAt = s.checkedAt
+
+			return nil
+		}
+	}
+
+	if s.set != nil {
+		s.logger.WithContext(ctx).Errorf("error occurred refreshing jwks, keys loaded at %v are used: %v", s.loadedAt, err)
+	}
+
+	return err
+}
+
+// Keys returns keys matching kid and alg, previously loaded keys are used if JWKS can't be refreshed
+// unless they are older than maxStale
+func (s *Source) Keys(ctx context.Context, kid, alg string) ([]Key, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.set == nil || time.Since(s.checkedAt) > s.ttl {
+		if err := s.refresh(ctx); err != nil {
+			if s.set == nil {
+				return nil, err
+			}
+
+			if time.Since(s.loadedAt) > s.maxStale {
+				return nil, fmt.Errorf("jwks loaded at %v is too stale: %w", s.loadedAt, err)
+			}
+		}
+	}
+
+	keys := s.set.Lookup(kid, alg)
+
+	// failed refresh is already logged and token signed with unknown key is rejected as such
+	if len(keys) == 0 && time.Since(s.checkedAt) > minRefreshInterval && s.refresh(ctx) == nil {
+		keys = s.set.Lookup(kid, alg)
+	}
+
+	return keys, nil
+}
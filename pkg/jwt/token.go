@@ -0,0 +1,203 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgHS256 = "HS256"
+)
+
+type Claims map[string]any
+
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+
+	return s
+}
+
+// Strings returns claim that is either string or array of strings (e.g. aud)
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+
+	case []any:
+		strs := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+
+		return strs
+
+	default:
+		return nil
+	}
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+
+	case json.Number:
+		secs, err := v.Int64()
+
+		return time.Unix(secs, 0), err == nil
+
+	default:
+		return time.Time{}, false
+	}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type KeyProvider interface {
+	Keys(ctx context.Context, kid, alg string) ([]Key, error)
+}
+
+type VerifyOptions struct {
+	// Issuers token may be issued by, any issuer is accepted if empty
+	Issuers []string
+	// Audience token must be issued for, audience is not checked if empty
+	Audience string
+	// Leeway is allowed clock skew
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+// Verify checks signature of compact serialized token and its registered claims, token must have exp claim
+func Verify(ctx context.Context, token string, provider KeyProvider, opts VerifyOptions) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var hdr header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, err
+	}
+
+	if hdr.Alg != AlgRS256 && hdr.Alg != AlgES256 && hdr.Alg != AlgHS256 {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedAlgorithm, hdr.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	keys, err := provider.Keys(ctx, hdr.Kid, hdr.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	if !slices.ContainsFunc(keys, func(key Key) bool { return verifySignature(hdr.Alg, key.Public, signed, signature) }) {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err = validateClaims(claims, opts); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, dest any) error {
+	buf, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+
+	if err = json.Unmarshal(buf, dest); err != nil {
+		return ErrMalformedToken
+	}
+
+	return nil
+}
+
+func verifySignature(alg string, public any, signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return alg == AlgRS256 && rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+
+	case *ecdsa.PublicKey:
+		// signature is r || s, each is 32 bytes for P-256
+		if alg != AlgES256 || len(signature) != 64 {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(key, digest[:], r, s)
+
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(signed)
+
+		return alg == AlgHS256 && hmac.Equal(mac.Sum(nil), signature)
+
+	default:
+		return false
+	}
+}
+
+func validateClaims(claims Claims, opts VerifyOptions) error {
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	exp, ok := claims.time("exp")
+	if !ok || now.After(exp.Add(opts.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if nbf, ok := claims.time("nbf"); ok && now.Add(opts.Leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+
+	if len(opts.Issuers) != 0 && !slices.Contains(opts.Issuers, claims.String("iss")) {
+		return ErrInvalidIssuer
+	}
+
+	if opts.Audience != "" && !slices.Contains(claims.Strings("aud"), opts.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
@@ -0,0 +1,323 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/script/v1/script.proto
+
+package scriptv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ScriptService_CreateScript_FullMethodName = "/pgstart.script.v1.ScriptService/CreateScript"
+	ScriptService_GetScript_FullMethodName    = "/pgstart.script.v1.ScriptService/GetScript"
+	ScriptService_ListScripts_FullMethodName  = "/pgstart.script.v1.ScriptService/ListScripts"
+	ScriptService_StopScript_FullMethodName   = "/pgstart.script.v1.ScriptService/StopScript"
+	ScriptService_DeleteScript_FullMethodName = "/pgstart.script.v1.ScriptService/DeleteScript"
+	ScriptService_WatchOutput_FullMethodName  = "/pgstart.script.v1.ScriptService/WatchOutput"
+)
+
+// ScriptServiceClient is the client API for ScriptService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ScriptService runs commands as scripts, it's backed by the same service as REST API
+type ScriptServiceClient interface {
+	// CreateScript creates and runs script, script policy requires approval for is stored without running
+	CreateScript(ctx context.Context, in *CreateScriptRequest, opts ...grpc.CallOption) (*Script, error)
+	GetScript(ctx context.Context, in *GetScriptRequest, opts ...grpc.CallOption) (*Script, error)
+	ListScripts(ctx context.Context, in *ListScriptsRequest, opts ...grpc.CallOption) (*ListScriptsResponse, error)
+	StopScript(ctx context.Context, in *StopScriptRequest, opts ...grpc.CallOption) (*StopScriptResponse, error)
+	DeleteScript(ctx context.Context, in *DeleteScriptRequest, opts ...grpc.CallOption) (*DeleteScriptResponse, error)
+	// WatchOutput streams output of script as it's appended, stream ends when script finishes
+	WatchOutput(ctx context.Context, in *WatchOutputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OutputChunk], error)
+}
+
+type scriptServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScriptServiceClient(cc grpc.ClientConnInterface) ScriptServiceClient {
+	return &scriptServiceClient{cc}
+}
+
+func (c *scriptServiceClient) CreateScript(ctx context.Context, in *CreateScriptRequest, opts ...grpc.CallOption) (*Script, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Script)
+	err := c.cc.Invoke(ctx, ScriptService_CreateScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scriptServiceClient) GetScript(ctx context.Context, in *GetScriptRequest, opts ...grpc.CallOption) (*Script, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Script)
+	err := c.cc.Invoke(ctx, ScriptService_GetScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scriptServiceClient) ListScripts(ctx context.Context, in *ListScriptsRequest, opts ...grpc.CallOption) (*ListScriptsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScriptsResponse)
+	err := c.cc.Invoke(ctx, ScriptService_ListScripts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scriptServiceClient) StopScript(ctx context.Context, in *StopScriptRequest, opts ...grpc.CallOption) (*StopScriptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopScriptResponse)
+	err := c.cc.Invoke(ctx, ScriptService_StopScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scriptServiceClient) DeleteScript(ctx context.Context, in *DeleteScriptRequest, opts ...grpc.CallOption) (*DeleteScriptResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteScriptResponse)
+	err := c.cc.Invoke(ctx, ScriptService_DeleteScript_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scriptServiceClient) WatchOutput(ctx context.Context, in *WatchOutputRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OutputChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ScriptService_ServiceDesc.Streams[0], ScriptService_WatchOutput_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOutputRequest, OutputChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ScriptService_WatchOutputClient = grpc.ServerStreamingClient[OutputChunk]
+
+// ScriptServiceServer is the server API for ScriptService service.
+// All implementations must embed UnimplementedScriptServiceServer
+// for forward compatibility.
+//
+// ScriptService runs commands as scripts, it's backed by the same service as REST API
+type ScriptServiceServer interface {
+	// CreateScript creates and runs script, script policy requires approval for is stored without running
+	CreateScript(context.Context, *CreateScriptRequest) (*Script, error)
+	GetScript(context.Context, *GetScriptRequest) (*Script, error)
+	ListScripts(context.Context, *ListScriptsRequest) (*ListScriptsResponse, error)
+	StopScript(context.Context, *StopScriptRequest) (*StopScriptResponse, error)
+	DeleteScript(context.Context, *DeleteScriptRequest) (*DeleteScriptResponse, error)
+	// WatchOutput streams output of script as it's appended, stream ends when script finishes
+	WatchOutput(*WatchOutputRequest, grpc.ServerStreamingServer[OutputChunk]) error
+	mustEmbedUnimplementedScriptServiceServer()
+}
+
+// UnimplementedScriptServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedScriptServiceServer struct{}
+
+func (UnimplementedScriptServiceServer) CreateScript(context.Context, *CreateScriptRequest) (*Script, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateScript not implemented")
+}
+func (UnimplementedScriptServiceServer) GetScript(context.Context, *GetScriptRequest) (*Script, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScript not implemented")
+}
+func (UnimplementedScriptServiceServer) ListScripts(context.Context, *ListScriptsRequest) (*ListScriptsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScripts not implemented")
+}
+func (UnimplementedScriptServiceServer) StopScript(context.Context, *StopScriptRequest) (*StopScriptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopScript not implemented")
+}
+func (UnimplementedScriptServiceServer) DeleteScript(context.Context, *DeleteScriptRequest) (*DeleteScriptResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteScript not implemented")
+}
+func (UnimplementedScriptServiceServer) WatchOutput(*WatchOutputRequest, grpc.ServerStreamingServer[OutputChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOutput not implemented")
+}
+func (UnimplementedScriptServiceServer) mustEmbedUnimplementedScriptServiceServer() {}
+func (UnimplementedScriptServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeScriptServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScriptServiceServer will
+// result in compilation errors.
+type UnsafeScriptServiceServer interface {
+	mustEmbedUnimplementedScriptServiceServer()
+}
+
+func RegisterScriptServiceServer(s grpc.ServiceRegistrar, srv ScriptServiceServer) {
+	// If the following call pancis, it indicates UnimplementedScriptServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ScriptService_ServiceDesc, srv)
+}
+
+func _ScriptService_CreateScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScriptServiceServer).CreateScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScriptService_CreateScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScriptServiceServer).CreateScript(ctx, req.(*CreateScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScriptService_GetScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScriptServiceServer).GetScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScriptService_GetScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScriptServiceServer).GetScript(ctx, req.(*GetScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScriptService_ListScripts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScriptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScriptServiceServer).ListScripts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScriptService_ListScripts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScriptServiceServer).ListScripts(ctx, req.(*ListScriptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScriptService_StopScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScriptServiceServer).StopScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScriptService_StopScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScriptServiceServer).StopScript(ctx, req.(*StopScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScriptService_DeleteScript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScriptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScriptServiceServer).DeleteScript(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ScriptService_DeleteScript_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScriptServiceServer).DeleteScript(ctx, req.(*DeleteScriptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScriptService_WatchOutput_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOutputRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScriptServiceServer).WatchOutput(m, &grpc.GenericServerStream[WatchOutputRequest, OutputChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ScriptService_WatchOutputServer = grpc.ServerStreamingServer[OutputChunk]
+
+// ScriptService_ServiceDesc is the grpc.ServiceDesc for ScriptService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ScriptService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pgstart.script.v1.ScriptService",
+	HandlerType: (*ScriptServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateScript",
+			Handler:    _ScriptService_CreateScript_Handler,
+		},
+		{
+			MethodName: "GetScript",
+			Handler:    _ScriptService_GetScript_Handler,
+		},
+		{
+			MethodName: "ListScripts",
+			Handler:    _ScriptService_ListScripts_Handler,
+		},
+		{
+			MethodName: "StopScript",
+			Handler:    _ScriptService_StopScript_Handler,
+		},
+		{
+			MethodName: "DeleteScript",
+			Handler:    _ScriptService_DeleteScript_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOutput",
+			Handler:       _ScriptService_WatchOutput_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/script/v1/script.proto",
+}
@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	ErrCannotOpenConnection = errors.New("dbutils: cannot open database connection")
+	ErrNoMigrations         = errors.New("dbutils: no migrations found")
 )
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Listener receives notifications sent to channel with NOTIFY on dedicated connection, lost connection is reestablished
+type Listener struct {
+	ConnectionURL string
+	Channel       string
+	// RetryInterval is delay between attempts to reconnect
+	RetryInterval time.Duration
+
+	logger *logrus.Logger
+}
+
+func NewListener(connectionURL, channel string, retryInterval time.Duration, logger *logrus.Logger) *Listener {
+	return &Listener{
+		ConnectionURL: connectionURL,
+		Channel:       channel,
+		RetryInterval: retryInterval,
+		logger:        logger,
+	}
+}
+
+// Listen passes payloads of notifications to handle until ctx is done. Notifications sent while connection is lost
+// are never received, so connected is called every time listening is (re)started
+func (l *Listener) Listen(ctx context.Context, connected func(), handle func(payload string)) error {
+	for {
+		err := l.listen(ctx, connected, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		l.logger.Errorf("error occurred listening to channel %v: %v, reconnecting in %v", l.Channel, err, l.RetryInterval)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-time.After(l.RetryInterval):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, connected func(), handle func(payload string)) error {
+	conn, err := pgx.Connect(ctx, l.ConnectionURL)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.Channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	connected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(notification.Payload)
+	}
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LatestMigrationVersion returns version of the latest goose sql migration in dir,
+// migrations are named <version>_<name>.sql
+func LatestMigrationVersion(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			continue
+		}
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		latest = max(latest, version)
+	}
+
+	if latest == 0 {
+		return 0, ErrNoMigrations
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// Transactor runs functions in transaction, repositories take part in it by running queries with Ext
+type Transactor struct {
+	DB *sqlx.DB
+}
+
+func NewTransactor(db *sqlx.DB) *Transactor {
+	return &Transactor{
+		DB: db,
+	}
+}
+
+// InTx runs fn in transaction, which is committed if fn returns nil and rolled back otherwise.
+// Nested calls join transaction ctx is already run in
+func (t *Transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// Ext returns transaction ctx is run in, db is returned outside of transaction
+func Ext(ctx context.Context, db *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StringArray is postgres text[] which can be scanned with database/sql and pgx stdlib driver
+type StringArray []string
+
+func (a *StringArray) Scan(src any) error {
+	if src == nil {
+		*a = nil
+
+		return nil
+	}
+
+	var arr []string
+
+	if err := pgtype.NewMap().SQLScanner(&arr).Scan(src); err != nil {
+		return err
+	}
+
+	*a = arr
+
+	return nil
+}
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+
+	typeMap := pgtype.NewMap()
+
+	buf, err := typeMap.Encode(pgtype.TextArrayOID, pgtype.TextFormatCode, []string(a), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(buf), nil
+}
+
+// StringMap is postgres jsonb object with string values
+type StringMap map[string]string
+
+func (m *StringMap) Scan(src any) error {
+	if src == nil {
+		*m = nil
+
+		return nil
+	}
+
+	var buf []byte
+
+	switch v := src.(type) {
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T to StringMap", src)
+	}
+
+	return json.Unmarshal(buf, m)
+}
+
+func (m StringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+
+	buf, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(buf), nil
+}
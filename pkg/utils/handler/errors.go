@@ -8,4 +8,6 @@ var (
 
 	ErrNoQueryParamProvided      = errors.New("no query param provided")
 	ErrInvalidQueryParamProvided = errors.New("invalid query param provided")
+
+	ErrInvalidURLParamProvided = errors.New("invalid url param provided")
 )
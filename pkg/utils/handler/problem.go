@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ProblemType identifies kind of problem, clients should rely on it instead of detail message
+type ProblemType string
+
+const (
+	ProblemTypeBadRequest       ProblemType = "/problems/bad-request"
+	ProblemTypeValidationFailed ProblemType = "/problems/validation-failed"
+	ProblemTypeNotFound         ProblemType = "/problems/not-found"
+	ProblemTypeInvalidState     ProblemType = "/problems/invalid-state"
+	ProblemTypeLimitExceeded    ProblemType = "/problems/limit-exceeded"
+	ProblemTypeUnauthorized     ProblemType = "/problems/unauthorized"
+	ProblemTypeForbidden        ProblemType = "/problems/forbidden"
+	ProblemTypePolicyViolation  ProblemType = "/problems/policy-violation"
+	ProblemTypeUnschedulable    ProblemType = "/problems/unschedulable"
+	ProblemTypeIdempotencyKey   ProblemType = "/problems/idempotency-key"
+	ProblemTypeInProgress       ProblemType = "/problems/in-progress"
+	ProblemTypeInternal         ProblemType = "/problems/internal"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Problem is RFC 7807 problem details object
+type Problem struct {
+	Type     ProblemType  `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+
+	return fmt.Sprintf("%v: %v", p.Title, p.Detail)
+}
+
+func NewBadRequestProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeBadRequest,
+		Title:  "Bad request",
+		Status: http.StatusBadRequest,
+		Detail: detail,
+	}
+}
+
+// NewMalformedBodyProblem is problem of request body that can't be decoded, decoder errors refer to offsets
+// and Go types, so they are only logged
+func NewMalformedBodyProblem() *Problem {
+	return NewBadRequestProblem("malformed JSON body")
+}
+
+func NewNotFoundProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeNotFound,
+		Title:  "Resource not found",
+		Status: http.StatusNotFound,
+		Detail: detail,
+	}
+}
+
+func NewInvalidStateProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeInvalidState,
+		Title:  "Invalid state transition",
+		Status: http.StatusConflict,
+		Detail: detail,
+	}
+}
+
+func NewLimitExceededProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeLimitExceeded,
+		Title:  "Limit exceeded",
+		Status: http.StatusTooManyRequests,
+		Detail: detail,
+	}
+}
+
+func NewUnauthorizedProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeUnauthorized,
+		Title:  "Unauthorized",
+		Status: http.StatusUnauthorized,
+		Detail: detail,
+	}
+}
+
+func NewForbiddenProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeForbidden,
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: detail,
+	}
+}
+
+func NewPolicyViolationProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypePolicyViolation,
+		Title:  "Command is not allowed by policy",
+		Status: http.StatusForbidden,
+		Detail: detail,
+	}
+}
+
+func NewUnschedulableProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeUnschedulable,
+		Title:  "Script can't be scheduled",
+		Status: http.StatusUnprocessableEntity,
+		Detail: detail,
+	}
+}
+
+// NewIdempotencyKeyProblem is returned if idempotency key is reused with different request
+func NewIdempotencyKeyProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeIdempotencyKey,
+		Title:  "Idempotency key is already used",
+		Status: http.StatusUnprocessableEntity,
+		Detail: detail,
+	}
+}
+
+func NewInProgressProblem(detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeInProgress,
+		Title:  "Request is in progress",
+		Status: http.StatusConflict,
+		Detail: detail,
+	}
+}
+
+// NewInternalProblem returns problem without any details, so internals are not leaked to clients
+func NewInternalProblem() *Problem {
+	return &Problem{
+		Type:   ProblemTypeInternal,
+		Title:  "Internal error",
+		Status: http.StatusInternalServerError,
+	}
+}
+
+// NewValidationFailedProblem returns problem with details for every field that failed validation,
+// errors other than validator.ValidationErrors are reported in detail
+func NewValidationFailedProblem(err error) *Problem {
+	problem := &Problem{
+		Type:   ProblemTypeValidationFailed,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		problem.Detail = err.Error()
+
+		return problem
+	}
+
+	problem.Errors = make([]FieldError, 0, len(validationErrs))
+
+	for _, fieldErr := range validationErrs {
+		problem.Errors = append(problem.Errors, FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fmt.Sprintf("field %v failed on '%v' rule", fieldErr.Field(), fieldErr.Tag()),
+		})
+	}
+
+	return problem
+}
+
+// JSONTagName returns name of field used in json, it's meant to be registered in validator,
+// so field errors refer to fields as clients know them
+func JSONTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// WriteProblemAndLog writes problem as application/problem+json and logs logMsg with request's context if it's not empty
+func WriteProblemAndLog(rw http.ResponseWriter, req *http.Request, logger *logrus.Logger, problem *Problem, logMsg string) {
+	if logMsg != "" {
+		logger.WithContext(req.Context()).Error(logMsg)
+	}
+
+	if problem.Instance == "" {
+		problem.Instance = req.URL.Path
+	}
+
+	rw.Header().Set("Content-Type", ProblemContentType)
+	rw.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(rw).Encode(problem); err != nil {
+		logger.WithContext(req.Context()).Errorf("error occurred writing response: %s", err)
+	}
+}
@@ -3,7 +3,10 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,6 +29,75 @@ func GetIntParamFromQuery(req *http.Request, key string) (int, error) {
 	return strconv.Atoi(req.URL.Query().Get(key))
 }
 
+// GetOptionalIntParamFromQuery parses int query param, nil is returned if param is not provided
+func GetOptionalIntParamFromQuery(req *http.Request, key string) (*int, error) {
+	str := req.URL.Query().Get(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, ErrInvalidQueryParamProvided
+	}
+
+	return &val, nil
+}
+
+// GetOptionalBoolParamFromQuery parses bool query param, nil is returned if param is not provided
+func GetOptionalBoolParamFromQuery(req *http.Request, key string) (*bool, error) {
+	str := req.URL.Query().Get(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	val, err := strconv.ParseBool(str)
+	if err != nil {
+		return nil, ErrInvalidQueryParamProvided
+	}
+
+	return &val, nil
+}
+
+// GetListParamFromQuery returns values of query param given either several times or comma separated
+func GetListParamFromQuery(req *http.Request, key string) []string {
+	values := make([]string, 0)
+
+	for _, param := range req.URL.Query()[key] {
+		for _, val := range strings.Split(param, ",") {
+			if val = strings.TrimSpace(val); val != "" {
+				values = append(values, val)
+			}
+		}
+	}
+
+	return values
+}
+
+// GetTimeParamFromQuery parses RFC3339 time query param, nil is returned if param is not provided
+func GetTimeParamFromQuery(req *http.Request, key string) (*time.Time, error) {
+	str := req.URL.Query().Get(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, ErrInvalidQueryParamProvided
+	}
+
+	return &t, nil
+}
+
+func GetIntURLParam(req *http.Request, key string) (int, error) {
+	val, err := strconv.Atoi(chi.URLParam(req, key))
+	if err != nil {
+		return -1, ErrInvalidURLParamProvided
+	}
+
+	return val, nil
+}
+
 func GetIntHeaderByKey(req *http.Request, key string) (int, error) {
 	str := req.Header.Get(key)
 	if str == "" {
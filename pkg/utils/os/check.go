@@ -0,0 +1,12 @@
+package os
+
+import (
+	"context"
+	"os/exec"
+)
+
+// CheckCanRun starts trivial process with default interpreter, so it fails if processes can't be forked
+// (e.g. process limit is reached) or interpreter is missing
+func CheckCanRun(ctx context.Context) error {
+	return exec.CommandContext(ctx, defaultInterpreter, "-c", "exit 0").Run()
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package os
+
+import "syscall"
+
+func sysProcAttrForUser(string, string) (*syscall.SysProcAttr, error) {
+	return nil, ErrRunAsNotSupported
+}
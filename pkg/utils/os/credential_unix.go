@@ -0,0 +1,35 @@
+//go:build unix
+
+package os
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// sysProcAttrForUser returns attributes of process run as user, script file is handed over to the user
+// as it's readable by owner only
+func sysProcAttrForUser(username, scriptPath string) (*syscall.SysProcAttr, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Chown(scriptPath, int(uid), int(gid)); err != nil {
+		return nil, err
+	}
+
+	return &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}}, nil
+}
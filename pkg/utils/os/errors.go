@@ -3,5 +3,6 @@ package os
 import "errors"
 
 var (
-	ErrContextCancelled = errors.New("osutils: context cancelled")
+	ErrContextCancelled  = errors.New("osutils: context cancelled")
+	ErrRunAsNotSupported = errors.New("osutils: running command as another user is not supported on this platform")
 )
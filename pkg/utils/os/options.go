@@ -0,0 +1,13 @@
+package os
+
+// CommandOptions tune how command is run, zero value runs command with /bin/sh as current user
+type CommandOptions struct {
+	// Interpreter is path to interpreter the command is passed to as script file
+	Interpreter string
+	// Env is appended to environment of the process, items look like KEY=VALUE
+	Env []string
+	// RunAs is name of user command is run as
+	RunAs string
+}
+
+const defaultInterpreter = "/bin/sh"
@@ -3,58 +3,106 @@ package os
 import (
 	"bufio"
 	"context"
-	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"sync/atomic"
-	"time"
+	"sync"
 )
 
-func RunCommand(ctx context.Context, command string, pidChan chan int, cmdChan chan *exec.Cmd, callback func(chan string)) error {
-	errChan := make(chan error)
-	doneChan := make(chan bool, 1)
+func RunCommand(
+	ctx context.Context,
+	command string,
+	opts CommandOptions,
+	pidChan chan int,
+	cmdChan chan *exec.Cmd,
+	callback func(chan string),
+) error {
+	// result of run is buffered, so goroutine running command finishes even if RunCommand returned on cancel
+	errChan := make(chan error, 1)
 	outChan := make(chan string)
 
-	var isOutChanClosed int32
+	// every run has its own script file readable by owner only, so concurrently started scripts don't overwrite
+	// files of each other and other users can't rewrite script before it's read
+	file, err := os.CreateTemp("", "pg-start-script-*.sh")
+	if err != nil {
+		close(pidChan)
+		close(cmdChan)
 
-	defer close(outChan)
+		return err
+	}
 
-	handleErr := func(err error) {
-		errChan <- err
+	filename := file.Name()
+
+	_, err = file.WriteString(command)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(filename)
 
 		close(pidChan)
 		close(cmdChan)
+
+		return err
 	}
 
-	filename := fmt.Sprintf("./%v_temp_script.sh", time.Now().Unix())
+	// script file is removed once process exits, interpreter reads it while running
+	handleErr := func(err error) {
+		_ = os.Remove(filename)
 
-	// create temp file
-	if err := os.WriteFile(filename, []byte(command), 0666); err != nil {
-		handleErr(err)
-	}
+		close(outChan)
+		close(pidChan)
+		close(cmdChan)
 
-	// remove created file
-	defer os.Remove(filename)
+		errChan <- err
+	}
 
 	go func() {
 		go func() {
 			callback(outChan)
 		}()
 
-		cmd := exec.CommandContext(ctx, "/bin/sh", filename)
+		interpreter := opts.Interpreter
+		if interpreter == "" {
+			interpreter = defaultInterpreter
+		}
+
+		cmd := exec.CommandContext(ctx, interpreter, filename)
+
+		if len(opts.Env) != 0 {
+			cmd.Env = append(os.Environ(), opts.Env...)
+		}
+
+		if opts.RunAs != "" {
+			sysProcAttr, err := sysProcAttrForUser(opts.RunAs, filename)
+			if err != nil {
+				handleErr(err)
+
+				return
+			}
+
+			cmd.SysProcAttr = sysProcAttr
+		}
 
 		stdoutReader, err := cmd.StdoutPipe()
 		if err != nil {
 			handleErr(err)
+
+			return
 		}
 
 		stderrReader, err := cmd.StderrPipe()
 		if err != nil {
 			handleErr(err)
+
+			return
 		}
 
 		if err = cmd.Start(); err != nil {
 			handleErr(err)
+
+			return
 		}
 
 		pidChan <- cmd.Process.Pid
@@ -63,47 +111,42 @@ func RunCommand(ctx context.Context, command string, pidChan chan int, cmdChan c
 		cmdChan <- cmd
 		close(cmdChan)
 
-		scanner := bufio.NewScanner(stdoutReader)
-		for scanner.Scan() {
-			if isOutChanClosed == 1 {
-				break
-			}
+		// stdout and stderr are read concurrently, so process blocked writing to one of them while the other one
+		// is read doesn't hang, outChan is closed once both of them are read to the end
+		readers := &sync.WaitGroup{}
 
-			outChan <- scanner.Text()
-		}
+		for _, reader := range []io.Reader{stdoutReader, stderrReader} {
+			readers.Add(1)
 
-		scanner = bufio.NewScanner(stderrReader)
-		for scanner.Scan() {
-			if isOutChanClosed == 1 {
-				break
-			}
+			go func(reader io.Reader) {
+				defer readers.Done()
 
-			outChan <- scanner.Text()
-		}
+				scanner := bufio.NewScanner(reader)
+				for scanner.Scan() {
+					outChan <- scanner.Text()
+				}
 
-		if err = cmd.Wait(); err != nil {
-			errChan <- err
+				// rest of output after too long line is discarded, process doesn't block on full pipe anyway
+				_, _ = io.Copy(io.Discard, reader)
+			}(reader)
 		}
 
-		doneChan <- true
-	}()
+		readers.Wait()
+		close(outChan)
 
-	for {
-		select {
-		case <-ctx.Done():
-			atomic.AddInt32(&isOutChanClosed, 1)
+		// pipes are closed by Wait, so it's called once they are read
+		err = cmd.Wait()
 
-			return ErrContextCancelled
+		_ = os.Remove(filename)
 
-		case <-doneChan:
-			atomic.AddInt32(&isOutChanClosed, 1)
-
-			return nil
+		errChan <- err
+	}()
 
-		case err := <-errChan:
-			atomic.AddInt32(&isOutChanClosed, 1)
+	select {
+	case <-ctx.Done():
+		return ErrContextCancelled
 
-			return err
-		}
+	case err := <-errChan:
+		return err
 	}
 }
@@ -0,0 +1,25 @@
+package webhook
+
+import "time"
+
+// Payload is JSON body of webhook request sent on script lifecycle event
+type Payload struct {
+	// Event is one of script.started, script.finished, script.failed or script.stopped
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Script     Script    `json:"script"`
+}
+
+// Script is state of script at the moment of event, output is never sent
+type Script struct {
+	ID         int        `json:"id"`
+	Namespace  string     `json:"namespace"`
+	Command    string     `json:"command"`
+	Status     string     `json:"status"`
+	PID        int        `json:"pid"`
+	ExitCode   *int       `json:"exit_code"`
+	Tags       []string   `json:"tags"`
+	CreatedBy  *string    `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
@@ -0,0 +1,136 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"strconv"
+	"time"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+)
+
+const approvalCommand = "echo needs-approval"
+
+func contextOf(subject string, role entity.Role) context.Context {
+	return auth.WithPrincipal(context.Background(), &entity.Principal{Subject: subject, Roles: []entity.Role{role}})
+}
+
+func (s *Suite) createPendingScript(ctx context.Context) *entity.Script {
+	created, err := s.service.CreateScript(ctx, entity.Script{Command: approvalCommand})
+	s.NoError(err)
+
+	s.Equal(entity.ScriptStatusPendingApproval, created.Status)
+
+	return created
+}
+
+func (s *Suite) TestCreateScriptRequiringApproval() {
+	body, err := json.Marshal(request.CreateScript{Command: approvalCommand})
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "", bytes.NewBuffer(body))
+
+	s.Equal(http.StatusAccepted, recorder.Result().StatusCode)
+
+	var resp response.CreateScript
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+	s.Equal(string(entity.ScriptStatusPendingApproval), resp.Status)
+
+	script, err := s.repository.GetScript(context.Background(), "", resp.ID)
+	s.NoError(err)
+
+	// script is stored, but not started
+	s.False(script.IsRunning)
+	s.Equal(0, script.PID)
+	s.NotNil(script.ApprovalExpiresAt)
+	s.NotNil(script.PolicyRule)
+	s.Equal("test-requires-approval", *script.PolicyRule)
+
+	_ = deleteScriptFromDB(s.db, resp.ID)
+}
+
+func (s *Suite) TestApproveScript() {
+	creator := contextOf("alice", entity.RoleOperator)
+	approver := contextOf("bob", entity.RoleOperator)
+
+	created := s.createPendingScript(creator)
+
+	// creator can't approve own script, viewer can't approve at all
+	_, err := s.service.ApproveScript(creator, created.ID)
+	s.ErrorIs(err, scriptservice.ErrSelfApproval)
+
+	_, err = s.service.ApproveScript(contextOf("carol", entity.RoleViewer), created.ID)
+	s.ErrorIs(err, scriptservice.ErrForbidden)
+
+	approved, err := s.service.ApproveScript(approver, created.ID)
+	s.NoError(err)
+
+	s.NotNil(approved.ReviewedBy)
+	s.Equal("bob", *approved.ReviewedBy)
+
+	time.Sleep(1 * time.Second)
+
+	script, err := s.repository.GetScript(context.Background(), "", created.ID)
+	s.NoError(err)
+
+	// script is run through the normal path
+	s.Equal(entity.ScriptStatusFinished, script.Status)
+	s.Equal("needs-approval\n", script.Output)
+
+	// script can't be approved twice
+	_, err = s.service.ApproveScript(approver, created.ID)
+	s.ErrorIs(err, scriptservice.ErrNotPendingApproval)
+
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestRejectScript() {
+	created := s.createPendingScript(contextOf("alice", entity.RoleOperator))
+
+	body, err := json.Marshal(request.RejectScript{Reason: "not now"})
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "/"+strconv.Itoa(created.ID)+"/reject", bytes.NewBuffer(body))
+
+	s.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+
+	script, err := s.repository.GetScript(context.Background(), "", created.ID)
+	s.NoError(err)
+
+	s.Equal(entity.ScriptStatusRejected, script.Status)
+	s.NotNil(script.RejectReason)
+	s.Equal("not now", *script.RejectReason)
+
+	// rejected script can't be approved
+	recorder = s.serveV2("POST", "/"+strconv.Itoa(created.ID)+"/approve", nil)
+
+	s.Equal(http.StatusConflict, recorder.Result().StatusCode)
+
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestExpirePendingScripts() {
+	created := s.createPendingScript(contextOf("alice", entity.RoleOperator))
+
+	_, err := s.db.Exec("UPDATE script SET approval_expires_at = now() - interval '1 minute' WHERE id = $1", created.ID)
+	s.NoError(err)
+
+	_, err = s.service.ApproveScript(contextOf("bob", entity.RoleOperator), created.ID)
+	s.ErrorIs(err, scriptservice.ErrApprovalExpired)
+
+	s.NoError(s.service.ExpirePendingScripts(context.Background()))
+
+	script, err := s.repository.GetScript(context.Background(), "", created.ID)
+	s.NoError(err)
+
+	s.Equal(entity.ScriptStatusExpired, script.Status)
+
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
@@ -0,0 +1,177 @@
+package script
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/internal/pkg/audit"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"strconv"
+
+	audithandler "pg-start-trainee-2024/internal/handler/audit"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	auditservice "pg-start-trainee-2024/internal/service/audit"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func auditContext(subject, requestID string) context.Context {
+	ctx := auth.WithPrincipal(context.Background(), &entity.Principal{
+		Subject:   subject,
+		Roles:     []entity.Role{entity.RoleAdmin},
+		Namespace: entity.GlobalNamespace,
+	})
+
+	return audit.WithRequestMeta(ctx, entity.RequestMeta{RequestID: requestID, ClientIP: "10.0.0.1"})
+}
+
+func (s *Suite) scriptAuditEvents(id int) []*entity.AuditEvent {
+	events, err := auditrepo.New(s.db).GetAuditEvents(context.Background(), entity.AuditFilter{
+		TargetType: entity.AuditTargetScript,
+		TargetID:   strconv.Itoa(id),
+	}, 0, 100)
+	s.NoError(err)
+
+	return events
+}
+
+func (s *Suite) webhookAuditEvents(id int) []*entity.AuditEvent {
+	events, err := auditrepo.New(s.db).GetAuditEvents(context.Background(), entity.AuditFilter{
+		TargetType: entity.AuditTargetWebhook,
+		TargetID:   strconv.Itoa(id),
+	}, 0, 100)
+	s.NoError(err)
+
+	return events
+}
+
+func (s *Suite) TestAuditCreateAndStopScript() {
+	created, err := s.service.CreateScript(auditContext(ownerSubject, "req-create"), entity.Script{Command: "sleep 5"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	s.NoError(s.service.StopScript(auditContext(otherSubject, "req-stop"), created.ID))
+
+	events := s.scriptAuditEvents(created.ID)
+	s.Len(events, 2)
+
+	// most recent first
+	stop, create := events[0], events[1]
+
+	s.Equal(entity.AuditActionStopScript, stop.Action)
+	s.Equal(otherSubject, *stop.Actor)
+	s.Equal("req-stop", *stop.RequestID)
+	s.Nil(stop.PayloadHash)
+
+	s.Equal(entity.AuditActionCreateScript, create.Action)
+	s.Equal(ownerSubject, *create.Actor)
+	s.Equal("req-create", *create.RequestID)
+	s.Equal("10.0.0.1", *create.ClientIP)
+	s.NotNil(create.PayloadHash)
+}
+
+func (s *Suite) TestAuditCreateAndDeleteWebhook() {
+	wh, err := s.webhooks.CreateWebhook(auditContext(ownerSubject, "req-create"), entity.Webhook{
+		Name:      "audited",
+		URL:       "https://example.com/hook",
+		Namespace: webhookTestNamespace,
+		Events:    []string{string(entity.ScriptEventFinished)},
+	})
+	s.NoError(err)
+
+	s.NoError(s.webhooks.DeleteWebhook(auditContext(otherSubject, "req-delete"), wh.ID))
+
+	events := s.webhookAuditEvents(wh.ID)
+	s.Len(events, 2)
+
+	// most recent first
+	deleted, created := events[0], events[1]
+
+	s.Equal(entity.AuditActionDeleteWebhook, deleted.Action)
+	s.Equal(otherSubject, *deleted.Actor)
+	s.Equal(webhookTestNamespace, deleted.Namespace)
+	s.Nil(deleted.PayloadHash)
+
+	s.Equal(entity.AuditActionCreateWebhook, created.Action)
+	s.Equal(ownerSubject, *created.Actor)
+	s.Equal("req-create", *created.RequestID)
+	s.NotNil(created.PayloadHash)
+
+	// webhook is already deleted
+	s.Error(s.webhooks.DeleteWebhook(auditContext(otherSubject, "req-delete-again"), wh.ID))
+	s.Len(s.webhookAuditEvents(wh.ID), 2)
+}
+
+func (s *Suite) TestAuditNotWrittenForFailedOperation() {
+	script := s.createScriptInNamespace(entity.DefaultNamespace)
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	// script is not running
+	s.Error(s.service.StopScript(auditContext(ownerSubject, "req"), script.ID))
+
+	s.Empty(s.scriptAuditEvents(script.ID))
+}
+
+func (s *Suite) TestAuditLogIsAppendOnly() {
+	created, err := s.service.CreateScript(auditContext(ownerSubject, "req"), entity.Script{Command: "true"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	events := s.scriptAuditEvents(created.ID)
+	s.Len(events, 1)
+
+	_, err = s.db.Exec("UPDATE audit_event SET actor = 'someone else' WHERE id = $1", events[0].ID)
+	s.Error(err)
+
+	_, err = s.db.Exec("DELETE FROM audit_event WHERE id = $1", events[0].ID)
+	s.Error(err)
+}
+
+func (s *Suite) TestAuditExport() {
+	created, err := s.service.CreateScript(auditContext(ownerSubject, "req"), entity.Script{Command: "true"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
+
+	handler := audithandler.New(auditservice.New(auditrepo.New(s.db)), logrus.New(), valid, 0, 100)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/export?target_type=script&target_id=%v", created.ID), nil)
+	rec := httptest.NewRecorder()
+
+	handler.Routes().ServeHTTP(rec, req)
+
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := 0
+
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var event response.AuditEvent
+
+		s.NoError(json.Unmarshal(scanner.Bytes(), &event))
+		s.Equal(string(entity.AuditActionCreateScript), event.Action)
+
+		lines++
+	}
+
+	s.Equal(1, lines)
+
+	req = httptest.NewRequest(http.MethodGet, "/?action=script.unknown", nil)
+	rec = httptest.NewRecorder()
+
+	handler.Routes().ServeHTTP(rec, req)
+
+	s.Equal(http.StatusBadRequest, rec.Code)
+}
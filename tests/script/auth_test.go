@@ -0,0 +1,157 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/router"
+
+	scripthandler "pg-start-trainee-2024/internal/handler/script"
+	apikeyrepo "pg-start-trainee-2024/internal/repository/postgres/apikey"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	apikeyservice "pg-start-trainee-2024/internal/service/apikey"
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func (s *Suite) newAPIKeyService() *apikeyservice.Service {
+	return apikeyservice.New(apikeyrepo.New(s.db), auditrepo.New(s.db), dbutils.NewTransactor(s.db), logrus.New(), "")
+}
+
+func (s *Suite) createAPIKey(service *apikeyservice.Service, scopes ...entity.Scope) (*entity.APIKey, string) {
+	key, plainKey, err := service.CreateAPIKey(context.Background(), entity.APIKey{Name: "test", Namespace: entity.DefaultNamespace, Scopes: scopes})
+	s.NoError(err)
+
+	return key, plainKey
+}
+
+func deleteAPIKeyFromDB(db *sqlx.DB, id int) error {
+	_, err := db.ExecContext(context.Background(), fmt.Sprintf("DELETE FROM api_key WHERE id = %v", id))
+
+	return err
+}
+
+// serveV2WithAuth serves request with routes configured the same way as in main, tokens may be nil
+func (s *Suite) serveV2WithAuth(apiKeys, tokens middleware.Authenticator, method, path, key string, body io.Reader) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, "/test/api/v2/scripts"+path, body)
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	logger := logrus.New()
+	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
+
+	handler := scripthandler.New(s.service, s.idempotency, logger, valid, s.config.DefaultOffset, s.config.DefaultLimit)
+
+	routers := make(map[string]chi.Router)
+
+	routers["/v2/scripts"] = handler.RoutesV2()
+
+	r := router.MakeRoutes("/test/api", routers, middleware.Authenticate(apiKeys, tokens, logger))
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func (s *Suite) TestAuthenticateAPIKey() {
+	apiKeyService := s.newAPIKeyService()
+
+	key, plainKey := s.createAPIKey(apiKeyService, entity.ScopeScriptsRead)
+
+	principal, err := apiKeyService.Authenticate(context.Background(), plainKey)
+	s.NoError(err)
+
+	s.Equal(key.ID, *principal.APIKeyID)
+	s.True(principal.HasScope(entity.ScopeScriptsRead))
+	s.False(principal.HasScope(entity.ScopeScriptsWrite))
+
+	// secret is stored hashed
+	s.NotContains(plainKey, key.SecretHash)
+
+	_, err = apiKeyService.Authenticate(context.Background(), plainKey+"x")
+	s.ErrorIs(err, apikeyservice.ErrInvalidAPIKey)
+
+	_ = deleteAPIKeyFromDB(s.db, key.ID)
+}
+
+func (s *Suite) TestRequestWithoutAPIKey() {
+	recorder := s.serveV2WithAuth(s.newAPIKeyService(), nil, "GET", "", "", nil)
+
+	s.Equal(http.StatusUnauthorized, recorder.Result().StatusCode)
+	s.Equal(handlerutils.ProblemContentType, recorder.Header().Get("Content-Type"))
+}
+
+func (s *Suite) TestRequestWithoutRequiredScope() {
+	apiKeyService := s.newAPIKeyService()
+
+	key, plainKey := s.createAPIKey(apiKeyService, entity.ScopeScriptsRead)
+
+	recorder := s.serveV2WithAuth(apiKeyService, nil, "GET", "", plainKey, nil)
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	body, err := json.Marshal(request.CreateScript{Command: "ls -la /"})
+	s.NoError(err)
+
+	recorder = s.serveV2WithAuth(apiKeyService, nil, "POST", "", plainKey, bytes.NewBuffer(body))
+	s.Equal(http.StatusForbidden, recorder.Result().StatusCode)
+
+	_ = deleteAPIKeyFromDB(s.db, key.ID)
+}
+
+func (s *Suite) TestCreateScriptRecordsAPIKey() {
+	apiKeyService := s.newAPIKeyService()
+
+	key, plainKey := s.createAPIKey(apiKeyService, entity.ScopeScriptsWrite)
+
+	body, err := json.Marshal(request.CreateScript{Command: "ls -la /"})
+	s.NoError(err)
+
+	recorder := s.serveV2WithAuth(apiKeyService, nil, "POST", "", plainKey, bytes.NewBuffer(body))
+	s.Equal(http.StatusCreated, recorder.Result().StatusCode)
+
+	var resp response.CreateScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	script, err := s.repository.GetScript(context.Background(), "", resp.ID)
+	s.NoError(err)
+
+	s.NotNil(script.APIKeyID)
+	s.Equal(key.ID, *script.APIKeyID)
+
+	_ = deleteScriptFromDB(s.db, resp.ID)
+	_ = deleteAPIKeyFromDB(s.db, key.ID)
+}
+
+func (s *Suite) TestRevokedAPIKey() {
+	apiKeyService := s.newAPIKeyService()
+
+	key, plainKey := s.createAPIKey(apiKeyService, entity.ScopeScriptsRead)
+
+	s.NoError(apiKeyService.RevokeAPIKey(context.Background(), key.ID))
+
+	recorder := s.serveV2WithAuth(apiKeyService, nil, "GET", "", plainKey, nil)
+	s.Equal(http.StatusUnauthorized, recorder.Result().StatusCode)
+
+	s.ErrorIs(apiKeyService.RevokeAPIKey(context.Background(), -1), apikeyservice.ErrNoSuchAPIKey)
+
+	_ = deleteAPIKeyFromDB(s.db, key.ID)
+}
@@ -0,0 +1,135 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+)
+
+const (
+	ownerSubject = "owner"
+	otherSubject = "other"
+)
+
+func principalWithRole(role entity.Role) *entity.Principal {
+	roles := make([]entity.Role, 0, 1)
+	if role != "" {
+		roles = append(roles, role)
+	}
+
+	return &entity.Principal{Subject: ownerSubject, Roles: roles}
+}
+
+// createScriptOwnedBy creates not running script directly in db
+func (s *Suite) createScriptOwnedBy(subject string) *entity.Script {
+	script, err := s.repository.CreateScript(context.Background(), entity.Script{
+		Command:   "echo test",
+		Status:    entity.ScriptStatusFinished,
+		CreatedBy: &subject,
+	})
+	s.NoError(err)
+
+	return script
+}
+
+func (s *Suite) TestAuthorizationMatrix() {
+	type operation struct {
+		name string
+		run  func(ctx context.Context) error
+	}
+
+	read := operation{"read", func(ctx context.Context) error {
+		script := s.createScriptOwnedBy(otherSubject)
+		defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+		_, err := s.service.GetScript(ctx, script.ID)
+
+		return err
+	}}
+
+	list := operation{"list", func(ctx context.Context) error {
+		_, err := s.service.GetAllScripts(ctx, entity.ScriptFilter{}, 0, 1)
+
+		return err
+	}}
+
+	create := operation{"create", func(ctx context.Context) error {
+		script, err := s.service.CreateScript(ctx, entity.Script{Command: "true"})
+		if err == nil {
+			_ = deleteScriptFromDB(s.db, script.ID)
+		}
+
+		return err
+	}}
+
+	stop := func(owner string) operation {
+		return operation{"stop script of " + owner, func(ctx context.Context) error {
+			script := s.createScriptOwnedBy(owner)
+			defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+			// script is not running, so authorized stop fails with another error
+			err := s.service.StopScript(ctx, script.ID)
+			if err != nil && !errors.Is(err, scriptservice.ErrNoSuchRunningScript) {
+				return err
+			}
+
+			return nil
+		}}
+	}
+
+	del := func(owner string) operation {
+		return operation{"delete script of " + owner, func(ctx context.Context) error {
+			script := s.createScriptOwnedBy(owner)
+			defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+			return s.service.DeleteScript(ctx, script.ID)
+		}}
+	}
+
+	tests := []struct {
+		role    entity.Role
+		allowed []bool // read, list, create, stop own, stop other, delete own, delete other
+	}{
+		{role: "", allowed: []bool{false, false, false, false, false, false, false}},
+		{role: entity.RoleViewer, allowed: []bool{true, true, false, false, false, false, false}},
+		{role: entity.RoleOperator, allowed: []bool{true, true, true, true, false, true, false}},
+		{role: entity.RoleAdmin, allowed: []bool{true, true, true, true, true, true, true}},
+	}
+
+	operations := []operation{read, list, create, stop(ownerSubject), stop(otherSubject), del(ownerSubject), del(otherSubject)}
+
+	for _, tt := range tests {
+		ctx := auth.WithPrincipal(context.Background(), principalWithRole(tt.role))
+
+		for i, op := range operations {
+			err := op.run(ctx)
+
+			if tt.allowed[i] {
+				s.NoErrorf(err, "role '%v' must be allowed to %v", tt.role, op.name)
+			} else {
+				s.ErrorIsf(err, scriptservice.ErrForbidden, "role '%v' must not be allowed to %v", tt.role, op.name)
+			}
+		}
+	}
+}
+
+func (s *Suite) TestRoleFromScopes() {
+	tests := []struct {
+		scopes []entity.Scope
+		role   entity.Role
+	}{
+		{scopes: nil, role: ""},
+		{scopes: []entity.Scope{entity.ScopeScriptsRead}, role: entity.RoleViewer},
+		{scopes: []entity.Scope{entity.ScopeScriptsRead, entity.ScopeScriptsWrite}, role: entity.RoleOperator},
+		{scopes: []entity.Scope{entity.ScopeAdmin}, role: entity.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		principal := entity.Principal{Scopes: tt.scopes}
+
+		s.Equal(tt.role, principal.Role())
+	}
+}
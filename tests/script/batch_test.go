@@ -0,0 +1,186 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func (s *Suite) serveBatch(operation string, batchReq any) (*http.Response, response.BatchResult) {
+	body, err := json.Marshal(batchReq)
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "/batch/"+operation, bytes.NewBuffer(body))
+
+	var resp response.BatchResult
+	if recorder.Code == http.StatusOK || recorder.Code == http.StatusMultiStatus {
+		s.NoError(json.Unmarshal(recorder.Body.Bytes(), &resp))
+	}
+
+	return recorder.Result(), resp
+}
+
+func (s *Suite) TestBatchCreateScriptsPartialFailure() {
+	result, resp := s.serveBatch("create", request.BatchCreateScripts{Scripts: []request.CreateScript{
+		{Command: "echo first"},
+		{Command: "echo second", Interpreter: "bash", Env: map[string]string{"1INVALID": "value"}},
+		{Command: "echo third"},
+	}})
+
+	s.Equal(http.StatusMultiStatus, result.StatusCode)
+	s.Equal(2, resp.Succeeded)
+	s.Equal(1, resp.Failed)
+	s.Require().Len(resp.Items, 3)
+
+	for _, item := range resp.Items {
+		if item.ID != nil {
+			_ = deleteScriptFromDB(s.db, *item.ID)
+		}
+	}
+
+	s.NotNil(resp.Items[0].ID)
+	s.Nil(resp.Items[0].Error)
+
+	s.Nil(resp.Items[1].ID)
+	s.Require().NotNil(resp.Items[1].Error)
+	s.Equal(handlerutils.ProblemTypeBadRequest, resp.Items[1].Error.Type)
+
+	s.Equal(2, resp.Items[2].Index)
+	s.NotNil(resp.Items[2].ID)
+}
+
+func (s *Suite) TestBatchCreateScriptsRunOwnCommands() {
+	scripts := make([]request.CreateScript, 10)
+	for i := range scripts {
+		scripts[i] = request.CreateScript{Command: fmt.Sprintf("echo batch-output-%v", i)}
+	}
+
+	result, resp := s.serveBatch("create", request.BatchCreateScripts{Scripts: scripts})
+
+	s.Equal(http.StatusOK, result.StatusCode)
+	s.Require().Len(resp.Items, len(scripts))
+
+	// every script outputs its own command, scripts started at once don't share script file
+	for i, item := range resp.Items {
+		s.Require().NotNil(item.ID)
+
+		s.Eventually(func() bool {
+			script, err := s.repository.GetScript(context.Background(), "", *item.ID)
+
+			return err == nil && !script.IsRunning && script.Output != ""
+		}, 5*time.Second, 50*time.Millisecond)
+
+		script, err := s.repository.GetScript(context.Background(), "", *item.ID)
+		s.NoError(err)
+
+		s.Equal(fmt.Sprintf("batch-output-%v\n", i), script.Output)
+		s.Require().NotNil(script.ExitCode)
+		s.Equal(0, *script.ExitCode)
+
+		_ = deleteScriptFromDB(s.db, *item.ID)
+	}
+}
+
+func (s *Suite) TestBatchCreateInvalidScript() {
+	result, _ := s.serveBatch("create", request.BatchCreateScripts{Scripts: []request.CreateScript{{Command: ""}}})
+
+	s.Equal(http.StatusBadRequest, result.StatusCode)
+}
+
+func (s *Suite) TestBatchDeleteScriptsByIDs() {
+	// create scripts for testing
+	first := s.createScript("ls -la /")
+	second := s.createScript("ping google.com")
+
+	result, resp := s.serveBatch("delete", request.BatchScripts{IDs: []int{first.ID, 1<<31 - 1, second.ID}})
+
+	s.Equal(http.StatusMultiStatus, result.StatusCode)
+	s.Equal(2, resp.Succeeded)
+	s.Equal(1, resp.Failed)
+	s.Require().Len(resp.Items, 3)
+	s.Require().NotNil(resp.Items[1].Error)
+	s.Equal(handlerutils.ProblemTypeNotFound, resp.Items[1].Error.Type)
+
+	// check that scripts deleted from db
+	_, err := getScriptFromDB(s.db, first.ID)
+	s.Error(err)
+
+	_, err = getScriptFromDB(s.db, second.ID)
+	s.Error(err)
+
+	// check that process of deleted script stopped
+	s.runCheckPidExistsScript(second.PID, "")
+}
+
+func (s *Suite) TestBatchStopScriptsByFilter() {
+	// create scripts for testing
+	first := s.createScriptWithTags("ping google.com", "batch-test")
+	second := s.createScriptWithTags("ping google.com", "batch-test")
+	other := s.createScriptWithTags("ping google.com", "batch-other")
+
+	isRunning := true
+
+	result, resp := s.serveBatch("stop", request.BatchScripts{Filter: &request.BatchFilter{
+		ScriptFilter: request.ScriptFilter{IsRunning: &isRunning, Tags: []string{"batch-test"}},
+	}})
+
+	s.Equal(http.StatusOK, result.StatusCode)
+	s.Equal(2, resp.Succeeded)
+	s.Equal(0, resp.Failed)
+
+	// check that processes of matched scripts stopped only
+	s.runCheckPidExistsScript(first.PID, "")
+	s.runCheckPidExistsScript(second.PID, "")
+
+	script, err := s.service.GetScript(context.Background(), other.ID)
+	s.NoError(err)
+	s.True(script.IsRunning)
+
+	s.NoError(s.service.StopScript(context.Background(), other.ID))
+
+	// delete scripts from db
+	for _, id := range []int{first.ID, second.ID, other.ID} {
+		_ = deleteScriptFromDB(s.db, id)
+	}
+}
+
+func (s *Suite) TestBatchDeleteScriptsOlderThan() {
+	// create script for testing
+	created := s.createScriptWithTags("ls -la /", "batch-older-test")
+
+	// script is not old enough
+	result, resp := s.serveBatch("delete", request.BatchScripts{Filter: &request.BatchFilter{
+		ScriptFilter: request.ScriptFilter{Tags: []string{"batch-older-test"}},
+		OlderThan:    "1h",
+	}})
+
+	s.Equal(http.StatusOK, result.StatusCode)
+	s.Empty(resp.Items)
+
+	_, err := getScriptFromDB(s.db, created.ID)
+	s.NoError(err)
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestBatchInvalidSelection() {
+	for name, batchReq := range map[string]request.BatchScripts{
+		"nothing":      {},
+		"both":         {IDs: []int{1}, Filter: &request.BatchFilter{OlderThan: "1h"}},
+		"empty":        {Filter: &request.BatchFilter{}},
+		"bad_duration": {Filter: &request.BatchFilter{OlderThan: "yesterday"}},
+	} {
+		result, _ := s.serveBatch("delete", batchReq)
+
+		s.Equal(http.StatusBadRequest, result.StatusCode, name)
+	}
+}
@@ -0,0 +1,235 @@
+package script
+
+import (
+	"context"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/service/bus"
+	"strconv"
+	"time"
+
+	scriptrepo "pg-start-trainee-2024/internal/repository/postgres/script"
+)
+
+const listenerRetryInterval = 100 * time.Millisecond
+
+// startChangeBus runs bus listening to script changes until returned function is called,
+// bus is returned as soon as it's listening
+func (s *Suite) startChangeBus() (<-chan entity.ScriptChange, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	changeBus := bus.New(scriptrepo.NewChangeListener(s.config.Postgres.ConnectionURL(), listenerRetryInterval, s.logger), s.logger)
+
+	go func() { _ = changeBus.Run(ctx) }()
+
+	changes, unsubscribe := changeBus.Subscribe(1000)
+
+	s.waitForListening(changes)
+
+	return changes, func() {
+		cancel()
+		unsubscribe()
+	}
+}
+
+// waitForListening notifies of change of not existing script until it's received
+func (s *Suite) waitForListening(changes <-chan entity.ScriptChange) {
+	s.Eventually(func() bool {
+		_, err := s.db.Exec(`SELECT pg_notify($1, '{"op":"updated","id":-1}')`, scriptrepo.ScriptChangesChannel)
+		s.NoError(err)
+
+		for {
+			select {
+			case change := <-changes:
+				if change.ID == -1 {
+					return true
+				}
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// drain probes sent before listening was noticed
+	for {
+		select {
+		case <-changes:
+		case <-time.After(100 * time.Millisecond):
+			return
+		}
+	}
+}
+
+// nextChangeOf returns next change of script with id, other changes are skipped
+func (s *Suite) nextChangeOf(changes <-chan entity.ScriptChange, id int) entity.ScriptChange {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case change := <-changes:
+			if change.ID == id {
+				return change
+			}
+		case <-timeout:
+			s.FailNow("change of script is not received", "script %v", id)
+
+			return entity.ScriptChange{}
+		}
+	}
+}
+
+func (s *Suite) TestScriptChangesNotified() {
+	changes, stop := s.startChangeBus()
+	defer stop()
+
+	ctx := context.Background()
+
+	script, err := s.repository.CreateScript(ctx, entity.Script{Namespace: "changes", Command: "echo changes"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	_, err = s.repository.UpdateScriptOutput(ctx, script.ID, "changes\n")
+	s.NoError(err)
+
+	_, err = s.repository.FinishScript(ctx, script.ID, entity.ScriptStatusFinished, nil)
+	s.NoError(err)
+
+	_, err = s.repository.DeleteScript(ctx, "", script.ID)
+	s.NoError(err)
+
+	created := s.nextChangeOf(changes, script.ID)
+	s.Equal(entity.ScriptChangeCreated, created.Op)
+	s.Equal("changes", created.Namespace)
+	s.Equal(entity.ScriptStatusRunning, created.Status)
+
+	// appended output is not notified, the next change is finish
+	finished := s.nextChangeOf(changes, script.ID)
+	s.Equal(entity.ScriptChangeFinished, finished.Op)
+	s.Equal(entity.ScriptStatusFinished, finished.Status)
+	s.False(finished.IsRunning)
+
+	s.Equal(entity.ScriptChangeDeleted, s.nextChangeOf(changes, script.ID).Op)
+}
+
+func (s *Suite) TestScriptChangesListenerReconnects() {
+	changes, stop := s.startChangeBus()
+	defer stop()
+
+	// drop connection of listener as if database was restarted
+	_, err := s.db.Exec(`SELECT pg_terminate_backend(pid) FROM pg_stat_activity 
+                        WHERE pid <> pg_backend_pid() AND query LIKE 'LISTEN %'`)
+	s.NoError(err)
+
+	s.Equal(entity.ScriptChangeResync, s.nextChangeOf(changes, 0).Op)
+
+	script, err := s.repository.CreateScript(context.Background(), entity.Script{Command: "echo reconnected"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.Equal(entity.ScriptChangeCreated, s.nextChangeOf(changes, script.ID).Op)
+}
+
+func (s *Suite) TestScriptFinishedElsewhereIsCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan entity.ScriptChange)
+	go s.service.WatchScriptChanges(ctx, changes)
+
+	script := s.createScript("sleep 30")
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	// as if script was stopped by another replica
+	changes <- entity.ScriptChange{Op: entity.ScriptChangeFinished, ID: script.ID}
+
+	s.Eventually(func() bool {
+		scpt, err := getScriptFromDB(s.db, script.ID)
+
+		return err == nil && !scpt.IsRunning && scpt.Status == entity.ScriptStatusStopped
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+// changesListener passes its changes to handle at once
+type changesListener []entity.ScriptChange
+
+func (l changesListener) Listen(_ context.Context, handle func(change entity.ScriptChange)) error {
+	for _, change := range l {
+		handle(change)
+	}
+
+	return nil
+}
+
+func (s *Suite) TestSlowSubscriberGetsResync() {
+	listener := changesListener{
+		{Op: entity.ScriptChangeCreated, ID: 1},
+		{Op: entity.ScriptChangeUpdated, ID: 1},
+		{Op: entity.ScriptChangeFinished, ID: 1},
+	}
+
+	changeBus := bus.New(listener, s.logger)
+
+	changes, unsubscribe := changeBus.Subscribe(2)
+	defer unsubscribe()
+
+	s.NoError(changeBus.Run(context.Background()))
+
+	// finished change doesn't fit, so changes are replaced with resync instead of finished change being dropped
+	s.Equal(entity.ScriptChangeResync, (<-changes).Op)
+	s.Empty(changes)
+}
+
+func (s *Suite) TestUnbufferedSubscriberDoesNotBlockBus() {
+	listener := changesListener{
+		{Op: entity.ScriptChangeCreated, ID: 1},
+		{Op: entity.ScriptChangeFinished, ID: 1},
+	}
+
+	changeBus := bus.New(listener, s.logger)
+
+	// unbuffered subscription would block publishing resync while nobody receives
+	changes, unsubscribe := changeBus.Subscribe(0)
+	defer unsubscribe()
+
+	done := make(chan error, 1)
+	go func() { done <- changeBus.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		s.NoError(err)
+	case <-time.After(5 * time.Second):
+		s.FailNow("bus is blocked by subscriber")
+	}
+
+	s.Equal(entity.ScriptChangeResync, (<-changes).Op)
+}
+
+func (s *Suite) TestScriptStoppedElsewhereIsCancelledOnResync() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan entity.ScriptChange)
+	go s.service.WatchScriptChanges(ctx, changes)
+
+	script := s.createScript("sleep 30")
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.Eventually(func() bool {
+		_, exist := s.cache.Get(strconv.Itoa(script.ID))
+
+		return exist
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// as if stop was requested by another replica while its change was missed
+	_, err := s.db.Exec("UPDATE script SET stop_requested_at = now() WHERE id = $1", script.ID)
+	s.NoError(err)
+
+	changes <- entity.ScriptChange{Op: entity.ScriptChangeResync}
+
+	s.Eventually(func() bool {
+		scpt, err := getScriptFromDB(s.db, script.ID)
+
+		return err == nil && !scpt.IsRunning && scpt.Status == entity.ScriptStatusStopped
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,206 @@
+package script
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/pkg/client"
+	"pg-start-trainee-2024/pkg/router"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+// newClientServer serves v1 routes as server does, handlers wrapping router may be passed to break requests
+func (s *Suite) newClientServer(wrap ...func(http.Handler) http.Handler) *httptest.Server {
+	routers := make(map[string]chi.Router)
+
+	routers["v1/script"] = s.handler.Routes()
+
+	var h http.Handler = router.MakeRoutes("/pg-start-trainee/api/", routers)
+	for _, w := range wrap {
+		h = w(h)
+	}
+
+	return httptest.NewServer(h)
+}
+
+func (s *Suite) newClient(url string, opts client.Options) *client.Client {
+	opts.BaseBackoff = 10 * time.Millisecond
+	opts.PollInterval = 100 * time.Millisecond
+
+	return client.New(url, opts)
+}
+
+func (s *Suite) TestClientScriptLifecycle() {
+	server := s.newClientServer()
+	defer server.Close()
+
+	c := s.newClient(server.URL, client.Options{})
+	ctx := context.Background()
+
+	created, err := c.CreateScript(ctx, client.CreateScriptRequest{Command: "sleep 30", Tags: []string{"sdk"}})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	s.Equal(client.StatusRunning, created.Status)
+
+	script, err := c.GetScript(ctx, created.ID)
+	s.NoError(err)
+
+	s.Equal("sleep 30", script.Command)
+	s.True(script.IsRunning)
+	s.False(script.Done())
+
+	scripts, err := c.GetAllScripts(ctx, client.Filter{Tags: []string{"sdk"}, Statuses: []string{client.StatusRunning}}, 0, 10)
+	s.NoError(err)
+
+	s.Len(scripts, 1)
+	s.Equal(created.ID, scripts[0].ID)
+
+	s.NoError(c.StopScript(ctx, created.ID))
+
+	script, err = c.Wait(ctx, created.ID)
+	s.NoError(err)
+
+	s.Equal(client.StatusStopped, script.Status)
+
+	// stopping script again is reported with problem type
+	err = c.StopScript(ctx, created.ID)
+	s.True(client.IsProblem(err, handlerutils.ProblemTypeInvalidState))
+
+	s.NoError(c.DeleteScript(ctx, created.ID))
+
+	_, err = c.GetScript(ctx, created.ID)
+	s.True(client.IsProblem(err, handlerutils.ProblemTypeNotFound))
+}
+
+func (s *Suite) TestClientCreateInvalidScript() {
+	server := s.newClientServer()
+	defer server.Close()
+
+	_, err := s.newClient(server.URL, client.Options{}).CreateScript(context.Background(), client.CreateScriptRequest{})
+	s.True(client.IsProblem(err, handlerutils.ProblemTypeValidationFailed))
+}
+
+func (s *Suite) TestClientOutputAndWait() {
+	server := s.newClientServer()
+	defer server.Close()
+
+	c := s.newClient(server.URL, client.Options{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, err := c.CreateScript(ctx, client.CreateScriptRequest{Command: "echo first; sleep 1; echo second; exit 3"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	var output strings.Builder
+
+	stream := c.Output(ctx, created.ID)
+	for stream.Next() {
+		output.WriteString(stream.Chunk())
+	}
+
+	s.NoError(stream.Err())
+	s.Equal("first\nsecond\n", output.String())
+	s.True(stream.Script().Done())
+
+	script, err := c.Wait(ctx, created.ID)
+	s.NoError(err)
+
+	s.Equal(client.StatusFailed, script.Status)
+	s.Equal(3, *script.ExitCode)
+}
+
+// failFirst responds with status to the first n requests with method
+func failFirst(method string, n int32, status int, attempts *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == method && attempts.Add(1) <= n {
+				rw.WriteHeader(status)
+
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+func (s *Suite) TestClientRetriesTransientErrors() {
+	created := s.createScript("echo retried")
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	var attempts atomic.Int32
+
+	server := s.newClientServer(failFirst(http.MethodGet, 2, http.StatusServiceUnavailable, &attempts))
+	defer server.Close()
+
+	script, err := s.newClient(server.URL, client.Options{}).GetScript(context.Background(), created.ID)
+	s.NoError(err)
+
+	s.Equal(created.ID, script.ID)
+	s.Equal(int32(3), attempts.Load())
+
+	// no retries are made if they are disabled
+	attempts.Store(0)
+
+	_, err = s.newClient(server.URL, client.Options{MaxRetries: -1}).GetScript(context.Background(), created.ID)
+	s.ErrorIs(err, client.ErrUnexpectedResponse)
+	s.Equal(int32(1), attempts.Load())
+}
+
+func (s *Suite) TestClientDoesNotRetryCreation() {
+	var attempts atomic.Int32
+
+	server := s.newClientServer(failFirst(http.MethodPost, 1, http.StatusBadGateway, &attempts))
+	defer server.Close()
+
+	// server may have created script before proxy failed, so creation isn't retried
+	_, err := s.newClient(server.URL, client.Options{}).CreateScript(context.Background(), client.CreateScriptRequest{Command: "echo once"})
+	s.ErrorIs(err, client.ErrUnexpectedResponse)
+	s.Equal(int32(1), attempts.Load())
+}
+
+func (s *Suite) TestClientAttemptTimeout() {
+	created := s.createScript("echo slow")
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	var attempts atomic.Int32
+
+	slowFirst := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if attempts.Add(1) == 1 {
+				time.Sleep(500 * time.Millisecond)
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+
+	server := s.newClientServer(slowFirst)
+	defer server.Close()
+
+	// the first attempt times out and the second one succeeds
+	script, err := s.newClient(server.URL, client.Options{Timeout: 200 * time.Millisecond}).GetScript(context.Background(), created.ID)
+	s.NoError(err)
+
+	s.Equal(created.ID, script.ID)
+	s.Equal(int32(2), attempts.Load())
+
+	// deadline of context limits all attempts
+	attempts.Store(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = s.newClient(server.URL, client.Options{}).GetScript(ctx, created.ID)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
@@ -14,9 +14,16 @@ import (
 	"pg-start-trainee-2024/domain/entity"
 	"pg-start-trainee-2024/internal/handler/request"
 	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/internal/service/policy"
 	"pg-start-trainee-2024/pkg/router"
 	"strconv"
 	"strings"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
 )
 
 func getScriptFromDB(db *sqlx.DB, id int) (*entity.Script, error) {
@@ -244,3 +251,82 @@ func (s *Suite) TestCreateInvalidScript() {
 	// delete script from db
 	_ = deleteScriptFromDB(s.db, resp.ID)
 }
+
+// newServiceWithOutputBuffer creates service running scripts locally which flushes output every n lines
+func (s *Suite) newServiceWithOutputBuffer(n int) *scriptservice.Service {
+	policyEngine, err := policy.New(entity.PolicyAction(s.config.Policy.DefaultAction), s.config.Policy.PolicyRules(), s.config.Policy.RunAsUsers)
+	s.NoError(err)
+
+	return scriptservice.New(
+		s.repository,
+		auditrepo.New(s.db),
+		dbutils.NewTransactor(s.db),
+		gocache.New(gocache.NoExpiration, gocache.NoExpiration),
+		policyEngine,
+		s.webhooks,
+		s.config.Quotas.NamespaceQuotas(),
+		s.logger,
+		scriptservice.ExecutionOptions{Mode: scriptservice.ExecuteLocally},
+		n,
+		time.Duration(s.config.Service.ApprovalTTL)*time.Second,
+	)
+}
+
+func (s *Suite) TestCreateScriptKeepsEveryOutputLine() {
+	service := s.newServiceWithOutputBuffer(4)
+
+	// 13 lines are flushed as 3 full buffers and the rest, the last line has no trailing newline
+	created, err := service.CreateScript(context.Background(), entity.Script{
+		Command: "seq 1 10; sleep 1; seq 11 13; printf partial",
+	})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	var expected strings.Builder
+	for i := 1; i <= 13; i++ {
+		expected.WriteString(fmt.Sprintf("%v\n", i))
+	}
+
+	expected.WriteString("partial\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var streamed strings.Builder
+
+	chunks := 0
+
+	s.NoError(service.WatchOutput(ctx, created.ID, func(output string) error {
+		streamed.WriteString(output)
+		chunks++
+
+		return nil
+	}))
+
+	s.Equal(expected.String(), streamed.String())
+	s.Greater(chunks, 1, "output must be streamed as it's flushed")
+
+	script, err := getScriptFromDB(s.db, created.ID)
+	s.NoError(err)
+
+	s.Equal(expected.String(), script.Output)
+}
+
+func (s *Suite) TestCreateScriptWritingMuchToStderrFinishes() {
+	// stderr output larger than pipe buffer is written before stdout, so both of them must be read at once
+	created, err := s.service.CreateScript(context.Background(), entity.Script{
+		Command: "seq 1 20000 >&2; echo done",
+	})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	// the last output is stored right after script finishes
+	s.Eventually(func() bool {
+		script, err := getScriptFromDB(s.db, created.ID)
+
+		return err == nil && script.Status == entity.ScriptStatusFinished &&
+			strings.Contains(script.Output, "20000\n") && strings.Contains(script.Output, "done\n")
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -26,7 +26,7 @@ func (s *Suite) TestDeleteNotExistingScript() {
 	recorder := httptest.NewRecorder()
 	r.ServeHTTP(recorder, req)
 
-	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
 }
 
 func (s *Suite) TestDeleteExistingScript() {
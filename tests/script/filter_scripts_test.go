@@ -0,0 +1,134 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/router"
+	"time"
+)
+
+func (s *Suite) getAllScriptsWithQuery(query url.Values) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", "/test/api/script/all", nil)
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+
+	req.URL.RawQuery = query.Encode()
+
+	routers := make(map[string]chi.Router)
+
+	routers["/script"] = s.handler.Routes()
+
+	r := router.MakeRoutes("/test/api", routers)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func (s *Suite) createScriptWithTags(command string, tags ...string) *entity.Script {
+	created, err := s.service.CreateScript(context.Background(), entity.Script{Command: command, Tags: tags})
+	s.NoError(err)
+
+	return created
+}
+
+func (s *Suite) TestGetScriptsFilterByTagsAndExitCode() {
+	// create scripts for testing
+	failed := s.createScriptWithTags("exit 3", "filter-test", "failing")
+	succeeded := s.createScriptWithTags("echo ok", "filter-test")
+
+	// wait some time for processes to exit
+	time.Sleep(1 * time.Second)
+
+	// test
+	recorder := s.getAllScriptsWithQuery(url.Values{
+		"tags":      {"filter-test"},
+		"status":    {"failed"},
+		"exit_code": {"3"},
+	})
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.GetScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	if s.Len(resp, 1) {
+		s.Equal(failed.ID, resp[0].ID)
+		s.Equal(string(entity.ScriptStatusFailed), resp[0].Status)
+		s.ElementsMatch([]string{"filter-test", "failing"}, resp[0].Tags)
+		s.NotNil(resp[0].FinishedAt)
+	}
+
+	// delete scripts from db
+	_ = deleteScriptFromDB(s.db, failed.ID)
+	_ = deleteScriptFromDB(s.db, succeeded.ID)
+}
+
+func (s *Suite) TestGetScriptsFilterByCommandSortedDesc() {
+	// create scripts for testing
+	first := s.createScriptWithTags("echo filter-by-command")
+	second := s.createScriptWithTags("echo FILTER-BY-COMMAND")
+
+	// test
+	recorder := s.getAllScriptsWithQuery(url.Values{
+		"command": {"filter-by-command"},
+		"sort":    {"id"},
+		"order":   {"desc"},
+	})
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.GetScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	if s.Len(resp, 2) {
+		s.Equal(second.ID, resp[0].ID)
+		s.Equal(first.ID, resp[1].ID)
+	}
+
+	// delete scripts from db
+	_ = deleteScriptFromDB(s.db, first.ID)
+	_ = deleteScriptFromDB(s.db, second.ID)
+}
+
+func (s *Suite) TestGetScriptsFilterByCreatedAtRange() {
+	recorder := s.getAllScriptsWithQuery(url.Values{
+		"created_from": {time.Now().Add(time.Hour).Format(time.RFC3339)},
+	})
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.GetScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	s.Empty(resp)
+}
+
+func (s *Suite) TestGetScriptsInvalidFilter() {
+	invalid := []url.Values{
+		{"status": {"sleeping"}},
+		{"is_running": {"maybe"}},
+		{"exit_code": {"zero"}},
+		{"sort": {"output"}},
+		{"order": {"random"}},
+		{"created_from": {"yesterday"}},
+		{
+			"created_from": {time.Now().Format(time.RFC3339)},
+			"created_to":   {time.Now().Add(-time.Hour).Format(time.RFC3339)},
+		},
+	}
+
+	for _, query := range invalid {
+		recorder := s.getAllScriptsWithQuery(query)
+
+		s.Equal(http.StatusBadRequest, recorder.Result().StatusCode, query.Encode())
+	}
+}
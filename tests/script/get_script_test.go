@@ -43,7 +43,7 @@ func (s *Suite) TestGetNotExistingScript() {
 	recorder := httptest.NewRecorder()
 	r.ServeHTTP(recorder, req)
 
-	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
 }
 
 func (s *Suite) TestGetShortScript() {
@@ -0,0 +1,86 @@
+package script
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+)
+
+func (s *Suite) getScriptsPage(cursor string, limit string) response.ScriptsPage {
+	recorder := s.getAllScriptsWithQuery(url.Values{
+		"tags":   {"cursor-test"},
+		"cursor": {cursor},
+		"limit":  {limit},
+	})
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp response.ScriptsPage
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	return resp
+}
+
+func pageIDs(page response.ScriptsPage) []int {
+	ids := make([]int, 0, len(page.Items))
+
+	for _, item := range page.Items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+func (s *Suite) TestGetScriptsPages() {
+	// create scripts for testing
+	created := []*entity.Script{
+		s.createScriptWithTags("echo 1", "cursor-test"),
+		s.createScriptWithTags("echo 2", "cursor-test"),
+		s.createScriptWithTags("echo 3", "cursor-test"),
+	}
+
+	// test
+	first := s.getScriptsPage("", "2")
+
+	s.Equal([]int{created[0].ID, created[1].ID}, pageIDs(first))
+	s.NotEmpty(first.NextCursor)
+	s.Empty(first.PrevCursor)
+
+	second := s.getScriptsPage(first.NextCursor, "2")
+
+	s.Equal([]int{created[2].ID}, pageIDs(second))
+	s.Empty(second.NextCursor)
+	s.NotEmpty(second.PrevCursor)
+
+	// script created after the first page was fetched must not shift pages
+	created = append(created, s.createScriptWithTags("echo 4", "cursor-test"))
+
+	back := s.getScriptsPage(second.PrevCursor, "2")
+
+	s.Equal([]int{created[0].ID, created[1].ID}, pageIDs(back))
+	s.Empty(back.PrevCursor)
+	s.NotEmpty(back.NextCursor)
+
+	next := s.getScriptsPage(back.NextCursor, "2")
+
+	s.Equal([]int{created[2].ID, created[3].ID}, pageIDs(next))
+
+	// delete scripts from db
+	for _, script := range created {
+		_ = deleteScriptFromDB(s.db, script.ID)
+	}
+}
+
+func (s *Suite) TestGetScriptsPageInvalidCursor() {
+	recorder := s.getAllScriptsWithQuery(url.Values{"cursor": {"not a cursor"}})
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestGetScriptsPageUnsupportedSort() {
+	recorder := s.getAllScriptsWithQuery(url.Values{"cursor": {""}, "sort": {"command"}})
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
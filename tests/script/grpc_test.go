@@ -0,0 +1,137 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/internal/handler/rpc"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	scriptv1 "pg-start-trainee-2024/pkg/pb/script/v1"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+const bufconnSize = 1024 * 1024
+
+// startGRPC serves gRPC API backed by suite's service on in-process listener until returned function is called
+func (s *Suite) startGRPC() (scriptv1.ScriptServiceClient, func()) {
+	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
+
+	listener := bufconn.Listen(bufconnSize)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.UnaryInterceptor(middleware.GRPCRequestMeta)),
+		grpc.ChainStreamInterceptor(middleware.StreamInterceptor(middleware.GRPCRequestMeta)),
+	)
+
+	rpc.New(s.service, s.logger, valid, s.config.DefaultLimit).Register(server)
+
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.NoError(err)
+
+	return scriptv1.NewScriptServiceClient(conn), func() {
+		_ = conn.Close()
+		server.Stop()
+	}
+}
+
+func (s *Suite) TestGRPCScriptLifecycle() {
+	client, stop := s.startGRPC()
+	defer stop()
+
+	ctx := context.Background()
+
+	created, err := client.CreateScript(ctx, &scriptv1.CreateScriptRequest{Command: "sleep 30", Tags: []string{"grpc"}})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, int(created.Id)) }()
+
+	got, err := client.GetScript(ctx, &scriptv1.GetScriptRequest{Id: created.Id})
+	s.NoError(err)
+
+	s.Equal("sleep 30", got.Command)
+	s.True(got.IsRunning)
+
+	list, err := client.ListScripts(ctx, &scriptv1.ListScriptsRequest{Tags: []string{"grpc"}})
+	s.NoError(err)
+
+	s.Len(list.Scripts, 1)
+	s.Equal(created.Id, list.Scripts[0].Id)
+
+	_, err = client.StopScript(ctx, &scriptv1.StopScriptRequest{Id: created.Id})
+	s.NoError(err)
+
+	got, err = client.GetScript(ctx, &scriptv1.GetScriptRequest{Id: created.Id})
+	s.NoError(err)
+
+	s.Equal("stopped", got.Status)
+	s.NotNil(got.FinishedAt)
+
+	// stopping script again is a failed precondition
+	_, err = client.StopScript(ctx, &scriptv1.StopScriptRequest{Id: created.Id})
+	s.Equal(codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.DeleteScript(ctx, &scriptv1.DeleteScriptRequest{Id: created.Id})
+	s.NoError(err)
+
+	_, err = client.GetScript(ctx, &scriptv1.GetScriptRequest{Id: created.Id})
+	s.Equal(codes.NotFound, status.Code(err))
+}
+
+func (s *Suite) TestGRPCInvalidRequest() {
+	client, stop := s.startGRPC()
+	defer stop()
+
+	_, err := client.CreateScript(context.Background(), &scriptv1.CreateScriptRequest{Command: ""})
+	s.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListScripts(context.Background(), &scriptv1.ListScriptsRequest{Statuses: []string{"unknown"}})
+	s.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func (s *Suite) TestGRPCWatchOutput() {
+	client, stop := s.startGRPC()
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, err := client.CreateScript(ctx, &scriptv1.CreateScriptRequest{Command: "echo first; sleep 1; echo second"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, int(created.Id)) }()
+
+	stream, err := client.WatchOutput(ctx, &scriptv1.WatchOutputRequest{Id: created.Id})
+	s.NoError(err)
+
+	var output strings.Builder
+
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+
+		s.Require().NoError(recvErr)
+
+		output.WriteString(chunk.Data)
+	}
+
+	s.Equal("first\nsecond\n", output.String())
+}
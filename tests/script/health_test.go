@@ -0,0 +1,75 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/response"
+
+	healthhandler "pg-start-trainee-2024/internal/handler/health"
+	healthrepo "pg-start-trainee-2024/internal/repository/postgres/health"
+	healthservice "pg-start-trainee-2024/internal/service/health"
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+func (s *Suite) newHealthService() *healthservice.Service {
+	version, err := dbutils.LatestMigrationVersion(s.config.Health.MigrationsDir)
+	s.NoError(err)
+
+	return healthservice.New(healthrepo.New(s.db), version)
+}
+
+func checkStatuses(report entity.HealthReport) map[string]entity.HealthStatus {
+	statuses := make(map[string]entity.HealthStatus, len(report.Checks))
+
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+
+	return statuses
+}
+
+func (s *Suite) TestReadiness() {
+	report := s.newHealthService().Ready(context.Background())
+
+	s.Equal(entity.HealthStatusUp, report.Status, report.Checks)
+	s.Len(report.Checks, 4)
+}
+
+func (s *Suite) TestReadinessWithUnexpectedMigrationVersion() {
+	report := healthservice.New(healthrepo.New(s.db), 1).Ready(context.Background())
+
+	s.Equal(entity.HealthStatusDown, report.Status)
+	s.Equal(entity.HealthStatusDown, checkStatuses(report)["migrations"])
+	s.Equal(entity.HealthStatusUp, checkStatuses(report)["database"])
+}
+
+func (s *Suite) TestReadinessFailsOnShutdown() {
+	service := s.newHealthService()
+	handler := healthhandler.New(service)
+
+	rec := httptest.NewRecorder()
+	handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	s.Equal(http.StatusOK, rec.Code)
+
+	service.StartShutdown()
+
+	rec = httptest.NewRecorder()
+	handler.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+
+	var report response.HealthReport
+
+	s.NoError(json.NewDecoder(rec.Body).Decode(&report))
+	s.Equal(string(entity.HealthStatusDown), report.Status)
+
+	// process is still alive
+	rec = httptest.NewRecorder()
+	handler.Live(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	s.Equal(http.StatusOK, rec.Code)
+}
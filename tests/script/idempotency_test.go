@@ -0,0 +1,200 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/router"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	idempotencyservice "pg-start-trainee-2024/internal/service/idempotency"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+// serveWithIdempotencyKey creates script with v1 or v2 route with Idempotency-Key header
+func (s *Suite) serveWithIdempotencyKey(path, key string, scriptReq request.CreateScript) *httptest.ResponseRecorder {
+	body, err := json.Marshal(scriptReq)
+	s.NoError(err)
+
+	req, err := http.NewRequest("POST", "/test/api"+path, bytes.NewBuffer(body))
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	routers := make(map[string]chi.Router)
+
+	routers["/v1/script"] = s.handler.Routes()
+	routers["/v2/scripts"] = s.handler.RoutesV2()
+
+	recorder := httptest.NewRecorder()
+	router.MakeRoutes("/test/api", routers).ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func (s *Suite) TestIdempotentCreateScriptIsReplayed() {
+	scriptReq := request.CreateScript{Command: "echo idempotent v1"}
+
+	first := s.serveWithIdempotencyKey("/v1/script", "key-v1", scriptReq)
+	s.Equal(http.StatusOK, first.Code)
+	s.Empty(first.Header().Get("Idempotent-Replayed"))
+
+	var created response.CreateScript
+	s.NoError(json.Unmarshal(first.Body.Bytes(), &created))
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	// retry gets the original response and script is not created again
+	retry := s.serveWithIdempotencyKey("/v1/script", "key-v1", scriptReq)
+	s.Equal(http.StatusOK, retry.Code)
+	s.Equal("true", retry.Header().Get("Idempotent-Replayed"))
+	s.JSONEq(first.Body.String(), retry.Body.String())
+
+	s.Equal(1, s.countScriptsWithCommand(scriptReq.Command))
+}
+
+func (s *Suite) TestIdempotentCreateScriptV2IsReplayed() {
+	scriptReq := request.CreateScript{Command: "echo idempotent v2"}
+
+	first := s.serveWithIdempotencyKey("/v2/scripts", "key-v2", scriptReq)
+	s.Equal(http.StatusCreated, first.Code)
+
+	var created response.CreateScript
+	s.NoError(json.Unmarshal(first.Body.Bytes(), &created))
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	retry := s.serveWithIdempotencyKey("/v2/scripts", "key-v2", scriptReq)
+	s.Equal(http.StatusCreated, retry.Code)
+	s.Equal("/test/api/v2/scripts/"+strconv.Itoa(created.ID), retry.Header().Get("Location"))
+	s.JSONEq(first.Body.String(), retry.Body.String())
+
+	// the same key identifies another request on another route
+	other := s.serveWithIdempotencyKey("/v1/script", "key-v2", scriptReq)
+	s.Equal(http.StatusUnprocessableEntity, other.Code)
+
+	s.Equal(1, s.countScriptsWithCommand(scriptReq.Command))
+}
+
+func (s *Suite) TestIdempotencyKeyReusedWithAnotherRequest() {
+	first := s.serveWithIdempotencyKey("/v1/script", "key-reused", request.CreateScript{Command: "echo first request"})
+	s.Equal(http.StatusOK, first.Code)
+
+	var created response.CreateScript
+	s.NoError(json.Unmarshal(first.Body.Bytes(), &created))
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	recorder := s.serveWithIdempotencyKey("/v1/script", "key-reused", request.CreateScript{Command: "echo second request"})
+	s.Equal(http.StatusUnprocessableEntity, recorder.Code)
+
+	var problem handlerutils.Problem
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &problem))
+
+	s.Equal(handlerutils.ProblemTypeIdempotencyKey, problem.Type)
+	s.Equal(0, s.countScriptsWithCommand("echo second request"))
+}
+
+func (s *Suite) TestFailedIdempotentRequestMayBeRetried() {
+	recorder := s.serveWithIdempotencyKey("/v2/scripts", "key-failed", request.CreateScript{Command: ""})
+	s.Equal(http.StatusBadRequest, recorder.Code)
+
+	// key of failed request is released, so fixed request is accepted with it
+	recorder = s.serveWithIdempotencyKey("/v2/scripts", "key-failed", request.CreateScript{Command: "echo fixed"})
+	s.Equal(http.StatusCreated, recorder.Code)
+	s.Empty(recorder.Header().Get("Idempotent-Replayed"))
+
+	var created response.CreateScript
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &created))
+
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestIdempotentRequestInProgress() {
+	scriptReq := request.CreateScript{Command: "echo in progress"}
+
+	body, err := json.Marshal(scriptReq)
+	s.NoError(err)
+
+	// as if the same request is being processed right now, request is identified by route and body
+	hash := sha256.Sum256(append([]byte("POST /test/api/v2/scripts\n"), body...))
+
+	claimed, err := s.idempotency.Claim(context.Background(), "key-in-progress", hex.EncodeToString(hash[:]))
+	s.NoError(err)
+	s.False(claimed.Completed())
+
+	recorder := s.serveWithIdempotencyKey("/v2/scripts", "key-in-progress", scriptReq)
+	s.Equal(http.StatusConflict, recorder.Code)
+	s.Equal(0, s.countScriptsWithCommand(scriptReq.Command))
+}
+
+// idempotencyKeyExpiresIn returns seconds left until key expires
+func (s *Suite) idempotencyKeyExpiresIn(key string) float64 {
+	var seconds float64
+
+	s.NoError(s.db.Get(&seconds, "SELECT extract(epoch FROM expires_at - now()) FROM idempotency_key WHERE key = $1", key))
+
+	return seconds
+}
+
+func (s *Suite) TestIdempotencyKeyInProgressIsLeased() {
+	claimed, err := s.idempotency.Claim(context.Background(), "key-leased", "hash")
+	s.NoError(err)
+	s.False(claimed.Completed())
+
+	// request that never completes holds the key for lease only
+	s.LessOrEqual(s.idempotencyKeyExpiresIn("key-leased"), float64(s.config.Service.IdempotencyLease))
+
+	// response is stored for the whole ttl once request completes
+	s.NoError(s.idempotency.Complete(context.Background(), *claimed, nil, http.StatusOK, map[string]string{}, "{}"))
+	s.Greater(s.idempotencyKeyExpiresIn("key-leased"), float64(s.config.Service.IdempotencyLease))
+
+	_, err = s.db.Exec("DELETE FROM idempotency_key WHERE key = $1", "key-leased")
+	s.NoError(err)
+}
+
+func (s *Suite) TestIdempotencyKeyLeaseKeptWhileRequestIsProcessed() {
+	claimed, err := s.idempotency.Claim(context.Background(), "key-kept", "hash")
+	s.NoError(err)
+
+	defer func() { _, _ = s.db.Exec("DELETE FROM idempotency_key WHERE key = $1", "key-kept") }()
+
+	stopLease := s.idempotency.KeepLease(context.Background(), *claimed)
+
+	// lease is extended every third of it, so key of slow request doesn't expire
+	time.Sleep(time.Duration(s.config.Service.IdempotencyLease) * time.Second / 2)
+
+	stopLease()
+
+	s.Greater(s.idempotencyKeyExpiresIn("key-kept"), float64(s.config.Service.IdempotencyLease)*2/3)
+}
+
+func (s *Suite) TestIdempotencyKeyWithLostLeaseIsFenced() {
+	claimed, err := s.idempotency.Claim(context.Background(), "key-lost", "hash")
+	s.NoError(err)
+
+	defer func() { _, _ = s.db.Exec("DELETE FROM idempotency_key WHERE key = $1", "key-lost") }()
+
+	// lease expires as if request took longer and key is claimed by retry of the request
+	_, err = s.db.Exec("UPDATE idempotency_key SET expires_at = now() WHERE key = $1", "key-lost")
+	s.NoError(err)
+
+	retry, err := s.idempotency.Claim(context.Background(), "key-lost", "hash")
+	s.NoError(err)
+	s.NotEqual(*claimed.LeaseToken, *retry.LeaseToken)
+
+	// request that lost the lease neither completes nor releases key of the retry
+	s.ErrorIs(s.idempotency.Complete(context.Background(), *claimed, nil, http.StatusOK, map[string]string{}, "{}"), idempotencyservice.ErrLeaseLost)
+	s.NoError(s.idempotency.Release(context.Background(), *claimed))
+
+	s.NoError(s.idempotency.Complete(context.Background(), *retry, nil, http.StatusOK, map[string]string{}, "{}"))
+}
@@ -0,0 +1,200 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/jwt"
+	"strconv"
+	"strings"
+	"time"
+
+	tokenservice "pg-start-trainee-2024/internal/service/token"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "pg-start-trainee"
+	testKeyID    = "test-key"
+)
+
+func encodeSegment(v any) string {
+	buf, _ := json.Marshal(v)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func (s *Suite) signRS256(key *rsa.PrivateKey, claims map[string]any) string {
+	signed := encodeSegment(map[string]string{"alg": jwt.AlgRS256, "kid": testKeyID, "typ": "JWT"}) + "." + encodeSegment(claims)
+
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	s.NoError(err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes JWKS file containing public part of key and returns its path
+func (s *Suite) writeJWKS(key *rsa.PrivateKey) string {
+	jwks := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"alg": jwt.AlgRS256,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+
+	buf, err := json.Marshal(jwks)
+	s.NoError(err)
+
+	path := filepath.Join(s.T().TempDir(), "jwks.json")
+	s.NoError(os.WriteFile(path, buf, 0o600))
+
+	return path
+}
+
+// newTokenService returns service verifying tokens with JWKS file containing public part of returned key
+func (s *Suite) newTokenService() (*tokenservice.Service, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.NoError(err)
+
+	return tokenservice.New(jwt.NewFileSource(s.writeJWKS(key), time.Minute, time.Hour, s.logger), jwt.VerifyOptions{
+		Issuers:  []string{testIssuer},
+		Audience: testAudience,
+	}), key
+}
+
+func testClaims(subject, scope string) map[string]any {
+	return map[string]any{
+		"sub":   subject,
+		"iss":   testIssuer,
+		"aud":   []string{testAudience},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	}
+}
+
+func (s *Suite) TestAuthenticateJWT() {
+	tokens, key := s.newTokenService()
+
+	principal, err := tokens.Authenticate(context.Background(), s.signRS256(key, testClaims("alice", "scripts:read scripts:write")))
+	s.NoError(err)
+
+	s.Equal("alice", principal.Subject)
+	s.True(principal.HasScope("scripts:write"))
+	s.Equal(testIssuer, principal.Claims["iss"])
+}
+
+func (s *Suite) TestAuthenticateInvalidJWT() {
+	tokens, key := s.newTokenService()
+
+	expired := testClaims("alice", "scripts:read")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	wrongIssuer := testClaims("alice", "scripts:read")
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	wrongAudience := testClaims("alice", "scripts:read")
+	wrongAudience["aud"] = "someone-else"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.NoError(err)
+
+	for _, token := range []string{
+		s.signRS256(key, expired),
+		s.signRS256(key, wrongIssuer),
+		s.signRS256(key, wrongAudience),
+		s.signRS256(otherKey, testClaims("alice", "scripts:read")),
+	} {
+		_, err = tokens.Authenticate(context.Background(), token)
+		s.ErrorIs(err, tokenservice.ErrInvalidToken)
+
+		recorder := s.serveV2WithAuth(s.newAPIKeyService(), tokens, "GET", "", token, nil)
+		s.Equal(http.StatusUnauthorized, recorder.Result().StatusCode)
+	}
+}
+
+func (s *Suite) TestScriptCreatedWithJWTIsOwnedBySubject() {
+	tokens, key := s.newTokenService()
+	apiKeys := s.newAPIKeyService()
+
+	owner := s.signRS256(key, testClaims("alice", "scripts:read scripts:write"))
+	other := s.signRS256(key, testClaims("bob", "scripts:read scripts:write"))
+
+	body, err := json.Marshal(request.CreateScript{Command: "sleep 10"})
+	s.NoError(err)
+
+	recorder := s.serveV2WithAuth(apiKeys, tokens, "POST", "", owner, bytes.NewBuffer(body))
+	s.Equal(http.StatusCreated, recorder.Result().StatusCode)
+
+	var resp response.CreateScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	script, err := s.repository.GetScript(context.Background(), "", resp.ID)
+	s.NoError(err)
+
+	s.NotNil(script.CreatedBy)
+	s.Equal("alice", *script.CreatedBy)
+
+	// other subject can see, but can't manage the script
+	recorder = s.serveV2WithAuth(apiKeys, tokens, "GET", "/"+strconv.Itoa(resp.ID), other, nil)
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	recorder = s.serveV2WithAuth(apiKeys, tokens, "DELETE", "/"+strconv.Itoa(resp.ID), other, nil)
+	s.Equal(http.StatusForbidden, recorder.Result().StatusCode)
+
+	recorder = s.serveV2WithAuth(apiKeys, tokens, "DELETE", "/"+strconv.Itoa(resp.ID), owner, nil)
+	s.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestStaleJWKSUsedWhileRefreshFails() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.NoError(err)
+
+	path := s.writeJWKS(key)
+
+	// jwks is refreshed on every call
+	source := jwt.NewFileSource(path, 0, time.Hour, s.logger)
+	tooStale := jwt.NewFileSource(path, 0, 0, s.logger)
+
+	for _, src := range []*jwt.Source{source, tooStale} {
+		keys, err := src.Keys(context.Background(), testKeyID, jwt.AlgRS256)
+		s.NoError(err)
+		s.Len(keys, 1)
+	}
+
+	// jwks can't be refreshed anymore
+	s.NoError(os.Remove(path))
+
+	keys, err := source.Keys(context.Background(), testKeyID, jwt.AlgRS256)
+	s.NoError(err)
+	s.Len(keys, 1)
+
+	logged := false
+
+	for _, entry := range s.logs.entries() {
+		if msg, ok := entry["msg"].(string); ok && strings.HasPrefix(msg, "error occurred refreshing jwks") {
+			logged = true
+		}
+	}
+
+	s.True(logged)
+
+	_, err = tooStale.Keys(context.Background(), testKeyID, jwt.AlgRS256)
+	s.Error(err)
+}
@@ -0,0 +1,82 @@
+package script
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	"sync"
+	"time"
+
+	chimiddlewares "github.com/go-chi/chi/v5/middleware"
+)
+
+// syncBuffer is buffer logs are written to concurrently with reading them in tests
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// entries returns json log entries written so far
+func (b *syncBuffer) entries() []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]map[string]any, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for scanner.Scan() {
+		var entry map[string]any
+
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func (s *Suite) findScriptLogEntry(msg string, id int) map[string]any {
+	for _, entry := range s.logs.entries() {
+		if entry["msg"] == msg && entry[logging.FieldScriptID] == float64(id) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func (s *Suite) TestScriptLogsHaveRequestAndScriptFields() {
+	const requestID = "test-host/logging-000001"
+
+	ctx := context.WithValue(context.Background(), chimiddlewares.RequestIDKey, requestID)
+	ctx = auth.WithPrincipal(ctx, &entity.Principal{Subject: "logging-tester", Scopes: []entity.Scope{entity.ScopeAdmin}})
+
+	script, err := s.service.CreateScript(ctx, entity.Script{Command: "echo logging"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	var started map[string]any
+
+	s.Eventually(func() bool {
+		started = s.findScriptLogEntry("script started", script.ID)
+
+		return started != nil && s.findScriptLogEntry("script finished", script.ID) != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	s.Equal(requestID, started[logging.FieldRequestID])
+	s.Equal("logging-tester", started[logging.FieldActor])
+	s.NotZero(started[logging.FieldPID])
+	s.Equal("info", started["level"])
+}
@@ -0,0 +1,101 @@
+package script
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/internal/pkg/metrics"
+	"pg-start-trainee-2024/pkg/router"
+	"strconv"
+	"time"
+
+	scripthandler "pg-start-trainee-2024/internal/handler/script"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func (s *Suite) TestHTTPMetricsLabeledWithRoutePattern() {
+	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
+
+	handler := scripthandler.New(s.service, s.idempotency, logrus.New(), valid, s.config.DefaultOffset, s.config.DefaultLimit)
+
+	routers := map[string]chi.Router{"/v2/scripts": handler.RoutesV2()}
+	r := router.MakeRoutes("/test/api", routers, middleware.Metrics)
+
+	requests := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/test/api/v2/scripts/{id}", "404")
+	before := testutil.ToFloat64(requests)
+
+	for _, id := range []int{-1, -2} {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/test/api/v2/scripts/%v", id), nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	s.Equal(before+2, testutil.ToFloat64(requests))
+}
+
+func (s *Suite) TestScriptMetrics() {
+	created := metrics.ScriptsCreated.WithLabelValues(string(entity.ScriptStatusRunning))
+	finished := metrics.ScriptsFinished.WithLabelValues(string(entity.ScriptStatusFinished))
+
+	createdBefore, finishedBefore := testutil.ToFloat64(created), testutil.ToFloat64(finished)
+
+	script, err := s.service.CreateScript(context.Background(), entity.Script{Command: "echo metrics"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.GreaterOrEqual(testutil.ToFloat64(created), createdBefore+1)
+
+	// scripts of other tests may finish meanwhile too
+	s.Eventually(func() bool {
+		return testutil.ToFloat64(finished) >= finishedBefore+1
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func (s *Suite) TestFinishedScriptsNotCachedAsRunning() {
+	// scripts finishing right away may finish before run returns, they must not stay cached anyway
+	ids := make([]int, 0, 20)
+
+	for i := 0; i < 20; i++ {
+		created, err := s.service.CreateScript(context.Background(), entity.Script{Command: "true"})
+		s.NoError(err)
+
+		ids = append(ids, created.ID)
+	}
+
+	for _, id := range ids {
+		s.Eventually(func() bool {
+			script, err := getScriptFromDB(s.db, id)
+			if err != nil || script.FinishedAt == nil {
+				return false
+			}
+
+			_, cached := s.cache.Get(strconv.Itoa(id))
+
+			return !cached
+		}, 5*time.Second, 50*time.Millisecond)
+
+		_ = deleteScriptFromDB(s.db, id)
+	}
+}
+
+func (s *Suite) TestRunningScriptsGaugeRegisteredWithRegisterer() {
+	registry := prometheus.NewRegistry()
+
+	s.NoError(metrics.RegisterRunningScripts(registry, func() int { return 3 }))
+
+	// registering gauge again is an error, not a panic
+	s.Error(metrics.RegisterRunningScripts(registry, func() int { return 3 }))
+
+	count, err := testutil.GatherAndCount(registry, "pg_start_scripts_running")
+	s.NoError(err)
+	s.Equal(1, count)
+}
@@ -0,0 +1,310 @@
+package script
+
+import (
+	"context"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	apikeyservice "pg-start-trainee-2024/internal/service/apikey"
+	auditservice "pg-start-trainee-2024/internal/service/audit"
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
+)
+
+const quotaTestNamespace = "quota-test"
+
+func adminOfNamespace(subject, namespace string) *entity.Principal {
+	return &entity.Principal{
+		Subject:   subject,
+		Namespace: namespace,
+		Roles:     []entity.Role{entity.RoleAdmin},
+	}
+}
+
+// createScriptInNamespace creates not running script directly in db
+func (s *Suite) createScriptInNamespace(namespace string) *entity.Script {
+	script, err := s.repository.CreateScript(context.Background(), entity.Script{
+		Namespace: namespace,
+		Command:   "echo test",
+		Status:    entity.ScriptStatusFinished,
+	})
+	s.NoError(err)
+
+	return script
+}
+
+func (s *Suite) TestNamespaceIsolation() {
+	foreign := s.createScriptInNamespace("team-b")
+	defer func() { _ = deleteScriptFromDB(s.db, foreign.ID) }()
+
+	own := s.createScriptInNamespace("team-a")
+	defer func() { _ = deleteScriptFromDB(s.db, own.ID) }()
+
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	_, err := s.service.GetScript(ctx, foreign.ID)
+	s.ErrorIs(err, scriptservice.ErrNoSuchScript)
+
+	s.ErrorIs(s.service.StopScript(ctx, foreign.ID), scriptservice.ErrNoSuchScript)
+	s.ErrorIs(s.service.DeleteScript(ctx, foreign.ID), scriptservice.ErrNoSuchScript)
+
+	_, err = s.repository.GetScript(context.Background(), "", foreign.ID)
+	s.NoError(err, "script of another namespace must not be deleted")
+
+	scripts, err := s.service.GetAllScripts(ctx, entity.ScriptFilter{}, 0, 1000)
+	s.NoError(err)
+
+	s.NotEmpty(scripts)
+
+	for _, script := range scripts {
+		s.Equal("team-a", script.Namespace)
+	}
+
+	script, err := s.service.GetScript(ctx, own.ID)
+	s.NoError(err)
+	s.Equal(own.ID, script.ID)
+}
+
+func (s *Suite) TestCreateScriptInCallerNamespace() {
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	script, err := s.service.CreateScript(ctx, entity.Script{Command: "true"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.Equal("team-a", script.Namespace)
+
+	script, err = s.service.CreateScript(context.Background(), entity.Script{Command: "true"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.Equal(entity.DefaultNamespace, script.Namespace)
+}
+
+func (s *Suite) TestNamespaceQuota() {
+	defer func() { _, _ = s.db.Exec("DELETE FROM script WHERE namespace = $1", quotaTestNamespace) }()
+
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, quotaTestNamespace))
+
+	// the first script is still running when the second one is created
+	_, err := s.service.CreateScript(ctx, entity.Script{Command: "sleep 5"})
+	s.NoError(err)
+
+	_, err = s.service.CreateScript(ctx, entity.Script{Command: "true"})
+	s.ErrorIs(err, scriptservice.ErrQuotaExceeded)
+
+	_, err = s.db.Exec("UPDATE script SET is_running = false WHERE namespace = $1", quotaTestNamespace)
+	s.NoError(err)
+
+	// rejected creation is not counted as run, so one more script still fits daily quota
+	_, err = s.service.CreateScript(ctx, entity.Script{Command: "true"})
+	s.NoError(err)
+
+	_, err = s.db.Exec("UPDATE script SET is_running = false WHERE namespace = $1", quotaTestNamespace)
+	s.NoError(err)
+
+	_, err = s.service.CreateScript(ctx, entity.Script{Command: "true"})
+	s.ErrorIs(err, scriptservice.ErrQuotaExceeded)
+
+	usage, err := s.repository.GetNamespaceUsage(context.Background(), quotaTestNamespace, time.Now().UTC().Add(-time.Hour))
+	s.NoError(err)
+	s.Equal(2, usage.RunsPerDay)
+}
+
+func (s *Suite) TestNamespaceQuotaAcrossReplicas() {
+	defer func() { _, _ = s.db.Exec("DELETE FROM script WHERE namespace = $1", quotaTestNamespace) }()
+
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, quotaTestNamespace))
+
+	// every service has own cache as separate replica has, quota is checked under lock of database
+	replicas := []*scriptservice.Service{
+		s.newServiceWithOutputBuffer(s.config.Service.OutputBufferLength),
+		s.newServiceWithOutputBuffer(s.config.Service.OutputBufferLength),
+	}
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func(service *scriptservice.Service) {
+			defer wg.Done()
+
+			if _, err := service.CreateScript(ctx, entity.Script{Command: "sleep 5"}); err == nil {
+				created.Add(1)
+			} else {
+				s.ErrorIs(err, scriptservice.ErrQuotaExceeded)
+			}
+		}(replicas[i%len(replicas)])
+	}
+
+	wg.Wait()
+
+	s.Equal(int32(1), created.Load())
+}
+
+func (s *Suite) TestAdminAPIKeysConfinedToNamespace() {
+	service := s.newAPIKeyService()
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	_, _, err := service.CreateAPIKey(ctx, entity.APIKey{Name: "foreign", Namespace: "team-b", Scopes: []entity.Scope{entity.ScopeAdmin}})
+	s.ErrorIs(err, auth.ErrOtherNamespace)
+
+	own, _, err := service.CreateAPIKey(ctx, entity.APIKey{Name: "own", Scopes: []entity.Scope{entity.ScopeScriptsRead}})
+	s.NoError(err)
+
+	defer func() { _ = deleteAPIKeyFromDB(s.db, own.ID) }()
+
+	s.Equal("team-a", own.Namespace)
+
+	// bootstrap key is not bound to namespace
+	foreign, _, err := service.CreateAPIKey(context.Background(), entity.APIKey{Name: "foreign", Namespace: "team-b", Scopes: []entity.Scope{entity.ScopeAdmin}})
+	s.NoError(err)
+
+	defer func() { _ = deleteAPIKeyFromDB(s.db, foreign.ID) }()
+
+	keys, err := service.GetAllAPIKeys(ctx)
+	s.NoError(err)
+
+	for _, key := range keys {
+		s.Equal("team-a", key.Namespace)
+	}
+
+	s.ErrorIs(service.RevokeAPIKey(ctx, foreign.ID), apikeyservice.ErrNoSuchAPIKey)
+
+	keys, err = service.GetAllAPIKeys(context.Background())
+	s.NoError(err)
+
+	for _, key := range keys {
+		if key.ID == foreign.ID {
+			s.Nil(key.RevokedAt, "key of another namespace must not be revoked")
+		}
+	}
+}
+
+func (s *Suite) TestGlobalNamespaceIsExplicit() {
+	service := s.newAPIKeyService()
+
+	// caller which may access every namespace must name namespace of key
+	_, _, err := service.CreateAPIKey(context.Background(), entity.APIKey{Name: "unnamed", Scopes: []entity.Scope{entity.ScopeAdmin}})
+	s.ErrorIs(err, apikeyservice.ErrNamespaceRequired)
+
+	_, _, err = service.CreateAPIKey(context.Background(), entity.APIKey{
+		Name:      "global reader",
+		Namespace: entity.GlobalNamespace,
+		Scopes:    []entity.Scope{entity.ScopeScriptsRead},
+	})
+	s.ErrorIs(err, apikeyservice.ErrGlobalKeyNotAdmin)
+
+	// admin bound to namespace can't grant every namespace
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	_, _, err = service.CreateAPIKey(ctx, entity.APIKey{Name: "global", Namespace: entity.GlobalNamespace, Scopes: []entity.Scope{entity.ScopeAdmin}})
+	s.ErrorIs(err, auth.ErrOtherNamespace)
+
+	global, plainKey, err := service.CreateAPIKey(context.Background(), entity.APIKey{
+		Name:      "global",
+		Namespace: entity.GlobalNamespace,
+		Scopes:    []entity.Scope{entity.ScopeAdmin},
+	})
+	s.NoError(err)
+
+	defer func() { _ = deleteAPIKeyFromDB(s.db, global.ID) }()
+
+	principal, err := service.Authenticate(context.Background(), plainKey)
+	s.NoError(err)
+	s.True(principal.IsGlobal())
+
+	foreign := s.createScriptInNamespace("team-b")
+	defer func() { _ = deleteScriptFromDB(s.db, foreign.ID) }()
+
+	_, err = s.service.GetScript(auth.WithPrincipal(context.Background(), principal), foreign.ID)
+	s.NoError(err)
+
+	// principal without namespace is bound to default namespace, not to every one
+	unbound := auth.WithPrincipal(context.Background(), &entity.Principal{Subject: ownerSubject, Roles: []entity.Role{entity.RoleAdmin}})
+
+	_, err = s.service.GetScript(unbound, foreign.ID)
+	s.ErrorIs(err, scriptservice.ErrNoSuchScript)
+}
+
+func (s *Suite) TestAdminAuditEventsConfinedToNamespace() {
+	own := s.createScriptInNamespace("team-a")
+	defer func() { _ = deleteScriptFromDB(s.db, own.ID) }()
+
+	foreign := s.createScriptInNamespace("team-b")
+	defer func() { _ = deleteScriptFromDB(s.db, foreign.ID) }()
+
+	for _, script := range []*entity.Script{own, foreign} {
+		ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, script.Namespace))
+		s.NoError(s.service.DeleteScript(ctx, script.ID))
+	}
+
+	service := auditservice.New(auditrepo.New(s.db))
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	_, err := service.GetAuditEvents(ctx, entity.AuditFilter{Namespace: "team-b"}, 0, 100)
+	s.ErrorIs(err, auth.ErrOtherNamespace)
+
+	s.ErrorIs(service.ExportAuditEvents(ctx, entity.AuditFilter{Namespace: "team-b"}, func(*entity.AuditEvent) error { return nil }), auth.ErrOtherNamespace)
+
+	events, err := service.GetAuditEvents(ctx, entity.AuditFilter{TargetType: entity.AuditTargetScript}, 0, 1000)
+	s.NoError(err)
+	s.NotEmpty(events)
+
+	exported := make([]*entity.AuditEvent, 0)
+	s.NoError(service.ExportAuditEvents(ctx, entity.AuditFilter{}, func(event *entity.AuditEvent) error {
+		exported = append(exported, event)
+
+		return nil
+	}))
+
+	for _, event := range append(events, exported...) {
+		s.Equal("team-a", event.Namespace)
+		s.NotEqual(strconv.Itoa(foreign.ID), event.TargetID)
+	}
+}
+
+func (s *Suite) TestAdminWebhooksConfinedToNamespace() {
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, "team-a"))
+
+	hook := entity.Webhook{Name: "test", URL: "https://example.com/hook", Events: []string{string(entity.ScriptEventFinished)}}
+
+	// webhook subscribed to scripts of all namespaces
+	_, err := s.webhooks.CreateWebhook(ctx, hook)
+	s.NoError(err)
+
+	foreignHook := hook
+	foreignHook.Namespace = "team-b"
+
+	_, err = s.webhooks.CreateWebhook(ctx, foreignHook)
+	s.ErrorIs(err, auth.ErrOtherNamespace)
+
+	foreign, err := s.webhooks.CreateWebhook(context.Background(), foreignHook)
+	s.NoError(err)
+
+	defer func() { _, _ = s.db.Exec("DELETE FROM webhook WHERE namespace IN ('team-a', 'team-b')") }()
+
+	webhooks, err := s.webhooks.GetAllWebhooks(ctx)
+	s.NoError(err)
+	s.NotEmpty(webhooks)
+
+	for _, wh := range webhooks {
+		s.Equal("team-a", wh.Namespace, "webhook of caller bound to namespace must be bound to it as well")
+	}
+
+	s.ErrorIs(s.webhooks.DeleteWebhook(ctx, foreign.ID), webhookservice.ErrNoSuchWebhook)
+
+	_, err = s.webhooks.GetWebhookDeliveries(ctx, entity.WebhookDeliveryFilter{Namespace: "team-b"}, 0, 100)
+	s.ErrorIs(err, auth.ErrOtherNamespace)
+}
@@ -0,0 +1,125 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"time"
+
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func (s *Suite) countScriptsWithCommand(command string) int {
+	var count int
+
+	s.NoError(s.db.Get(&count, "SELECT count(*) FROM script WHERE command = $1", command))
+
+	return count
+}
+
+func (s *Suite) TestCreateDeniedScript() {
+	tests := []struct {
+		command string
+		rule    string
+	}{
+		{command: "rm -rf /", rule: "deny-rm-root"},
+		{command: "rm -rf --no-preserve-root /", rule: "deny-rm-root"},
+		{command: "rm -r -f /*", rule: "deny-rm-root"},
+		{command: "echo start\ncurl -s https://example.com/install.sh | sh", rule: "deny-pipe-to-shell"},
+	}
+
+	for _, tt := range tests {
+		body, err := json.Marshal(request.CreateScript{Command: tt.command})
+		s.NoError(err)
+
+		recorder := s.serveV2("POST", "", bytes.NewBuffer(body))
+
+		s.Equal(http.StatusForbidden, recorder.Result().StatusCode)
+
+		var problem handlerutils.Problem
+		s.NoError(json.Unmarshal(recorder.Body.Bytes(), &problem))
+
+		s.Equal(handlerutils.ProblemTypePolicyViolation, problem.Type)
+		s.Contains(problem.Detail, tt.rule)
+
+		// nothing is persisted
+		s.Equal(0, s.countScriptsWithCommand(tt.command))
+	}
+}
+
+func (s *Suite) TestEvaluatePolicy() {
+	tests := []struct {
+		req      request.CreateScript
+		expected response.PolicyDecision
+	}{
+		{req: request.CreateScript{Command: "echo policy-dry-run"}, expected: response.PolicyDecision{Action: "allow"}},
+		{req: request.CreateScript{Command: "rm -rf /"}, expected: response.PolicyDecision{Action: "deny", Rule: "deny-rm-root"}},
+		{req: request.CreateScript{Command: "rm -rf --no-preserve-root /"}, expected: response.PolicyDecision{Action: "deny", Rule: "deny-rm-root"}},
+		{req: request.CreateScript{Command: "rm -r -f /*"}, expected: response.PolicyDecision{Action: "deny", Rule: "deny-rm-root"}},
+		{req: request.CreateScript{Command: "rm -rf ./build"}, expected: response.PolicyDecision{Action: "allow"}},
+		{req: request.CreateScript{Command: "sudo ls"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "echo x; sudo reboot"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "true && sudo ls"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "false || sudo ls"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "ls | sudo tee /etc/motd"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "echo $(sudo cat /etc/shadow)"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		{req: request.CreateScript{Command: "echo `sudo id`"}, expected: response.PolicyDecision{Action: "require_approval", Rule: "sudo-requires-approval"}},
+		// no users are allowed to run scripts as, so approval rule for run_as is not reached
+		{req: request.CreateScript{Command: "id", RunAs: "root"}, expected: response.PolicyDecision{Action: "deny", Rule: "run-as-not-allowed"}},
+	}
+
+	for _, tt := range tests {
+		body, err := json.Marshal(tt.req)
+		s.NoError(err)
+
+		recorder := s.serveV2("POST", "/policy/evaluate", bytes.NewBuffer(body))
+
+		s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+		var resp response.PolicyDecision
+		s.NoError(json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+		s.Equal(tt.expected, resp)
+	}
+
+	// dry-run doesn't create scripts
+	s.Equal(0, s.countScriptsWithCommand("echo policy-dry-run"))
+}
+
+func (s *Suite) TestCreateScriptWithEnvAndInterpreter() {
+	created, err := s.service.CreateScript(context.Background(), entity.Script{
+		Command:     `echo "$GREETING from ${BASH_VERSION:+bash}"`,
+		Interpreter: "bash",
+		Env:         map[string]string{"GREETING": "hello"},
+	})
+	s.NoError(err)
+
+	s.Equal("bash", created.Interpreter)
+	s.Nil(created.PolicyRule)
+
+	time.Sleep(1 * time.Second)
+
+	script, err := s.repository.GetScript(context.Background(), "", created.ID)
+	s.NoError(err)
+
+	s.Equal("hello from bash\n", script.Output)
+
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestCreateScriptWithInvalidEnv() {
+	_, err := s.service.CreateScript(context.Background(), entity.Script{Command: "env", Env: map[string]string{"A=B": "c"}})
+	s.ErrorIs(err, scriptservice.ErrInvalidEnv)
+}
+
+func (s *Suite) TestCreateScriptWithReservedEnv() {
+	for _, key := range []string{"LD_PRELOAD", "PATH", "BASH_ENV"} {
+		_, err := s.service.CreateScript(context.Background(), entity.Script{Command: "env", Env: map[string]string{key: "/tmp/x"}})
+		s.ErrorIs(err, scriptservice.ErrInvalidEnv)
+	}
+}
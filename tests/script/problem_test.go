@@ -0,0 +1,150 @@
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/pkg/router"
+	"strconv"
+
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
+)
+
+func (s *Suite) decodeProblem(recorder *httptest.ResponseRecorder) handlerutils.Problem {
+	s.Equal(handlerutils.ProblemContentType, recorder.Header().Get("Content-Type"))
+
+	var problem handlerutils.Problem
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &problem))
+
+	s.Equal(recorder.Result().StatusCode, problem.Status)
+
+	return problem
+}
+
+func (s *Suite) TestProblemNotFound() {
+	recorder := s.serveV2("GET", "/-1", nil)
+
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+
+	problem := s.decodeProblem(recorder)
+
+	s.Equal(handlerutils.ProblemTypeNotFound, problem.Type)
+	s.Equal("/test/api/v2/scripts/-1", problem.Instance)
+
+	// internals such as sql errors are not leaked
+	s.NotContains(problem.Detail, "sql")
+}
+
+func (s *Suite) TestProblemValidationFailed() {
+	body, err := json.Marshal(request.CreateScript{Command: ""})
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "", bytes.NewBuffer(body))
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+
+	problem := s.decodeProblem(recorder)
+
+	s.Equal(handlerutils.ProblemTypeValidationFailed, problem.Type)
+
+	if s.Len(problem.Errors, 1) {
+		s.Equal("command", problem.Errors[0].Field)
+		s.Equal("required", problem.Errors[0].Rule)
+	}
+}
+
+func (s *Suite) TestProblemV1HasItsOwnStatus() {
+	req, err := http.NewRequest("GET", "/test/api/script", nil)
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("id", strconv.Itoa(-1))
+
+	routers := make(map[string]chi.Router)
+
+	routers["/script"] = s.handler.Routes()
+
+	r := router.MakeRoutes("/test/api", routers)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+
+	problem := s.decodeProblem(recorder)
+
+	s.Equal(handlerutils.ProblemTypeNotFound, problem.Type)
+}
+
+func (s *Suite) TestProblemV1OfAuthenticationKeepsStatus() {
+	req, err := http.NewRequest("GET", "/test/api/v1/script/all", nil)
+	s.NoError(err)
+
+	routers := make(map[string]chi.Router)
+
+	routers["/v1/script"] = s.handler.Routes()
+
+	r := router.MakeRoutes("/test/api", routers, middleware.Authenticate(s.newAPIKeyService(), nil, s.logger))
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	s.Equal(http.StatusUnauthorized, recorder.Result().StatusCode)
+	s.Equal(handlerutils.ProblemTypeUnauthorized, s.decodeProblem(recorder).Type)
+}
+
+func (s *Suite) TestProblemV1Forbidden() {
+	script := s.createScriptOwnedBy(otherSubject)
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	req, err := http.NewRequest("DELETE", "/test/api/script", nil)
+	s.NoError(err)
+
+	req.Header.Set("id", strconv.Itoa(script.ID))
+
+	// operators may delete only their own scripts
+	req = req.WithContext(auth.WithPrincipal(req.Context(), principalWithRole(entity.RoleOperator)))
+
+	routers := make(map[string]chi.Router)
+
+	routers["/script"] = s.handler.Routes()
+
+	recorder := httptest.NewRecorder()
+	router.MakeRoutes("/test/api", routers).ServeHTTP(recorder, req)
+
+	s.Equal(http.StatusForbidden, recorder.Result().StatusCode)
+	s.Equal(handlerutils.ProblemTypeForbidden, s.decodeProblem(recorder).Type)
+}
+
+func (s *Suite) TestProblemDetailsDoNotLeakErrors() {
+	// json decoder error refers to offsets and Go types
+	recorder := s.serveV2("POST", "", bytes.NewBufferString(`{"command": 1`))
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal("malformed JSON body", s.decodeProblem(recorder).Detail)
+
+	recorder = s.serveV2("GET", "/not-a-number", nil)
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal("script id must be an integer", s.decodeProblem(recorder).Detail)
+
+	// strconv error of v1 id header
+	req, err := http.NewRequest("GET", "/test/api/script", nil)
+	s.NoError(err)
+
+	req.Header.Set("id", "not-a-number")
+
+	routers := make(map[string]chi.Router)
+
+	routers["/script"] = s.handler.Routes()
+
+	recorder = httptest.NewRecorder()
+	router.MakeRoutes("/test/api", routers).ServeHTTP(recorder, req)
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal("id header must be an integer", s.decodeProblem(recorder).Detail)
+}
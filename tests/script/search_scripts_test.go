@@ -0,0 +1,218 @@
+package script
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/router"
+	"time"
+)
+
+func setSearchQuery(req *http.Request, query, mode string) {
+	q := req.URL.Query()
+
+	q.Set("q", query)
+
+	if mode != "" {
+		q.Set("mode", mode)
+	}
+
+	req.URL.RawQuery = q.Encode()
+}
+
+func (s *Suite) searchScripts(query, mode string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", "/test/api/script/search", nil)
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+
+	setSearchQuery(req, query, mode)
+
+	routers := make(map[string]chi.Router)
+
+	routers["/script"] = s.handler.Routes()
+
+	r := router.MakeRoutes("/test/api", routers)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func findSearchResult(resp []response.SearchScript, id int) *response.SearchScript {
+	for i := range resp {
+		if resp[i].ID == id {
+			return &resp[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Suite) TestSearchScriptsFullText() {
+	// create script for testing
+	created := s.createScript("echo 'first line'; echo 'dial tcp: connection refused'")
+
+	// wait some time for process to exit
+	time.Sleep(1 * time.Second)
+
+	// test
+	recorder := s.searchScripts("connection refused", "")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	found := findSearchResult(resp, created.ID)
+	if s.NotNil(found) && s.Len(found.Matches, 1) {
+		s.Equal(2, found.Matches[0].Line)
+		s.Equal("dial tcp: <b>connection</b> <b>refused</b>", found.Matches[0].Snippet)
+	}
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestSearchScriptsRegex() {
+	// create script for testing
+	created := s.createScript("echo 'exit status 17'; echo 'exit status 4'")
+
+	// wait some time for process to exit
+	time.Sleep(1 * time.Second)
+
+	// test
+	recorder := s.searchScripts(`^exit status [0-9]{2}$`, "regex")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	found := findSearchResult(resp, created.ID)
+	if s.NotNil(found) && s.Len(found.Matches, 1) {
+		s.Equal(1, found.Matches[0].Line)
+		s.Equal("<b>exit status 17</b>", found.Matches[0].Snippet)
+	}
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestSearchScriptsRegexNonCapturingGroup() {
+	// create script for testing
+	created := s.createScript("echo 'exit status 17'; echo 'exit status 4'")
+
+	// wait some time for process to exit
+	time.Sleep(1 * time.Second)
+
+	// test, '$' still anchors lines when pattern starts with a group
+	recorder := s.searchScripts(`(?:exit|quit) status [0-9]{2}$`, "regex")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	found := findSearchResult(resp, created.ID)
+	if s.NotNil(found) && s.Len(found.Matches, 1) {
+		s.Equal("<b>exit status 17</b>", found.Matches[0].Snippet)
+	}
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestSearchScriptsRegexMultibyteOutput() {
+	// create script for testing
+	created := s.createScript("echo 'ошибка: код 17, код 4'")
+
+	// wait some time for process to exit
+	time.Sleep(1 * time.Second)
+
+	// test, postgres reports matches in characters, so they must be highlighted at right bytes
+	recorder := s.searchScripts(`код [0-9]+`, "regex")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	found := findSearchResult(resp, created.ID)
+	if s.NotNil(found) && s.Len(found.Matches, 1) {
+		s.Equal(1, found.Matches[0].Line)
+		s.Equal("ошибка: <b>код 17</b>, <b>код 4</b>", found.Matches[0].Snippet)
+	}
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestSearchScriptsEscapesSnippet() {
+	// create script for testing
+	created := s.createScript(`echo '<img src=x onerror=alert(1)> & escaped-marker'`)
+
+	// wait some time for process to exit
+	time.Sleep(1 * time.Second)
+
+	// test
+	recorder := s.searchScripts("escaped-marker", "regex")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	found := findSearchResult(resp, created.ID)
+	if s.NotNil(found) && s.Len(found.Matches, 1) {
+		s.Equal("&lt;img src=x onerror=alert(1)&gt; &amp; <b>escaped-marker</b>", found.Matches[0].Snippet)
+	}
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestSearchScriptsNoMatches() {
+	recorder := s.searchScripts("zzzqqqxxx", "")
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp []response.SearchScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	s.Empty(resp)
+}
+
+func (s *Suite) TestSearchScriptsEmptyQuery() {
+	recorder := s.searchScripts("", "")
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestSearchScriptsInvalidRegex() {
+	recorder := s.searchScripts("(unclosed", "regex")
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestSearchScriptsRegexRejectedByDatabase() {
+	// postgres doesn't support named groups
+	recorder := s.searchScripts(`(?P<code>status) [0-9]+`, "regex")
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestSearchScriptsRegexUnsupportedOptions() {
+	recorder := s.searchScripts(`(?s)exit.status`, "regex")
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestSearchScriptsInvalidMode() {
+	recorder := s.searchScripts("output", "fuzzy")
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
@@ -27,7 +27,7 @@ func (s *Suite) TestStopNotExistingScript() {
 	recorder := httptest.NewRecorder()
 	r.ServeHTTP(recorder, req)
 
-	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
 }
 
 func (s *Suite) TestStopExistingNotRunningScript() {
@@ -55,7 +55,7 @@ func (s *Suite) TestStopExistingNotRunningScript() {
 	recorder := httptest.NewRecorder()
 	r.ServeHTTP(recorder, req)
 
-	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+	s.Equal(http.StatusConflict, recorder.Result().StatusCode)
 }
 
 func (s *Suite) TestStopExistingRunningScript() {
@@ -10,44 +10,83 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
+	"io"
+	"os"
 	"pg-start-trainee-2024/domain/entity"
 	"pg-start-trainee-2024/internal/config"
 	scripthandler "pg-start-trainee-2024/internal/handler/script"
+	"pg-start-trainee-2024/internal/pkg/logging"
+	idempotencyservice "pg-start-trainee-2024/internal/service/idempotency"
+	"pg-start-trainee-2024/internal/service/policy"
 	scriptservice "pg-start-trainee-2024/internal/service/script"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
 	dbutils "pg-start-trainee-2024/pkg/utils/db"
+	handlerutils "pg-start-trainee-2024/pkg/utils/handler"
 	"testing"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	idempotencyrepo "pg-start-trainee-2024/internal/repository/postgres/idempotency"
 	scriptrepo "pg-start-trainee-2024/internal/repository/postgres/script"
+	webhookrepo "pg-start-trainee-2024/internal/repository/postgres/webhook"
 )
 
 type Repo interface {
 	CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error)
 	UpdateScriptOutput(ctx context.Context, id int, output string) (*entity.Script, error)
-	DeleteScript(ctx context.Context, id int) (*entity.Script, error)
+	DeleteScript(ctx context.Context, namespace string, id int) (*entity.Script, error)
 	UpdateScriptPIDAndRunningState(ctx context.Context, id, pid int, isRunning bool) (*entity.Script, error)
 	UpdateScriptRunningState(ctx context.Context, id int, isRunning bool) (*entity.Script, error)
-	GetScript(ctx context.Context, id int) (*entity.Script, error)
-	GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error)
+	FinishScript(ctx context.Context, id int, status entity.ScriptStatus, exitCode *int) (*entity.Script, error)
+	GetScript(ctx context.Context, namespace string, id int) (*entity.Script, error)
+	GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error)
+	GetScriptsByCursor(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) ([]*entity.Script, error)
+	SearchScriptsOutput(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchHit, error)
+	ReviewScript(ctx context.Context, id int, status entity.ScriptStatus, reviewedBy, rejectReason *string) (*entity.Script, error)
+	ExpirePendingScripts(ctx context.Context) (int64, error)
+	GetNamespaceUsage(ctx context.Context, namespace string, since time.Time) (*entity.NamespaceUsage, error)
+	LockNamespaceQuota(ctx context.Context, namespace string) error
+	RequestScriptStop(ctx context.Context, id int) (*entity.Script, error)
+	RegisterWorker(ctx context.Context, worker entity.Worker) (*entity.Worker, error)
+	HeartbeatWorker(ctx context.Context, id string) error
+	DeregisterWorker(ctx context.Context, id string) error
+	ClaimQueuedScripts(ctx context.Context, workerID string, limit int) ([]*entity.Script, error)
+	GetStopRequestedScripts(ctx context.Context, workerID string) ([]*entity.Script, error)
+	MarkLostScripts(ctx context.Context, lostAfter time.Duration) ([]*entity.Script, error)
+	CountMatchingWorkers(ctx context.Context, selector dbutils.StringMap, aliveWithin time.Duration) (int, error)
 }
 
 type Cache interface {
 	Set(key string, value any, duration time.Duration)
 	Get(key string) (any, bool)
 	Delete(key string)
+	ItemCount() int
+	Items() map[string]gocache.Item
 }
 
 type Service interface {
 	CreateScript(ctx context.Context, script entity.Script) (*entity.Script, error)
 	StopScript(ctx context.Context, id int) error
 	GetScript(ctx context.Context, id int) (*entity.Script, error)
-	GetAllScripts(ctx context.Context, offset, limit int) ([]*entity.Script, error)
+	GetAllScripts(ctx context.Context, filter entity.ScriptFilter, offset, limit int) ([]*entity.Script, error)
+	GetScriptsPage(ctx context.Context, filter entity.ScriptFilter, cursor *entity.Cursor, limit int) (*entity.ScriptsPage, error)
 	DeleteScript(ctx context.Context, id int) error
+	SearchScripts(ctx context.Context, search entity.ScriptSearch, offset, limit int) ([]*entity.ScriptSearchResult, error)
+	EvaluatePolicy(ctx context.Context, script entity.Script) (*entity.PolicyDecision, error)
+	ApproveScript(ctx context.Context, id int) (*entity.Script, error)
+	RejectScript(ctx context.Context, id int, reason string) error
+	CreateScripts(ctx context.Context, scripts []entity.Script) []entity.BatchResult
+	StopScripts(ctx context.Context, ids []int) []entity.BatchResult
+	DeleteScripts(ctx context.Context, ids []int) ([]entity.BatchResult, error)
+	ExpirePendingScripts(ctx context.Context) error
+	WatchScriptChanges(ctx context.Context, changes <-chan entity.ScriptChange)
+	WatchOutput(ctx context.Context, id int, send func(output string) error) error
 }
 
 type Handler interface {
 	Routes() *chi.Mux
+	RoutesV2() *chi.Mux
 }
 
 var (
@@ -85,12 +124,18 @@ type Suite struct {
 
 	config *config.Config
 
+	// logger writes to stderr and logs, so tests can check what's logged
+	logger *logrus.Logger
+	logs   *syncBuffer
+
 	db    *sqlx.DB
 	cache Cache
 
-	repository Repo
-	service    Service
-	handler    Handler
+	repository  Repo
+	service     Service
+	webhooks    *webhookservice.Service
+	idempotency *idempotencyservice.Service
+	handler     Handler
 }
 
 func TestSuite(t *testing.T) {
@@ -106,6 +151,16 @@ func (s *Suite) setupConfig() {
 	s.config = conf
 }
 
+func (s *Suite) setupLogger() {
+	s.logs = &syncBuffer{}
+	s.logger = logrus.New()
+	s.logger.SetOutput(io.MultiWriter(os.Stderr, s.logs))
+
+	if err := logging.Configure(s.logger, logging.Options{Level: s.config.Logging.Level, Format: s.config.Logging.Format}); err != nil {
+		s.FailNowf(err.Error(), err.Error())
+	}
+}
+
 func (s *Suite) setupDB() {
 	db, err := dbutils.TryToConnectToDB(s.config.Postgres.ConnectionURL(), "postgres", 5, 5, logrus.New())
 	if err != nil {
@@ -124,14 +179,53 @@ func (s *Suite) setupRepo() {
 }
 
 func (s *Suite) setupService() {
-	s.service = scriptservice.New(s.repository, s.cache, s.config.Service.OutputBufferLength)
+	policyEngine, err := policy.New(entity.PolicyAction(s.config.Policy.DefaultAction), s.config.Policy.PolicyRules(), s.config.Policy.RunAsUsers)
+	if err != nil {
+		s.FailNowf(err.Error(), err.Error())
+	}
+
+	allowedNetworks, err := s.config.Webhooks.AllowedPrefixes()
+	if err != nil {
+		s.FailNowf(err.Error(), err.Error())
+	}
+
+	s.webhooks = webhookservice.New(webhookrepo.New(s.db), auditrepo.New(s.db), dbutils.NewTransactor(s.db), s.logger, webhookservice.Options{
+		CallbackSecret:  s.config.Webhooks.CallbackSecret,
+		Timeout:         time.Duration(s.config.Webhooks.Timeout) * time.Second,
+		MaxAttempts:     s.config.Webhooks.MaxAttempts,
+		BaseBackoff:     time.Duration(s.config.Webhooks.BaseBackoff) * time.Second,
+		MaxBackoff:      time.Duration(s.config.Webhooks.MaxBackoff) * time.Second,
+		BatchSize:       s.config.Webhooks.BatchSize,
+		AllowedNetworks: allowedNetworks,
+	})
+
+	s.service = scriptservice.New(
+		s.repository,
+		auditrepo.New(s.db),
+		dbutils.NewTransactor(s.db),
+		s.cache,
+		policyEngine,
+		s.webhooks,
+		s.config.Quotas.NamespaceQuotas(),
+		s.logger,
+		scriptservice.ExecutionOptions{Mode: scriptservice.ExecuteLocally},
+		s.config.Service.OutputBufferLength,
+		time.Duration(s.config.Service.ApprovalTTL)*time.Second,
+	)
 }
 
 func (s *Suite) setupHandler() {
-	logger := logrus.New()
+	s.idempotency = idempotencyservice.New(
+		idempotencyrepo.New(s.db),
+		s.logger,
+		time.Duration(s.config.Service.IdempotencyTTL)*time.Second,
+		time.Duration(s.config.Service.IdempotencyLease)*time.Second,
+	)
+
 	valid := validator.New(validator.WithRequiredStructEnabled())
+	valid.RegisterTagNameFunc(handlerutils.JSONTagName)
 
-	s.handler = scripthandler.New(s.service, logger, valid, s.config.DefaultOffset, s.config.DefaultLimit)
+	s.handler = scripthandler.New(s.service, s.idempotency, s.logger, valid, s.config.DefaultOffset, s.config.DefaultLimit)
 }
 
 func (s *Suite) loadFixturesIntoDB() {
@@ -145,6 +239,7 @@ func (s *Suite) loadFixturesIntoDB() {
 
 func (s *Suite) SetupSuite() {
 	s.setupConfig()
+	s.setupLogger()
 	s.setupDB()
 	s.setupCache()
 	s.setupRepo()
@@ -156,6 +251,7 @@ func (s *Suite) SetupSuite() {
 func (s *Suite) TearDownSuite() {
 	// delete all data from db
 	_, _ = s.db.Exec("DELETE FROM script WHERE true")
+	_, _ = s.db.Exec("DELETE FROM idempotency_key WHERE true")
 
 	// close db connection
 	_ = s.db.Close()
@@ -0,0 +1,95 @@
+package script
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/handler/middleware"
+	"pg-start-trainee-2024/pkg/router"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupSpanRecorder registers provider recording all spans globally until returned function is called
+func setupSpanRecorder() (*tracetest.SpanRecorder, func()) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return recorder, func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan, traceID string) []string {
+	names := make([]string, 0, len(spans))
+
+	for _, span := range spans {
+		if span.SpanContext().TraceID().String() == traceID {
+			names = append(names, span.Name())
+		}
+	}
+
+	return names
+}
+
+func (s *Suite) TestTraceContextPassedToScript() {
+	recorder, restore := setupSpanRecorder()
+	defer restore()
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "test")
+	traceID := root.SpanContext().TraceID().String()
+
+	script, err := s.service.CreateScript(ctx, entity.Script{Command: "echo $TRACEPARENT"})
+	root.End()
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	s.Eventually(func() bool {
+		scpt, err := getScriptFromDB(s.db, script.ID)
+
+		return err == nil && !scpt.IsRunning && strings.Contains(scpt.Output, traceID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	s.Eventually(func() bool {
+		names := spanNames(recorder.Ended(), traceID)
+
+		return len(names) > 0 &&
+			strings.Contains(strings.Join(names, ","), "script.Service.CreateScript") &&
+			strings.Contains(strings.Join(names, ","), "script.Service.run") &&
+			strings.Contains(strings.Join(names, ","), "script.Repo.CreateScript")
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func (s *Suite) TestRequestTraceContinuesIncomingTraceparent() {
+	recorder, restore := setupSpanRecorder()
+	defer restore()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	routers := map[string]chi.Router{"/v2/scripts": s.handler.RoutesV2()}
+	r := router.MakeRoutes("/test/api", routers, middleware.Tracing)
+
+	req := httptest.NewRequest(http.MethodGet, "/test/api/v2/scripts/-1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	names := spanNames(recorder.Ended(), traceID)
+
+	s.Contains(names, "GET /test/api/v2/scripts/{id}")
+	s.Contains(names, "script.Service.GetScript")
+	s.Contains(names, "script.Repo.GetScript")
+}
@@ -0,0 +1,166 @@
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/internal/handler/request"
+	"pg-start-trainee-2024/internal/handler/response"
+	"pg-start-trainee-2024/pkg/router"
+	"strconv"
+	"time"
+)
+
+func (s *Suite) serveV2(method, path string, body io.Reader) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, "/test/api/v2/scripts"+path, body)
+	s.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+
+	routers := make(map[string]chi.Router)
+
+	routers["/v2/scripts"] = s.handler.RoutesV2()
+
+	r := router.MakeRoutes("/test/api", routers)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func (s *Suite) TestV2CreateScript() {
+	body, err := json.Marshal(request.CreateScript{Command: "ls -la /"})
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "", bytes.NewBuffer(body))
+
+	s.Equal(http.StatusCreated, recorder.Result().StatusCode)
+
+	var resp response.CreateScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	s.Equal("/test/api/v2/scripts/"+strconv.Itoa(resp.ID), recorder.Header().Get("Location"))
+
+	// check that script created in db
+	s.checkThatScriptCreatedInDB(&resp)
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, resp.ID)
+}
+
+func (s *Suite) TestV2CreateEmptyScript() {
+	body, err := json.Marshal(request.CreateScript{Command: ""})
+	s.NoError(err)
+
+	recorder := s.serveV2("POST", "", bytes.NewBuffer(body))
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestV2GetScript() {
+	// create script for testing
+	created := s.createScript("ls -la /")
+
+	// test
+	recorder := s.serveV2("GET", "/"+strconv.Itoa(created.ID), nil)
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp response.GetScript
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	s.scriptAndResponseEqual(created, &resp)
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestV2GetNotExistingScript() {
+	recorder := s.serveV2("GET", "/-1", nil)
+
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestV2GetScriptInvalidID() {
+	recorder := s.serveV2("GET", "/abc", nil)
+
+	s.Equal(http.StatusBadRequest, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestV2GetScripts() {
+	recorder := s.serveV2("GET", "?limit=1", nil)
+
+	s.Equal(http.StatusOK, recorder.Result().StatusCode)
+
+	var resp response.ScriptsPage
+	s.NoError(json.Unmarshal([]byte(recorder.Body.String()), &resp))
+
+	s.Len(resp.Items, 1)
+	s.NotEmpty(resp.NextCursor)
+}
+
+func (s *Suite) TestV2StopRunningScript() {
+	// create script for testing
+	created := s.createScript("ping google.com")
+
+	// test
+	recorder := s.serveV2("POST", "/"+strconv.Itoa(created.ID)+"/stop", nil)
+
+	s.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+
+	// check that process stopped
+	s.runCheckPidExistsScript(created.PID, "")
+
+	// stopping script again is a conflict
+	recorder = s.serveV2("POST", "/"+strconv.Itoa(created.ID)+"/stop", nil)
+
+	s.Equal(http.StatusConflict, recorder.Result().StatusCode)
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestV2StopNotRunningScript() {
+	// create script for testing
+	created := s.createScript("ls -la /")
+
+	// wait some time for script to exit
+	time.Sleep(1 * time.Second)
+
+	// test
+	recorder := s.serveV2("POST", "/"+strconv.Itoa(created.ID)+"/stop", nil)
+
+	s.Equal(http.StatusConflict, recorder.Result().StatusCode)
+
+	// delete script from db
+	_ = deleteScriptFromDB(s.db, created.ID)
+}
+
+func (s *Suite) TestV2StopNotExistingScript() {
+	recorder := s.serveV2("POST", "/-1/stop", nil)
+
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
+
+func (s *Suite) TestV2DeleteScript() {
+	// create script for testing
+	created := s.createScript("ls -la /")
+
+	// test
+	recorder := s.serveV2("DELETE", "/"+strconv.Itoa(created.ID), nil)
+
+	s.Equal(http.StatusNoContent, recorder.Result().StatusCode)
+
+	// check that scrip deleted from db
+	_, err := getScriptFromDB(s.db, created.ID)
+	s.Error(err)
+
+	// deleting it again reports that script is not found
+	recorder = s.serveV2("DELETE", "/"+strconv.Itoa(created.ID), nil)
+
+	s.Equal(http.StatusNotFound, recorder.Result().StatusCode)
+}
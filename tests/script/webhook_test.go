@@ -0,0 +1,213 @@
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/pkg/auth"
+	"pg-start-trainee-2024/pkg/webhook"
+	"sync"
+	"time"
+
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	webhookrepo "pg-start-trainee-2024/internal/repository/postgres/webhook"
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	webhookservice "pg-start-trainee-2024/internal/service/webhook"
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+const webhookTestNamespace = "webhook-test"
+
+type receivedWebhook struct {
+	event     string
+	signature string
+	body      []byte
+	payload   webhook.Payload
+}
+
+// webhookReceiver records webhook requests, the first failures requests are answered with 500
+type webhookReceiver struct {
+	mu       sync.Mutex
+	failures int
+	received []receivedWebhook
+}
+
+func (wr *webhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	if wr.failures > 0 {
+		wr.failures--
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	var payload webhook.Payload
+	_ = json.Unmarshal(body, &payload)
+
+	wr.received = append(wr.received, receivedWebhook{
+		event:     req.Header.Get(webhook.HeaderEvent),
+		signature: req.Header.Get(webhook.HeaderSignature),
+		body:      body,
+		payload:   payload,
+	})
+}
+
+// events returns events received for script
+func (wr *webhookReceiver) events(scriptID int) []receivedWebhook {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	events := make([]receivedWebhook, 0)
+
+	for _, received := range wr.received {
+		if received.payload.Script.ID == scriptID {
+			events = append(events, received)
+		}
+	}
+
+	return events
+}
+
+// waitForWebhooks delivers due webhooks until receiver gets n events of script
+func (s *Suite) waitForWebhooks(receiver *webhookReceiver, scriptID, n int) []receivedWebhook {
+	s.Eventually(func() bool {
+		s.NoError(s.webhooks.DeliverDueWebhooks(context.Background()))
+
+		return len(receiver.events(scriptID)) >= n
+	}, 10*time.Second, 100*time.Millisecond)
+
+	return receiver.events(scriptID)
+}
+
+func (s *Suite) TestScriptCallbackDelivered() {
+	receiver := &webhookReceiver{}
+
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	script, err := s.service.CreateScript(context.Background(), entity.Script{Command: "echo callback", CallbackURL: &server.URL})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	received := s.waitForWebhooks(receiver, script.ID, 2)
+
+	// deliveries are sent concurrently, so events may arrive in any order
+	events := make([]string, 0, len(received))
+
+	for _, r := range received {
+		events = append(events, r.event)
+
+		s.Equal(r.event, r.payload.Event)
+		s.True(webhook.Verify(s.config.Webhooks.CallbackSecret, r.body, r.signature))
+
+		if r.event == string(entity.ScriptEventFinished) {
+			s.Equal(string(entity.ScriptStatusFinished), r.payload.Script.Status)
+		}
+	}
+
+	s.ElementsMatch([]string{string(entity.ScriptEventStarted), string(entity.ScriptEventFinished)}, events)
+}
+
+func (s *Suite) TestWebhookDeliveryRetried() {
+	receiver := &webhookReceiver{failures: 1}
+
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	wh, err := s.webhooks.CreateWebhook(context.Background(), entity.Webhook{
+		Name:      "retried",
+		URL:       server.URL,
+		Namespace: webhookTestNamespace,
+		Events:    []string{string(entity.ScriptEventFailed)},
+	})
+	s.NoError(err)
+	s.NotEmpty(wh.Secret)
+
+	defer func() { s.NoError(s.webhooks.DeleteWebhook(context.Background(), wh.ID)) }()
+
+	ctx := auth.WithPrincipal(context.Background(), adminOfNamespace(ownerSubject, webhookTestNamespace))
+
+	script, err := s.service.CreateScript(ctx, entity.Script{Command: "exit 3"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	received := s.waitForWebhooks(receiver, script.ID, 1)
+
+	s.Len(received, 1, "webhook must be delivered only on subscribed event")
+	s.Equal(string(entity.ScriptEventFailed), received[0].event)
+	s.Equal(3, *received[0].payload.Script.ExitCode)
+	s.True(webhook.Verify(wh.Secret, received[0].body, received[0].signature))
+
+	deliveries, err := s.webhooks.GetWebhookDeliveries(context.Background(), entity.WebhookDeliveryFilter{WebhookID: &wh.ID}, 0, 10)
+	s.NoError(err)
+
+	s.Len(deliveries, 1)
+	s.Equal(entity.WebhookDeliverySucceeded, deliveries[0].Status)
+	s.Equal(2, deliveries[0].Attempts)
+	s.Equal(http.StatusOK, *deliveries[0].ResponseStatus)
+	s.NotNil(deliveries[0].DeliveredAt)
+}
+
+func (s *Suite) TestCallbackURLRejectedWithoutSecret() {
+	webhooks := webhookservice.New(webhookrepo.New(s.db), auditrepo.New(s.db), dbutils.NewTransactor(s.db), s.logger, webhookservice.Options{Timeout: time.Second})
+
+	s.ErrorIs(webhooks.CheckCallbackURL(context.Background(), "https://example.com/hook"), webhookservice.ErrCallbacksDisabled)
+}
+
+func (s *Suite) TestWebhookURLMustBePublic() {
+	for _, url := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1/hook",
+		"http://[::1]:8080/hook",
+		"http://localhost.invalid/hook",
+	} {
+		callbackURL := url
+
+		_, err := s.service.CreateScript(context.Background(), entity.Script{Command: "true", CallbackURL: &callbackURL})
+		s.ErrorIs(err, scriptservice.ErrInvalidCallbackURL, url)
+
+		_, err = s.webhooks.CreateWebhook(context.Background(), entity.Webhook{
+			Name:   "internal",
+			URL:    url,
+			Events: []string{string(entity.ScriptEventFinished)},
+		})
+		s.ErrorIs(err, webhookservice.ErrURLNotAllowed, url)
+	}
+
+	// address is checked on connection as well, so host resolving to another address later is refused
+	server := httptest.NewServer(&webhookReceiver{})
+	defer server.Close()
+
+	webhooks := webhookservice.New(webhookrepo.New(s.db), auditrepo.New(s.db), dbutils.NewTransactor(s.db), s.logger, webhookservice.Options{Timeout: time.Second})
+
+	_, err := webhooks.Client.Get(server.URL)
+	s.ErrorIs(err, webhookservice.ErrURLNotAllowed)
+}
+
+func (s *Suite) TestDeliveriesKeptAfterScriptDeleted() {
+	receiver := &webhookReceiver{}
+
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	script, err := s.service.CreateScript(context.Background(), entity.Script{Command: "echo callback", CallbackURL: &server.URL})
+	s.NoError(err)
+
+	s.waitForWebhooks(receiver, script.ID, 2)
+
+	s.NoError(deleteScriptFromDB(s.db, script.ID))
+
+	deliveries, err := s.webhooks.GetWebhookDeliveries(context.Background(), entity.WebhookDeliveryFilter{ScriptID: &script.ID}, 0, 10)
+	s.NoError(err)
+
+	s.Len(deliveries, 2)
+}
@@ -0,0 +1,251 @@
+package script
+
+import (
+	"context"
+	"net/http/httptest"
+	"pg-start-trainee-2024/domain/entity"
+	"pg-start-trainee-2024/internal/service/policy"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	auditrepo "pg-start-trainee-2024/internal/repository/postgres/audit"
+	scriptservice "pg-start-trainee-2024/internal/service/script"
+	dbutils "pg-start-trainee-2024/pkg/utils/db"
+)
+
+// newWorkersService creates service queueing scripts for workers, every service has own cache as separate replica has
+func (s *Suite) newWorkersService() *scriptservice.Service {
+	policyEngine, err := policy.New(entity.PolicyAction(s.config.Policy.DefaultAction), s.config.Policy.PolicyRules(), s.config.Policy.RunAsUsers)
+	s.NoError(err)
+
+	return scriptservice.New(
+		s.repository,
+		auditrepo.New(s.db),
+		dbutils.NewTransactor(s.db),
+		gocache.New(gocache.NoExpiration, gocache.NoExpiration),
+		policyEngine,
+		s.webhooks,
+		s.config.Quotas.NamespaceQuotas(),
+		s.logger,
+		scriptservice.ExecutionOptions{
+			Mode:            scriptservice.ExecuteByWorkers,
+			WorkerLostAfter: time.Duration(s.config.Worker.LostAfter) * time.Second,
+		},
+		s.config.Service.OutputBufferLength,
+		time.Duration(s.config.Service.ApprovalTTL)*time.Second,
+	)
+}
+
+// startWorker runs worker with id and labels until returned function is called
+func (s *Suite) startWorker(id string, labels ...string) func() {
+	parsed, err := scriptservice.ParseLabels(labels)
+	s.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		_ = s.newWorkersService().Work(ctx, scriptservice.WorkerOptions{
+			ID:                id,
+			Hostname:          "localhost",
+			MaxConcurrent:     s.config.Worker.MaxConcurrent,
+			Labels:            parsed,
+			PollInterval:      time.Duration(s.config.Worker.PollInterval) * time.Second,
+			HeartbeatInterval: time.Duration(s.config.Worker.HeartbeatInterval) * time.Second,
+			LostAfter:         time.Duration(s.config.Worker.LostAfter) * time.Second,
+		})
+	}()
+
+	return cancel
+}
+
+func (s *Suite) TestQueuedScriptRunByWorker() {
+	created, err := s.newWorkersService().CreateScript(context.Background(), entity.Script{Command: "echo worker"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	s.Equal(entity.ScriptStatusQueued, created.Status)
+
+	stop := s.startWorker("worker-run")
+	defer stop()
+
+	s.Eventually(func() bool {
+		script, err := getScriptFromDB(s.db, created.ID)
+
+		return err == nil && script.Status == entity.ScriptStatusFinished
+	}, 10*time.Second, 100*time.Millisecond)
+
+	script, err := getScriptFromDB(s.db, created.ID)
+	s.NoError(err)
+
+	s.Equal("worker\n", script.Output)
+	s.Equal("worker-run", *script.WorkerID)
+}
+
+func (s *Suite) TestScriptRunByWorkerStoppedByAnotherReplica() {
+	api := s.newWorkersService()
+
+	created, err := api.CreateScript(context.Background(), entity.Script{Command: "sleep 30"})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	stop := s.startWorker("worker-stop")
+	defer stop()
+
+	s.Eventually(func() bool {
+		script, err := getScriptFromDB(s.db, created.ID)
+
+		return err == nil && script.IsRunning && script.PID != 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// stop is requested via db as script isn't run by api replica
+	s.NoError(api.StopScript(context.Background(), created.ID))
+
+	s.Eventually(func() bool {
+		script, err := getScriptFromDB(s.db, created.ID)
+
+		return err == nil && !script.IsRunning && script.Status == entity.ScriptStatusStopped
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func (s *Suite) TestQueuedScriptStoppedRightAway() {
+	receiver := &webhookReceiver{}
+
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	api := s.newWorkersService()
+
+	created, err := api.CreateScript(context.Background(), entity.Script{Command: "sleep 30", CallbackURL: &server.URL})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	s.NoError(api.StopScript(context.Background(), created.ID))
+
+	script, err := getScriptFromDB(s.db, created.ID)
+	s.NoError(err)
+
+	s.Equal(entity.ScriptStatusStopped, script.Status)
+	s.NotNil(script.FinishedAt)
+
+	// nobody runs queued script, so stop event is sent by replica stopping it
+	received := s.waitForWebhooks(receiver, created.ID, 1)
+
+	s.Len(received, 1)
+	s.Equal(string(entity.ScriptEventStopped), received[0].event)
+	s.Equal(string(entity.ScriptStatusStopped), received[0].payload.Script.Status)
+
+	// stopping script again is an error
+	s.ErrorIs(api.StopScript(context.Background(), created.ID), scriptservice.ErrNoSuchRunningScript)
+}
+
+func (s *Suite) TestScriptOfDeadWorkerMarkedLost() {
+	ctx := context.Background()
+
+	_, err := s.repository.RegisterWorker(ctx, entity.Worker{ID: "worker-dead", Hostname: "localhost", MaxConcurrent: 1})
+	s.NoError(err)
+
+	defer func() { _ = s.repository.DeregisterWorker(ctx, "worker-dead") }()
+
+	script, err := s.repository.CreateScript(ctx, entity.Script{Command: "sleep 30", IsRunning: true})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	// as if worker claimed script and died long ago
+	_, err = s.db.Exec(`UPDATE script SET worker_id = 'worker-dead' WHERE id = $1`, script.ID)
+	s.NoError(err)
+
+	_, err = s.db.Exec(`UPDATE worker SET heartbeat_at = now() - interval '1 hour' WHERE id = 'worker-dead'`)
+	s.NoError(err)
+
+	stop := s.startWorker("worker-alive")
+	defer stop()
+
+	s.Eventually(func() bool {
+		scpt, err := getScriptFromDB(s.db, script.ID)
+
+		return err == nil && !scpt.IsRunning && scpt.Status == entity.ScriptStatusLost
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func (s *Suite) TestScriptOfDeadWorkerMarkedLostByAPIReplica() {
+	ctx := context.Background()
+
+	_, err := s.repository.RegisterWorker(ctx, entity.Worker{ID: "worker-dead", Hostname: "localhost", MaxConcurrent: 1})
+	s.NoError(err)
+
+	defer func() { _ = s.repository.DeregisterWorker(ctx, "worker-dead") }()
+
+	script, err := s.repository.CreateScript(ctx, entity.Script{Command: "sleep 30", IsRunning: true})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, script.ID) }()
+
+	_, err = s.db.Exec(`UPDATE script SET worker_id = 'worker-dead' WHERE id = $1`, script.ID)
+	s.NoError(err)
+
+	_, err = s.db.Exec(`UPDATE worker SET heartbeat_at = now() - interval '1 hour' WHERE id = 'worker-dead'`)
+	s.NoError(err)
+
+	// no worker is alive, so only api replica may mark script as lost
+	sweepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go s.newWorkersService().MarkLostScriptsPeriodically(sweepCtx, 100*time.Millisecond)
+
+	s.Eventually(func() bool {
+		scpt, err := getScriptFromDB(s.db, script.ID)
+
+		return err == nil && !scpt.IsRunning && scpt.Status == entity.ScriptStatusLost
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func (s *Suite) TestScriptPlacedOnMatchingWorker() {
+	stopEU := s.startWorker("worker-eu", "region=eu", "has-docker")
+	defer stopEU()
+
+	stopUS := s.startWorker("worker-us", "region=us", "has-docker")
+	defer stopUS()
+
+	selector := dbutils.StringMap{"region": "us", "has-docker": ""}
+
+	// workers are registered asynchronously
+	s.Eventually(func() bool {
+		count, err := s.repository.CountMatchingWorkers(context.Background(), dbutils.StringMap{"has-docker": ""}, time.Minute)
+
+		return err == nil && count == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	created, err := s.newWorkersService().CreateScript(context.Background(), entity.Script{Command: "echo placed", LabelSelector: selector})
+	s.NoError(err)
+
+	defer func() { _ = deleteScriptFromDB(s.db, created.ID) }()
+
+	s.Eventually(func() bool {
+		script, err := getScriptFromDB(s.db, created.ID)
+
+		return err == nil && script.Status == entity.ScriptStatusFinished
+	}, 10*time.Second, 100*time.Millisecond)
+
+	script, err := getScriptFromDB(s.db, created.ID)
+	s.NoError(err)
+
+	s.Equal("worker-us", *script.WorkerID)
+}
+
+func (s *Suite) TestScriptWithoutMatchingWorkerIsUnschedulable() {
+	api := s.newWorkersService()
+
+	_, err := api.CreateScript(context.Background(), entity.Script{Command: "nvidia-smi", LabelSelector: dbutils.StringMap{"gpu": "true"}})
+	s.ErrorIs(err, scriptservice.ErrUnschedulable)
+
+	// scripts run locally can't be placed by labels at all
+	_, err = s.service.CreateScript(context.Background(), entity.Script{Command: "nvidia-smi", LabelSelector: dbutils.StringMap{"gpu": "true"}})
+	s.ErrorIs(err, scriptservice.ErrUnschedulable)
+
+	_, err = api.CreateScript(context.Background(), entity.Script{Command: "echo", LabelSelector: dbutils.StringMap{"gpu=": "true"}})
+	s.ErrorIs(err, scriptservice.ErrInvalidLabel)
+}